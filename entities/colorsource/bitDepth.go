@@ -0,0 +1,26 @@
+package colorsource
+
+import (
+	"image/color"
+)
+
+// SixteenBitColor widens each channel of an 8 bit color to 16 bits, so 0xff becomes 0xffff.
+func SixteenBitColor(original color.NRGBA) color.NRGBA64 {
+	return color.NRGBA64{
+		R: uint16(original.R) * 0x101,
+		G: uint16(original.G) * 0x101,
+		B: uint16(original.B) * 0x101,
+		A: uint16(original.A) * 0x101,
+	}
+}
+
+// EightBitColor keeps the top 8 bits of each channel.
+//   Opaque colors convert the same way color.NRGBAModel converts them.
+func EightBitColor(original color.NRGBA64) color.NRGBA {
+	return color.NRGBA{
+		R: uint8(original.R >> 8),
+		G: uint8(original.G >> 8),
+		B: uint8(original.B >> 8),
+		A: uint8(original.A >> 8),
+	}
+}
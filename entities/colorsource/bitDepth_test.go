@@ -0,0 +1,33 @@
+package colorsource_test
+
+import (
+	. "gopkg.in/check.v1"
+	"image/color"
+	"wallpaper/entities/colorsource"
+)
+
+type BitDepthSuite struct {
+}
+
+var _ = Suite(&BitDepthSuite{})
+
+func (suite *BitDepthSuite) TestSixteenBitColorFillsTheWholeRange(checker *C) {
+	checker.Assert(
+		colorsource.SixteenBitColor(color.NRGBA{R: 0xff, G: 0x80, B: 0x01, A: 0}),
+		Equals,
+		color.NRGBA64{R: 0xffff, G: 0x8080, B: 0x0101, A: 0},
+	)
+}
+
+func (suite *BitDepthSuite) TestEightBitColorKeepsTheTopBits(checker *C) {
+	checker.Assert(
+		colorsource.EightBitColor(color.NRGBA64{R: 0xffff, G: 0x80ff, B: 0x0100, A: 0x7f00}),
+		Equals,
+		color.NRGBA{R: 0xff, G: 0x80, B: 0x01, A: 0x7f},
+	)
+}
+
+func (suite *BitDepthSuite) TestEightBitColorMatchesTheStandardLibraryForOpaqueColors(checker *C) {
+	sixteenBitColor := color.NRGBA64{R: 0x1234, G: 0xabcd, B: 0x00ff, A: 0xffff}
+	checker.Assert(colorsource.EightBitColor(sixteenBitColor), Equals, color.NRGBAModel.Convert(sixteenBitColor))
+}
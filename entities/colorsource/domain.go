@@ -0,0 +1,71 @@
+package colorsource
+
+import (
+	"errors"
+	"image/color"
+	"math"
+	"math/cmplx"
+)
+
+// DomainColoring colors complex numbers directly, without a source image.
+//   The hue comes from the number's argument: positive real numbers are red, then yellow, green, cyan, blue and magenta
+//   as the argument turns counterclockwise.
+//   The lightness comes from the modulus: 0 is black, 1 is fully saturated and very large numbers fade to white.
+type DomainColoring struct {
+	// ModulusContours darkens the colors in steps, restarting each time the modulus doubles.
+	ModulusContours bool `json:"modulus_contours" yaml:"modulus_contours"`
+	// PhaseContours darkens the colors in steps, restarting this many times around the circle. 0 turns them off.
+	PhaseContours int `json:"phase_contours" yaml:"phase_contours"`
+}
+
+// contourShadeRange is how much contours darken a color, from the start of a step to its end.
+const contourShadeRange = 0.3
+
+// Validate returns an error if the options cannot be used.
+func (domainColoring *DomainColoring) Validate() error {
+	if domainColoring.PhaseContours < 0 {
+		return errors.New(`phase_contours cannot be negative`)
+	}
+	return nil
+}
+
+// ColorFor returns the color of the complex number.
+//   Infinite and undefined numbers are transparent.
+func (domainColoring *DomainColoring) ColorFor(number complex128) color.NRGBA64 {
+	if cmplx.IsNaN(number) || cmplx.IsInf(number) {
+		return color.NRGBA64{R: 0, G: 0, B: 0, A: 0}
+	}
+
+	modulus := cmplx.Abs(number)
+	argumentAsFraction := cmplx.Phase(number) / (2 * math.Pi)
+	hue := argumentAsFraction - math.Floor(argumentAsFraction)
+	lightness := math.Atan(modulus) * 2 / math.Pi
+
+	shade := 1.0
+	if domainColoring.ModulusContours && modulus > 0 {
+		logModulus := math.Log2(modulus)
+		shade *= 1 - contourShadeRange + contourShadeRange*(logModulus-math.Floor(logModulus))
+	}
+	if domainColoring.PhaseContours > 0 {
+		phaseStep := hue * float64(domainColoring.PhaseContours)
+		shade *= 1 - contourShadeRange + contourShadeRange*(phaseStep-math.Floor(phaseStep))
+	}
+
+	red, green, blue := hueLightnessToRGB(hue, lightness)
+	return color.NRGBA64{
+		R: uint16(math.Round(red * shade * 0xffff)),
+		G: uint16(math.Round(green * shade * 0xffff)),
+		B: uint16(math.Round(blue * shade * 0xffff)),
+		A: 0xffff,
+	}
+}
+
+// hueLightnessToRGB converts a fully saturated hue and lightness (both from 0 to 1) into red, green and blue (from 0 to 1).
+func hueLightnessToRGB(hue, lightness float64) (float64, float64, float64) {
+	chromaHalf := math.Min(lightness, 1-lightness)
+	channel := func(offset float64) float64 {
+		position := math.Mod(offset+hue*12, 12)
+		return lightness - chromaHalf*math.Max(-1, math.Min(math.Min(position-3, 9-position), 1))
+	}
+	return channel(0), channel(8), channel(4)
+}
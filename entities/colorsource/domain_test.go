@@ -0,0 +1,53 @@
+package colorsource_test
+
+import (
+	. "gopkg.in/check.v1"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"wallpaper/entities/colorsource"
+)
+
+type DomainColoringSuite struct {
+	plainColoring *colorsource.DomainColoring
+}
+
+var _ = Suite(&DomainColoringSuite{})
+
+func (suite *DomainColoringSuite) SetUpTest(checker *C) {
+	suite.plainColoring = &colorsource.DomainColoring{}
+}
+
+func (suite *DomainColoringSuite) TestHueFollowsTheArgument(checker *C) {
+	checker.Assert(colorsource.EightBitColor(suite.plainColoring.ColorFor(complex(1, 0))), Equals, color.NRGBA{R: 255, G: 0, B: 0, A: 255})
+	checker.Assert(colorsource.EightBitColor(suite.plainColoring.ColorFor(cmplx.Rect(1, 2*math.Pi/3))), Equals, color.NRGBA{R: 0, G: 255, B: 0, A: 255})
+	checker.Assert(colorsource.EightBitColor(suite.plainColoring.ColorFor(cmplx.Rect(1, -2*math.Pi/3))), Equals, color.NRGBA{R: 0, G: 0, B: 255, A: 255})
+	checker.Assert(colorsource.EightBitColor(suite.plainColoring.ColorFor(complex(-1, 0))), Equals, color.NRGBA{R: 0, G: 255, B: 255, A: 255})
+}
+
+func (suite *DomainColoringSuite) TestLightnessFollowsTheModulus(checker *C) {
+	checker.Assert(colorsource.EightBitColor(suite.plainColoring.ColorFor(complex(0, 0))), Equals, color.NRGBA{R: 0, G: 0, B: 0, A: 255})
+	checker.Assert(colorsource.EightBitColor(suite.plainColoring.ColorFor(complex(1e9, 0))), Equals, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+}
+
+func (suite *DomainColoringSuite) TestInfinityIsTransparent(checker *C) {
+	checker.Assert(colorsource.EightBitColor(suite.plainColoring.ColorFor(cmplx.Inf())), Equals, color.NRGBA{})
+	checker.Assert(colorsource.EightBitColor(suite.plainColoring.ColorFor(cmplx.NaN())), Equals, color.NRGBA{})
+}
+
+func (suite *DomainColoringSuite) TestModulusContoursRestartWhenTheModulusDoubles(checker *C) {
+	contourColoring := &colorsource.DomainColoring{ModulusContours: true}
+	checker.Assert(colorsource.EightBitColor(contourColoring.ColorFor(complex(1, 0))), Equals, color.NRGBA{R: 179, A: 255})
+	checker.Assert(contourColoring.ColorFor(complex(1.999999, 0)).R > contourColoring.ColorFor(complex(2, 0)).R, Equals, true)
+}
+
+func (suite *DomainColoringSuite) TestPhaseContoursRestartAroundTheCircle(checker *C) {
+	contourColoring := &colorsource.DomainColoring{PhaseContours: 4}
+	checker.Assert(colorsource.EightBitColor(contourColoring.ColorFor(complex(1, 0))), Equals, color.NRGBA{R: 179, A: 255})
+	checker.Assert(colorsource.EightBitColor(contourColoring.ColorFor(cmplx.Rect(1, math.Pi/2-1e-9))).G, Equals, uint8(255))
+	checker.Assert(colorsource.EightBitColor(contourColoring.ColorFor(cmplx.Rect(1, math.Pi/2+1e-9))).G, Equals, uint8(179))
+}
+
+func (suite *DomainColoringSuite) TestPhaseContoursCannotBeNegative(checker *C) {
+	checker.Assert((&colorsource.DomainColoring{PhaseContours: -1}).Validate(), ErrorMatches, "phase_contours cannot be negative")
+}
@@ -0,0 +1,195 @@
+package colorsource
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// GeneratorType is the kind of image a Generator draws.
+type GeneratorType string
+
+// Generator types.
+const (
+	// ColorWheel draws hues around the center, from white at the center to black at the corners.
+	ColorWheel GeneratorType = "color_wheel"
+	// Stripes draws Count stripes, cycling through the Colors.
+	Stripes GeneratorType = "stripes"
+	// Checkerboard draws Count by Count squares, alternating between the first two Colors.
+	Checkerboard GeneratorType = "checkerboard"
+	// Rings draws Count rings around the center, cycling through the Colors.
+	Rings GeneratorType = "rings"
+	// Gradient blends smoothly from one color to the next, with the Colors spread evenly across the image.
+	Gradient GeneratorType = "gradient"
+)
+
+// Directions for stripes and gradients.
+const (
+	// Horizontal stripes run from side to side, stacked from top to bottom. Horizontal gradients change from left to right.
+	Horizontal = "horizontal"
+	// Vertical stripes run from top to bottom, lined up from left to right. Vertical gradients change from top to bottom.
+	Vertical = "vertical"
+)
+
+// DefaultGeneratorSize is the width and height of generated images that do not set their size.
+const DefaultGeneratorSize = 256
+
+// defaultGeneratorColors is a rainbow, like example/rainbow_stripe.png.
+var defaultGeneratorColors = []string{"#ff0000", "#ff8000", "#ffff00", "#00c000", "#0040ff", "#8000c0"}
+
+// Generator draws a color source image, so formula files do not need an image on disk.
+type Generator struct {
+	Type GeneratorType `json:"type" yaml:"type"`
+	// Width and Height are in pixels. They default to DefaultGeneratorSize.
+	Width  int `json:"width,omitempty" yaml:"width,omitempty"`
+	Height int `json:"height,omitempty" yaml:"height,omitempty"`
+	// Colors are written like "#rrggbb" or "#rrggbbaa". Each type has its own default colors.
+	Colors []string `json:"colors,omitempty" yaml:"colors,omitempty"`
+	// Count is the number of stripes, rings or squares along each side of a checkerboard.
+	Count int `json:"count,omitempty" yaml:"count,omitempty"`
+	// Direction is horizontal (the default) or vertical, for stripes and gradients.
+	Direction string `json:"direction,omitempty" yaml:"direction,omitempty"`
+}
+
+var knownGeneratorTypes = map[GeneratorType]bool{
+	ColorWheel:   true,
+	Stripes:      true,
+	Checkerboard: true,
+	Rings:        true,
+	Gradient:     true,
+}
+
+// Validate returns an error if the generator cannot draw an image.
+func (generator *Generator) Validate() error {
+	if !knownGeneratorTypes[generator.Type] {
+		return fmt.Errorf(`unknown generator type: %s`, generator.Type)
+	}
+	if generator.Width < 0 || generator.Height < 0 {
+		return fmt.Errorf(`size cannot be negative: %dx%d`, generator.Width, generator.Height)
+	}
+	if generator.Count < 0 {
+		return errors.New(`count cannot be negative`)
+	}
+	if generator.Direction != "" && generator.Direction != Horizontal && generator.Direction != Vertical {
+		return fmt.Errorf(`direction must be %s or %s: %s`, Horizontal, Vertical, generator.Direction)
+	}
+	_, err := generator.palette()
+	return err
+}
+
+// Generate draws the image.
+func (generator *Generator) Generate() (*image.NRGBA, error) {
+	if err := generator.Validate(); err != nil {
+		return nil, err
+	}
+	palette, _ := generator.palette()
+
+	width := generator.Width
+	if width == 0 {
+		width = DefaultGeneratorSize
+	}
+	height := generator.Height
+	if height == 0 {
+		height = DefaultGeneratorSize
+	}
+	count := generator.Count
+	if count == 0 {
+		count = len(palette)
+	}
+
+	generatedImage := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			// Measure from each pixel's center, so the image is symmetric.
+			across := (float64(x) + 0.5) / float64(width)
+			down := (float64(y) + 0.5) / float64(height)
+			generatedImage.SetNRGBA(x, y, generator.colorAt(across, down, count, palette))
+		}
+	}
+	return generatedImage, nil
+}
+
+// colorAt returns the color at the given position. across and down range from 0 to 1.
+func (generator *Generator) colorAt(across, down float64, count int, palette []color.NRGBA) color.NRGBA {
+	switch generator.Type {
+	case ColorWheel:
+		return colorWheelAt(across*2-1, down*2-1)
+	case Stripes:
+		stripePosition := down
+		if generator.Direction == Vertical {
+			stripePosition = across
+		}
+		return palette[int(stripePosition*float64(count))%len(palette)]
+	case Checkerboard:
+		squareIndex := int(across*float64(count)) + int(down*float64(count))
+		return palette[squareIndex%2]
+	case Rings:
+		distanceToCorner := math.Hypot(across-0.5, down-0.5) / math.Sqrt(0.5)
+		return palette[int(distanceToCorner*float64(count))%len(palette)]
+	}
+
+	gradientPosition := across
+	if generator.Direction == Vertical {
+		gradientPosition = down
+	}
+	return blendEvenlySpacedColors(palette, gradientPosition)
+}
+
+// palette reads the generator's colors, or picks default colors for its type.
+func (generator *Generator) palette() ([]color.NRGBA, error) {
+	colorStrings := generator.Colors
+	if len(colorStrings) == 0 {
+		colorStrings = defaultGeneratorColors
+		if generator.Type == Checkerboard || generator.Type == Gradient {
+			colorStrings = []string{"#000000", "#ffffff"}
+		}
+	}
+	if generator.Type == Checkerboard && len(colorStrings) < 2 {
+		return nil, errors.New(`checkerboards need 2 colors`)
+	}
+
+	palette := []color.NRGBA{}
+	for _, colorString := range colorStrings {
+		parsedColor, err := ParseHexColor(colorString)
+		if err != nil {
+			return nil, err
+		}
+		palette = append(palette, parsedColor)
+	}
+	return palette, nil
+}
+
+// colorWheelAt colors a point in the square from -1-1i to 1+1i.
+//   The hue follows the point's argument, like DomainColoring.
+//   The center is white, the middle of each side is fully saturated and the corners are black.
+func colorWheelAt(x, y float64) color.NRGBA {
+	argumentAsFraction := math.Atan2(y, x) / (2 * math.Pi)
+	hue := argumentAsFraction - math.Floor(argumentAsFraction)
+	lightness := 1 - math.Hypot(x, y)/math.Sqrt2
+	red, green, blue := hueLightnessToRGB(hue, lightness)
+	return color.NRGBA{
+		R: uint8(math.Round(red * 255)),
+		G: uint8(math.Round(green * 255)),
+		B: uint8(math.Round(blue * 255)),
+		A: 255,
+	}
+}
+
+// blendEvenlySpacedColors spreads the colors evenly from 0 to 1, and blends the two closest to position.
+//   Colors are blended with their alpha premultiplied.
+func blendEvenlySpacedColors(colors []color.NRGBA, position float64) color.NRGBA {
+	if len(colors) == 1 {
+		return colors[0]
+	}
+
+	scaledPosition := clampFloat(position, 0, 1) * float64(len(colors)-1)
+	firstIndex := int(math.Min(math.Floor(scaledPosition), float64(len(colors)-2)))
+	ratio := scaledPosition - float64(firstIndex)
+
+	blended := premultipliedColor{}
+	blended.addWeighted(newPremultipliedColor(colors[firstIndex]), 1-ratio)
+	blended.addWeighted(newPremultipliedColor(colors[firstIndex+1]), ratio)
+	return blended.toNRGBA()
+}
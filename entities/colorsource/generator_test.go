@@ -0,0 +1,107 @@
+package colorsource_test
+
+import (
+	. "gopkg.in/check.v1"
+	"image"
+	"image/color"
+	"wallpaper/entities/colorsource"
+)
+
+type GeneratorSuite struct {
+}
+
+var _ = Suite(&GeneratorSuite{})
+
+func (suite *GeneratorSuite) TestDefaultSize(checker *C) {
+	generatedImage, err := (&colorsource.Generator{Type: colorsource.ColorWheel}).Generate()
+	checker.Assert(err, IsNil)
+	checker.Assert(generatedImage.Bounds(), Equals, image.Rect(0, 0, colorsource.DefaultGeneratorSize, colorsource.DefaultGeneratorSize))
+}
+
+func (suite *GeneratorSuite) TestColorWheelIsWhiteInTheCenterAndRedToTheRight(checker *C) {
+	generatedImage, err := (&colorsource.Generator{Type: colorsource.ColorWheel, Width: 101, Height: 101}).Generate()
+	checker.Assert(err, IsNil)
+	checker.Assert(generatedImage.NRGBAAt(50, 50), Equals, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+
+	rightSide := generatedImage.NRGBAAt(100, 50)
+	checker.Assert(rightSide.R > rightSide.G, Equals, true)
+	checker.Assert(rightSide.G, Equals, rightSide.B)
+}
+
+func (suite *GeneratorSuite) TestHorizontalStripesCycleThroughColors(checker *C) {
+	generatedImage, err := (&colorsource.Generator{
+		Type:   colorsource.Stripes,
+		Width:  2,
+		Height: 6,
+		Count:  3,
+		Colors: []string{"#ff0000", "#0000ff"},
+	}).Generate()
+	checker.Assert(err, IsNil)
+
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	for y, expectedColor := range []color.NRGBA{red, red, blue, blue, red, red} {
+		checker.Assert(generatedImage.NRGBAAt(1, y), Equals, expectedColor)
+	}
+}
+
+func (suite *GeneratorSuite) TestVerticalStripes(checker *C) {
+	generatedImage, err := (&colorsource.Generator{
+		Type:      colorsource.Stripes,
+		Width:     4,
+		Height:    1,
+		Count:     2,
+		Direction: colorsource.Vertical,
+		Colors:    []string{"#ff0000", "#0000ff"},
+	}).Generate()
+	checker.Assert(err, IsNil)
+	checker.Assert(generatedImage.NRGBAAt(1, 0), Equals, color.NRGBA{R: 255, A: 255})
+	checker.Assert(generatedImage.NRGBAAt(2, 0), Equals, color.NRGBA{B: 255, A: 255})
+}
+
+func (suite *GeneratorSuite) TestCheckerboardAlternates(checker *C) {
+	generatedImage, err := (&colorsource.Generator{Type: colorsource.Checkerboard, Width: 4, Height: 4, Count: 2}).Generate()
+	checker.Assert(err, IsNil)
+	checker.Assert(generatedImage.NRGBAAt(0, 0), Equals, color.NRGBA{A: 255})
+	checker.Assert(generatedImage.NRGBAAt(2, 0), Equals, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	checker.Assert(generatedImage.NRGBAAt(2, 2), Equals, color.NRGBA{A: 255})
+}
+
+func (suite *GeneratorSuite) TestCheckerboardNeedsTwoColors(checker *C) {
+	_, err := (&colorsource.Generator{Type: colorsource.Checkerboard, Colors: []string{"#ffffff"}}).Generate()
+	checker.Assert(err, ErrorMatches, "checkerboards need 2 colors")
+}
+
+func (suite *GeneratorSuite) TestRingsStartAtTheCenter(checker *C) {
+	generatedImage, err := (&colorsource.Generator{
+		Type:   colorsource.Rings,
+		Width:  10,
+		Height: 10,
+		Count:  2,
+		Colors: []string{"#ff0000", "#0000ff"},
+	}).Generate()
+	checker.Assert(err, IsNil)
+	checker.Assert(generatedImage.NRGBAAt(5, 5), Equals, color.NRGBA{R: 255, A: 255})
+	checker.Assert(generatedImage.NRGBAAt(0, 0), Equals, color.NRGBA{B: 255, A: 255})
+}
+
+func (suite *GeneratorSuite) TestGradientBlendsEvenly(checker *C) {
+	generatedImage, err := (&colorsource.Generator{
+		Type:   colorsource.Gradient,
+		Width:  5,
+		Height: 1,
+		Colors: []string{"#000000", "#ff0000", "#ffffff"},
+	}).Generate()
+	checker.Assert(err, IsNil)
+	checker.Assert(generatedImage.NRGBAAt(0, 0), Equals, color.NRGBA{R: 51, A: 255})
+	checker.Assert(generatedImage.NRGBAAt(2, 0), Equals, color.NRGBA{R: 255, A: 255})
+	checker.Assert(generatedImage.NRGBAAt(4, 0), Equals, color.NRGBA{R: 255, G: 204, B: 204, A: 255})
+}
+
+func (suite *GeneratorSuite) TestValidate(checker *C) {
+	checker.Assert((&colorsource.Generator{Type: "plaid"}).Validate(), ErrorMatches, "unknown generator type: plaid")
+	checker.Assert((&colorsource.Generator{Type: colorsource.Rings, Width: -1}).Validate(), ErrorMatches, "size cannot be negative: -1x0")
+	checker.Assert((&colorsource.Generator{Type: colorsource.Rings, Count: -1}).Validate(), ErrorMatches, "count cannot be negative")
+	checker.Assert((&colorsource.Generator{Type: colorsource.Stripes, Direction: "diagonal"}).Validate(), ErrorMatches, "direction must be horizontal or vertical: diagonal")
+	checker.Assert((&colorsource.Generator{Type: colorsource.Stripes, Colors: []string{"red"}}).Validate(), ErrorMatches, "colors must look like.*")
+}
@@ -0,0 +1,31 @@
+package colorsource
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// ParseHexColor reads colors written like "#rrggbb" or "#rrggbbaa".
+//   Colors without an alpha channel are opaque.
+func ParseHexColor(hexColor string) (color.NRGBA, error) {
+	digits := strings.TrimPrefix(hexColor, "#")
+	if len(digits) != 6 && len(digits) != 8 {
+		return color.NRGBA{}, fmt.Errorf(`colors must look like #rrggbb or #rrggbbaa: %s`, hexColor)
+	}
+	if len(digits) == 6 {
+		digits += "ff"
+	}
+
+	value, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf(`colors must look like #rrggbb or #rrggbbaa: %s`, hexColor)
+	}
+	return color.NRGBA{
+		R: uint8(value >> 24),
+		G: uint8(value >> 16),
+		B: uint8(value >> 8),
+		A: uint8(value),
+	}, nil
+}
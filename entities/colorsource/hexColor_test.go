@@ -0,0 +1,37 @@
+package colorsource_test
+
+import (
+	. "gopkg.in/check.v1"
+	"image/color"
+	"wallpaper/entities/colorsource"
+)
+
+type HexColorSuite struct {
+}
+
+var _ = Suite(&HexColorSuite{})
+
+func (suite *HexColorSuite) TestColorsWithoutAlphaAreOpaque(checker *C) {
+	parsedColor, err := colorsource.ParseHexColor("#204080")
+	checker.Assert(err, IsNil)
+	checker.Assert(parsedColor, Equals, color.NRGBA{R: 0x20, G: 0x40, B: 0x80, A: 0xff})
+}
+
+func (suite *HexColorSuite) TestColorsCanHaveAlpha(checker *C) {
+	parsedColor, err := colorsource.ParseHexColor("#a0b0c040")
+	checker.Assert(err, IsNil)
+	checker.Assert(parsedColor, Equals, color.NRGBA{R: 0xa0, G: 0xb0, B: 0xc0, A: 0x40})
+}
+
+func (suite *HexColorSuite) TestHashIsOptional(checker *C) {
+	parsedColor, err := colorsource.ParseHexColor("FFFFFF")
+	checker.Assert(err, IsNil)
+	checker.Assert(parsedColor, Equals, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+}
+
+func (suite *HexColorSuite) TestBadColorsAreErrors(checker *C) {
+	for _, badColor := range []string{"#fff", "#12345g", "", "#-12345"} {
+		_, err := colorsource.ParseHexColor(badColor)
+		checker.Assert(err, ErrorMatches, "colors must look like #rrggbb or #rrggbbaa: .*")
+	}
+}
@@ -0,0 +1,217 @@
+package colorsource
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// PaletteValue is the number taken from each complex number to find its color in the palette.
+type PaletteValue string
+
+// Palette values.
+const (
+	Modulus       PaletteValue = "modulus"
+	Argument      PaletteValue = "argument"
+	RealPart      PaletteValue = "real"
+	ImaginaryPart PaletteValue = "imaginary"
+)
+
+// PaletteScale changes how values are spread across the palette.
+type PaletteScale string
+
+// Palette scales.
+const (
+	// LinearScale spreads values evenly.
+	LinearScale PaletteScale = "linear"
+	// LogScale gives small values more room than large ones.
+	//   Negative values are scaled like positive ones, then negated.
+	LogScale PaletteScale = "log"
+)
+
+// ColorStop is one color in a palette.
+type ColorStop struct {
+	// Position ranges from 0 (the palette's minimum) to 1 (the palette's maximum).
+	Position float64 `json:"position" yaml:"position"`
+	// Color is written like "#rrggbb" or "#rrggbbaa".
+	Color string `json:"color" yaml:"color"`
+}
+
+// Palette colors complex numbers by running one part of them through a gradient.
+type Palette struct {
+	Value PaletteValue `json:"value" yaml:"value"`
+	// Scale defaults to linear.
+	Scale PaletteScale `json:"scale" yaml:"scale"`
+	// Minimum and Maximum are the values at the start and end of the palette.
+	//   When both are 0, arguments use -pi to pi, and other values use 0 to 1.
+	Minimum float64 `json:"minimum" yaml:"minimum"`
+	Maximum float64 `json:"maximum" yaml:"maximum"`
+	// Cyclic palettes repeat instead of stopping at their ends, and blend the last color into the first.
+	Cyclic bool        `json:"cyclic" yaml:"cyclic"`
+	Stops  []ColorStop `json:"stops" yaml:"stops"`
+}
+
+var knownPaletteValues = map[PaletteValue]bool{
+	Modulus:       true,
+	Argument:      true,
+	RealPart:      true,
+	ImaginaryPart: true,
+}
+
+// CompiledPalette has its colors parsed and sorted, so it is ready to color many numbers.
+type CompiledPalette struct {
+	value         PaletteValue
+	scale         PaletteScale
+	scaledMinimum float64
+	scaledMaximum float64
+	cyclic        bool
+	positions     []float64
+	colors        []premultipliedColor
+}
+
+// Validate returns an error if the palette cannot be used.
+func (palette *Palette) Validate() error {
+	_, err := palette.Compile()
+	return err
+}
+
+// Compile checks the palette and prepares it for coloring.
+func (palette *Palette) Compile() (*CompiledPalette, error) {
+	if !knownPaletteValues[palette.Value] {
+		return nil, fmt.Errorf(`unknown palette value: %s`, palette.Value)
+	}
+	if palette.Scale != "" && palette.Scale != LinearScale && palette.Scale != LogScale {
+		return nil, fmt.Errorf(`unknown palette scale: %s`, palette.Scale)
+	}
+	if len(palette.Stops) == 0 {
+		return nil, errors.New(`palette needs at least one stop`)
+	}
+
+	minimum, maximum := palette.Minimum, palette.Maximum
+	if minimum == 0 && maximum == 0 {
+		maximum = 1
+		if palette.Value == Argument {
+			minimum, maximum = -math.Pi, math.Pi
+		}
+	}
+	if minimum >= maximum {
+		return nil, fmt.Errorf(`palette minimum must be less than its maximum: %g - %g`, minimum, maximum)
+	}
+
+	sortedStops := append([]ColorStop{}, palette.Stops...)
+	sort.SliceStable(sortedStops, func(i, j int) bool {
+		return sortedStops[i].Position < sortedStops[j].Position
+	})
+
+	compiledPalette := &CompiledPalette{
+		value:     palette.Value,
+		scale:     palette.Scale,
+		cyclic:    palette.Cyclic,
+		positions: []float64{},
+		colors:    []premultipliedColor{},
+	}
+	compiledPalette.scaledMinimum = compiledPalette.applyScale(minimum)
+	compiledPalette.scaledMaximum = compiledPalette.applyScale(maximum)
+
+	for _, stop := range sortedStops {
+		if stop.Position < 0 || stop.Position > 1 {
+			return nil, fmt.Errorf(`stop positions must be from 0 to 1: %g`, stop.Position)
+		}
+		stopColor, err := ParseHexColor(stop.Color)
+		if err != nil {
+			return nil, err
+		}
+		compiledPalette.positions = append(compiledPalette.positions, stop.Position)
+		compiledPalette.colors = append(compiledPalette.colors, newPremultipliedColor(stopColor))
+	}
+	return compiledPalette, nil
+}
+
+// ColorFor returns the palette color for the complex number.
+//   Infinite and undefined numbers are transparent.
+func (compiledPalette *CompiledPalette) ColorFor(number complex128) color.NRGBA64 {
+	if cmplx.IsNaN(number) || cmplx.IsInf(number) {
+		return color.NRGBA64{R: 0, G: 0, B: 0, A: 0}
+	}
+
+	var value float64
+	switch compiledPalette.value {
+	case Modulus:
+		value = cmplx.Abs(number)
+	case Argument:
+		value = cmplx.Phase(number)
+	case RealPart:
+		value = real(number)
+	default:
+		value = imag(number)
+	}
+
+	position := (compiledPalette.applyScale(value) - compiledPalette.scaledMinimum) /
+		(compiledPalette.scaledMaximum - compiledPalette.scaledMinimum)
+	if compiledPalette.cyclic {
+		position -= math.Floor(position)
+	} else {
+		position = clampFloat(position, 0, 1)
+	}
+	return compiledPalette.colorAtPosition(position).toNRGBA64()
+}
+
+// applyScale spreads the value using the palette's scale.
+func (compiledPalette *CompiledPalette) applyScale(value float64) float64 {
+	if compiledPalette.scale != LogScale {
+		return value
+	}
+	return math.Copysign(math.Log1p(math.Abs(value)), value)
+}
+
+// colorAtPosition blends the two stops around the position.
+//   Cyclic palettes blend the last stop into the first, past the ends of the stops.
+func (compiledPalette *CompiledPalette) colorAtPosition(position float64) premultipliedColor {
+	lastIndex := len(compiledPalette.positions) - 1
+	nextIndex := sort.SearchFloat64s(compiledPalette.positions, position)
+	if nextIndex <= lastIndex && compiledPalette.positions[nextIndex] == position {
+		return compiledPalette.colors[nextIndex]
+	}
+
+	if nextIndex == 0 || nextIndex > lastIndex {
+		if !compiledPalette.cyclic {
+			return compiledPalette.colors[minimumInt(nextIndex, lastIndex)]
+		}
+
+		gapAcrossTheEnds := compiledPalette.positions[0] + 1 - compiledPalette.positions[lastIndex]
+		distancePastLastStop := position - compiledPalette.positions[lastIndex]
+		if distancePastLastStop < 0 {
+			distancePastLastStop += 1
+		}
+		return blendPremultipliedColors(
+			compiledPalette.colors[lastIndex],
+			compiledPalette.colors[0],
+			distancePastLastStop/gapAcrossTheEnds,
+		)
+	}
+
+	previousPosition := compiledPalette.positions[nextIndex-1]
+	return blendPremultipliedColors(
+		compiledPalette.colors[nextIndex-1],
+		compiledPalette.colors[nextIndex],
+		(position-previousPosition)/(compiledPalette.positions[nextIndex]-previousPosition),
+	)
+}
+
+// blendPremultipliedColors mixes the colors. A ratio of 0 returns the first color and 1 returns the second.
+func blendPremultipliedColors(first, second premultipliedColor, ratio float64) premultipliedColor {
+	blended := premultipliedColor{}
+	blended.addWeighted(first, 1-ratio)
+	blended.addWeighted(second, ratio)
+	return blended
+}
+
+func minimumInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,121 @@
+package colorsource_test
+
+import (
+	. "gopkg.in/check.v1"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"wallpaper/entities/colorsource"
+)
+
+type PaletteSuite struct {
+	blackToWhite []colorsource.ColorStop
+}
+
+var _ = Suite(&PaletteSuite{})
+
+func (suite *PaletteSuite) SetUpTest(checker *C) {
+	suite.blackToWhite = []colorsource.ColorStop{
+		{Position: 0, Color: "#000000"},
+		{Position: 1, Color: "#ffffff"},
+	}
+}
+
+func compilePalette(checker *C, palette *colorsource.Palette) *colorsource.CompiledPalette {
+	compiledPalette, err := palette.Compile()
+	checker.Assert(err, IsNil)
+	return compiledPalette
+}
+
+func gray(level uint8) color.NRGBA {
+	return color.NRGBA{R: level, G: level, B: level, A: 255}
+}
+
+func (suite *PaletteSuite) TestModulusBlendsBetweenStops(checker *C) {
+	compiledPalette := compilePalette(checker, &colorsource.Palette{
+		Value:   colorsource.Modulus,
+		Maximum: 2,
+		Stops:   suite.blackToWhite,
+	})
+	checker.Assert(colorsource.EightBitColor(compiledPalette.ColorFor(complex(0, 0))), Equals, gray(0))
+	checker.Assert(colorsource.EightBitColor(compiledPalette.ColorFor(complex(0, 1))), Equals, gray(128))
+	checker.Assert(colorsource.EightBitColor(compiledPalette.ColorFor(complex(-2, 0))), Equals, gray(255))
+}
+
+func (suite *PaletteSuite) TestValuesPastTheEndsUseTheEndStops(checker *C) {
+	compiledPalette := compilePalette(checker, &colorsource.Palette{
+		Value:   colorsource.RealPart,
+		Minimum: -1,
+		Maximum: 1,
+		Stops:   suite.blackToWhite,
+	})
+	checker.Assert(colorsource.EightBitColor(compiledPalette.ColorFor(complex(-5, 0))), Equals, gray(0))
+	checker.Assert(colorsource.EightBitColor(compiledPalette.ColorFor(complex(5, 0))), Equals, gray(255))
+}
+
+func (suite *PaletteSuite) TestImaginaryPart(checker *C) {
+	compiledPalette := compilePalette(checker, &colorsource.Palette{
+		Value: colorsource.ImaginaryPart,
+		Stops: suite.blackToWhite,
+	})
+	checker.Assert(colorsource.EightBitColor(compiledPalette.ColorFor(complex(7, 0.25))), Equals, gray(64))
+}
+
+func (suite *PaletteSuite) TestArgumentDefaultsToAFullCircle(checker *C) {
+	compiledPalette := compilePalette(checker, &colorsource.Palette{
+		Value: colorsource.Argument,
+		Stops: suite.blackToWhite,
+	})
+	checker.Assert(colorsource.EightBitColor(compiledPalette.ColorFor(complex(1, 0))), Equals, gray(128))
+	checker.Assert(colorsource.EightBitColor(compiledPalette.ColorFor(complex(-1, -1e-12))), Equals, gray(0))
+}
+
+func (suite *PaletteSuite) TestLogScaleGivesSmallValuesMoreRoom(checker *C) {
+	compiledPalette := compilePalette(checker, &colorsource.Palette{
+		Value:   colorsource.Modulus,
+		Scale:   colorsource.LogScale,
+		Maximum: math.E*math.E - 1,
+		Stops:   suite.blackToWhite,
+	})
+	checker.Assert(colorsource.EightBitColor(compiledPalette.ColorFor(complex(math.E-1, 0))), Equals, gray(128))
+}
+
+func (suite *PaletteSuite) TestCyclicPalettesRepeatAndBlendAcrossTheEnds(checker *C) {
+	compiledPalette := compilePalette(checker, &colorsource.Palette{
+		Value:  colorsource.RealPart,
+		Cyclic: true,
+		Stops: []colorsource.ColorStop{
+			{Position: 0.25, Color: "#000000"},
+			{Position: 0.75, Color: "#ffffff"},
+		},
+	})
+	checker.Assert(colorsource.EightBitColor(compiledPalette.ColorFor(complex(0.5, 0))), Equals, gray(128))
+	checker.Assert(colorsource.EightBitColor(compiledPalette.ColorFor(complex(2.5, 0))), Equals, gray(128))
+	checker.Assert(colorsource.EightBitColor(compiledPalette.ColorFor(complex(1, 0))), Equals, gray(128))
+	checker.Assert(colorsource.EightBitColor(compiledPalette.ColorFor(complex(-0.125, 0))), Equals, gray(191))
+}
+
+func (suite *PaletteSuite) TestStopsAreSorted(checker *C) {
+	compiledPalette := compilePalette(checker, &colorsource.Palette{
+		Value: colorsource.RealPart,
+		Stops: []colorsource.ColorStop{
+			{Position: 1, Color: "#ffffff"},
+			{Position: 0, Color: "#000000"},
+		},
+	})
+	checker.Assert(colorsource.EightBitColor(compiledPalette.ColorFor(complex(0, 0))), Equals, gray(0))
+}
+
+func (suite *PaletteSuite) TestInfinityIsTransparent(checker *C) {
+	compiledPalette := compilePalette(checker, &colorsource.Palette{Value: colorsource.Modulus, Stops: suite.blackToWhite})
+	checker.Assert(colorsource.EightBitColor(compiledPalette.ColorFor(cmplx.Inf())), Equals, color.NRGBA{})
+}
+
+func (suite *PaletteSuite) TestValidate(checker *C) {
+	checker.Assert((&colorsource.Palette{Value: "hue", Stops: suite.blackToWhite}).Validate(), ErrorMatches, "unknown palette value: hue")
+	checker.Assert((&colorsource.Palette{Value: colorsource.Modulus, Scale: "square", Stops: suite.blackToWhite}).Validate(), ErrorMatches, "unknown palette scale: square")
+	checker.Assert((&colorsource.Palette{Value: colorsource.Modulus}).Validate(), ErrorMatches, "palette needs at least one stop")
+	checker.Assert((&colorsource.Palette{Value: colorsource.Modulus, Minimum: 2, Maximum: 1, Stops: suite.blackToWhite}).Validate(), ErrorMatches, "palette minimum must be less than its maximum: 2 - 1")
+	checker.Assert((&colorsource.Palette{Value: colorsource.Modulus, Stops: []colorsource.ColorStop{{Position: 2, Color: "#ffffff"}}}).Validate(), ErrorMatches, "stop positions must be from 0 to 1: 2")
+	checker.Assert((&colorsource.Palette{Value: colorsource.Modulus, Stops: []colorsource.ColorStop{{Position: 0, Color: "white"}}}).Validate(), ErrorMatches, "colors must look like.*")
+}
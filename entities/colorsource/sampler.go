@@ -0,0 +1,306 @@
+package colorsource
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// SamplingMode decides how colors between the source image's pixels are chosen.
+type SamplingMode string
+
+// Sampling modes.
+const (
+	// Nearest uses the color of the pixel the point falls in.
+	Nearest SamplingMode = "nearest"
+	// Bilinear blends the 4 closest pixels.
+	Bilinear SamplingMode = "bilinear"
+	// Bicubic blends the 16 closest pixels along smooth curves (Catmull-Rom splines).
+	Bicubic SamplingMode = "bicubic"
+)
+
+var knownSamplingModes = map[SamplingMode]bool{
+	Nearest:  true,
+	Bilinear: true,
+	Bicubic:  true,
+}
+
+// IsKnown returns true if the mode is one of the listed sampling modes.
+func (mode SamplingMode) IsKnown() bool {
+	return knownSamplingModes[mode]
+}
+
+// EdgeMode decides which pixels are used when sampling past the edge of the source image.
+type EdgeMode int
+
+// Edge modes.
+const (
+	// EdgeNone returns transparent colors for Nearest sampling outside of the image.
+	//   Bilinear and bicubic sampling blend with the closest edge pixels.
+	EdgeNone EdgeMode = iota
+	// EdgeClamp uses the closest edge pixel.
+	EdgeClamp
+	// EdgeWrap repeats the source image, like tiles.
+	EdgeWrap
+	// EdgeMirror repeats the source image, flipping every other copy so the tiles meet seamlessly.
+	EdgeMirror
+)
+
+// premultipliedColor stores a color with its alpha premultiplied, scaled from 0 to 0xffff.
+//   Blending premultiplied colors keeps transparent pixels from bleeding their hidden color into their neighbors.
+type premultipliedColor struct {
+	r float64
+	g float64
+	b float64
+	a float64
+}
+
+func newPremultipliedColor(original color.Color) premultipliedColor {
+	r, g, b, a := original.RGBA()
+	return premultipliedColor{
+		r: float64(r),
+		g: float64(g),
+		b: float64(b),
+		a: float64(a),
+	}
+}
+
+// premultipliedPixel converts a stored source pixel so it can be blended.
+func premultipliedPixel(pixel color.RGBA64) premultipliedColor {
+	return premultipliedColor{
+		r: float64(pixel.R),
+		g: float64(pixel.G),
+		b: float64(pixel.B),
+		a: float64(pixel.A),
+	}
+}
+
+// Sampler picks colors from a source image at fractional pixel coordinates.
+//   It can be used by several goroutines at once.
+type Sampler struct {
+	mode   SamplingMode
+	edge   EdgeMode
+	bounds image.Rectangle
+	// pixels are stored row by row with premultiplied alpha, so the source image is only converted once.
+	//   16 bits per channel keep every source's precision in 8 bytes per pixel.
+	pixels []color.RGBA64
+}
+
+// NewSampler copies the source image so it can be sampled using the given mode.
+//   An empty mode uses Nearest.
+//   edge decides what happens past the edges of the source image.
+func NewSampler(source image.Image, mode SamplingMode, edge EdgeMode) *Sampler {
+	if mode == "" {
+		mode = Nearest
+	}
+
+	bounds := source.Bounds()
+	pixels := make([]color.RGBA64, 0, bounds.Dx()*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := source.At(x, y).RGBA()
+			pixels = append(pixels, color.RGBA64{R: uint16(r), G: uint16(g), B: uint16(b), A: uint16(a)})
+		}
+	}
+
+	return &Sampler{
+		mode:   mode,
+		edge:   edge,
+		bounds: bounds,
+		pixels: pixels,
+	}
+}
+
+// Bounds returns the bounds of the source image.
+func (sampler *Sampler) Bounds() image.Rectangle {
+	return sampler.bounds
+}
+
+// At returns the color at (x, y), measured in source image pixels.
+//   Pixel (i, j) covers everything from (i, j) up to (but not including) (i+1, j+1), and its center is at (i+0.5, j+0.5).
+//   Points outside of the source image use the sampler's EdgeMode.
+func (sampler *Sampler) At(x, y float64) color.NRGBA64 {
+	x = sampler.nearTheImage(x, sampler.bounds.Min.X, sampler.bounds.Max.X)
+	y = sampler.nearTheImage(y, sampler.bounds.Min.Y, sampler.bounds.Max.Y)
+
+	var sampledColor premultipliedColor
+	switch sampler.mode {
+	case Bilinear:
+		sampledColor = sampler.bilinearAt(x, y)
+	case Bicubic:
+		sampledColor = sampler.bicubicAt(x, y)
+	default:
+		sampledColor = sampler.nearestAt(x, y)
+	}
+	return sampledColor.toNRGBA64()
+}
+
+func (sampler *Sampler) nearestAt(x, y float64) premultipliedColor {
+	pixelX := int(math.Floor(x))
+	pixelY := int(math.Floor(y))
+	if sampler.edge == EdgeNone && !(image.Point{X: pixelX, Y: pixelY}.In(sampler.bounds)) {
+		return premultipliedColor{}
+	}
+	return sampler.pixelAt(pixelX, pixelY)
+}
+
+// pixelsPastTheEdge is how far outside of the image nearTheImage leaves coordinates.
+//   It is more than the 2 neighboring pixels bicubic sampling reads past the edge.
+const pixelsPastTheEdge = 4
+
+// nearTheImage moves the coordinate close enough to the image that pixel indexes cannot overflow an int,
+//   without changing which colors are sampled.
+//   Wrapped and mirrored coordinates move by whole repeats of the image.
+//   Infinite or undefined ones have no place in a repeat, so they use the first pixel.
+//   Other coordinates are clamped to just past the edges, and undefined ones are treated as before the first pixel.
+func (sampler *Sampler) nearTheImage(coordinate float64, minimum, maximum int) float64 {
+	switch sampler.edge {
+	case EdgeWrap, EdgeMirror:
+		if math.IsNaN(coordinate) || math.IsInf(coordinate, 0) {
+			return float64(minimum)
+		}
+		repeatSize := float64(maximum - minimum)
+		if sampler.edge == EdgeMirror {
+			repeatSize *= 2
+		}
+		fromMinimum := math.Mod(coordinate-float64(minimum), repeatSize)
+		if fromMinimum < 0 {
+			fromMinimum += repeatSize
+		}
+		return float64(minimum) + fromMinimum
+	}
+	if math.IsNaN(coordinate) {
+		return float64(minimum - pixelsPastTheEdge)
+	}
+	return clampFloat(coordinate, float64(minimum-pixelsPastTheEdge), float64(maximum+pixelsPastTheEdge))
+}
+
+// pixelAt returns the source pixel, using the EdgeMode to find pixels outside of the image.
+func (sampler *Sampler) pixelAt(pixelX, pixelY int) premultipliedColor {
+	pixelX = sampler.edgeIndex(pixelX, sampler.bounds.Min.X, sampler.bounds.Max.X)
+	pixelY = sampler.edgeIndex(pixelY, sampler.bounds.Min.Y, sampler.bounds.Max.Y)
+	return premultipliedPixel(sampler.pixels[(pixelY-sampler.bounds.Min.Y)*sampler.bounds.Dx()+(pixelX-sampler.bounds.Min.X)])
+}
+
+// edgeIndex moves index somewhere from minimum up to (but not including) maximum.
+func (sampler *Sampler) edgeIndex(index, minimum, maximum int) int {
+	size := maximum - minimum
+	switch sampler.edge {
+	case EdgeWrap:
+		return minimum + positiveModulo(index-minimum, size)
+	case EdgeMirror:
+		indexInPair := positiveModulo(index-minimum, 2*size)
+		if indexInPair >= size {
+			indexInPair = 2*size - 1 - indexInPair
+		}
+		return minimum + indexInPair
+	}
+	return clampInt(index, minimum, maximum-1)
+}
+
+func positiveModulo(value, divisor int) int {
+	remainder := value % divisor
+	if remainder < 0 {
+		remainder += divisor
+	}
+	return remainder
+}
+
+func (sampler *Sampler) bilinearAt(x, y float64) premultipliedColor {
+	left, horizontalRatio := splitIntoPixelAndRatio(x)
+	top, verticalRatio := splitIntoPixelAndRatio(y)
+
+	sampledColor := premultipliedColor{}
+	for row := 0; row < 2; row++ {
+		rowWeight := 1 - verticalRatio
+		if row == 1 {
+			rowWeight = verticalRatio
+		}
+		for column := 0; column < 2; column++ {
+			columnWeight := 1 - horizontalRatio
+			if column == 1 {
+				columnWeight = horizontalRatio
+			}
+			sampledColor.addWeighted(sampler.pixelAt(left+column, top+row), rowWeight*columnWeight)
+		}
+	}
+	return sampledColor
+}
+
+func (sampler *Sampler) bicubicAt(x, y float64) premultipliedColor {
+	left, horizontalRatio := splitIntoPixelAndRatio(x)
+	top, verticalRatio := splitIntoPixelAndRatio(y)
+	horizontalWeights := catmullRomWeights(horizontalRatio)
+	verticalWeights := catmullRomWeights(verticalRatio)
+
+	sampledColor := premultipliedColor{}
+	for row := 0; row < 4; row++ {
+		for column := 0; column < 4; column++ {
+			sampledColor.addWeighted(
+				sampler.pixelAt(left+column-1, top+row-1),
+				verticalWeights[row]*horizontalWeights[column],
+			)
+		}
+	}
+	return sampledColor
+}
+
+// splitIntoPixelAndRatio finds the pixel whose center is at or before the coordinate,
+//   and how far the coordinate is towards the next pixel's center (from 0 to 1).
+func splitIntoPixelAndRatio(coordinate float64) (int, float64) {
+	fromFirstCenter := coordinate - 0.5
+	pixel := math.Floor(fromFirstCenter)
+	return int(pixel), fromFirstCenter - pixel
+}
+
+// catmullRomWeights returns how much each of 4 neighboring pixels contributes,
+//   given the ratio between the second and third pixels.
+func catmullRomWeights(ratio float64) [4]float64 {
+	ratioSquared := ratio * ratio
+	ratioCubed := ratioSquared * ratio
+	return [4]float64{
+		(-ratioCubed + 2*ratioSquared - ratio) / 2,
+		(3*ratioCubed - 5*ratioSquared + 2) / 2,
+		(-3*ratioCubed + 4*ratioSquared + ratio) / 2,
+		(ratioCubed - ratioSquared) / 2,
+	}
+}
+
+func (premultiplied *premultipliedColor) addWeighted(other premultipliedColor, weight float64) {
+	premultiplied.r += other.r * weight
+	premultiplied.g += other.g * weight
+	premultiplied.b += other.b * weight
+	premultiplied.a += other.a * weight
+}
+
+// toNRGBA64 converts the color back to 16 bits per channel, without premultiplied alpha.
+//   Bicubic sampling can overshoot, so channels are clamped first.
+func (premultiplied premultipliedColor) toNRGBA64() color.NRGBA64 {
+	alpha := clampFloat(premultiplied.a, 0, 0xffff)
+	premultipliedColor := color.RGBA64{
+		R: uint16(math.Round(clampFloat(premultiplied.r, 0, alpha))),
+		G: uint16(math.Round(clampFloat(premultiplied.g, 0, alpha))),
+		B: uint16(math.Round(clampFloat(premultiplied.b, 0, alpha))),
+		A: uint16(math.Round(alpha)),
+	}
+	return color.NRGBA64Model.Convert(premultipliedColor).(color.NRGBA64)
+}
+
+// toNRGBA converts the color back to 8 bits per channel, without premultiplied alpha.
+func (premultiplied premultipliedColor) toNRGBA() color.NRGBA {
+	return EightBitColor(premultiplied.toNRGBA64())
+}
+
+func clampInt(value, minimum, maximum int) int {
+	if value < minimum {
+		return minimum
+	}
+	if value > maximum {
+		return maximum
+	}
+	return value
+}
+
+func clampFloat(value, minimum, maximum float64) float64 {
+	return math.Max(minimum, math.Min(value, maximum))
+}
@@ -0,0 +1,168 @@
+package colorsource_test
+
+import (
+	. "gopkg.in/check.v1"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+	"wallpaper/entities/colorsource"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type SamplerSuite struct {
+	colorSource image.Image
+}
+
+var _ = Suite(&SamplerSuite{})
+
+func (suite *SamplerSuite) SetUpTest(checker *C) {
+	colorSource := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	colorSource.Set(0, 0, color.NRGBA{R: 200, A: 255})
+	colorSource.Set(1, 0, color.NRGBA{B: 100, A: 0})
+	suite.colorSource = colorSource
+}
+
+func (suite *SamplerSuite) TestUnknownModeIsNotKnown(checker *C) {
+	checker.Assert(colorsource.Bilinear.IsKnown(), Equals, true)
+	checker.Assert(colorsource.SamplingMode("blurry").IsKnown(), Equals, false)
+}
+
+func (suite *SamplerSuite) TestNearestUsesThePixelThePointFallsIn(checker *C) {
+	sampler := colorsource.NewSampler(suite.colorSource, colorsource.Nearest, colorsource.EdgeNone)
+	checker.Assert(colorsource.EightBitColor(sampler.At(0.9, 0.5)), Equals, color.NRGBA{R: 200, A: 255})
+	checker.Assert(colorsource.EightBitColor(sampler.At(1.1, 0.5)), Equals, color.NRGBA{})
+}
+
+func (suite *SamplerSuite) TestEmptyModeIsNearest(checker *C) {
+	sampler := colorsource.NewSampler(suite.colorSource, "", colorsource.EdgeNone)
+	checker.Assert(colorsource.EightBitColor(sampler.At(0.9, 0.5)), Equals, color.NRGBA{R: 200, A: 255})
+}
+
+func (suite *SamplerSuite) TestNearestIsTransparentOutsideOfTheImage(checker *C) {
+	sampler := colorsource.NewSampler(suite.colorSource, colorsource.Nearest, colorsource.EdgeNone)
+	checker.Assert(colorsource.EightBitColor(sampler.At(2, 0)), Equals, color.NRGBA{})
+}
+
+func (suite *SamplerSuite) TestNearestKeepsTheColorOfTranslucentPixels(checker *C) {
+	translucentSource := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	translucentSource.Set(0, 0, color.NRGBA{R: 200, G: 100, A: 128})
+
+	sampler := colorsource.NewSampler(translucentSource, colorsource.Nearest, colorsource.EdgeNone)
+	checker.Assert(colorsource.EightBitColor(sampler.At(0.5, 0.5)), Equals, color.NRGBA{R: 200, G: 100, A: 128})
+}
+
+func (suite *SamplerSuite) TestBilinearMatchesPixelCenters(checker *C) {
+	sampler := colorsource.NewSampler(suite.colorSource, colorsource.Bilinear, colorsource.EdgeNone)
+	checker.Assert(colorsource.EightBitColor(sampler.At(0.5, 0.5)), Equals, color.NRGBA{R: 200, A: 255})
+}
+
+func (suite *SamplerSuite) TestBilinearDoesNotBleedTransparentColors(checker *C) {
+	sampler := colorsource.NewSampler(suite.colorSource, colorsource.Bilinear, colorsource.EdgeNone)
+	checker.Assert(colorsource.EightBitColor(sampler.At(1.0, 0.5)), Equals, color.NRGBA{R: 200, A: 128})
+}
+
+func (suite *SamplerSuite) TestBilinearExtendsTheEdges(checker *C) {
+	sampler := colorsource.NewSampler(suite.colorSource, colorsource.Bilinear, colorsource.EdgeNone)
+	checker.Assert(colorsource.EightBitColor(sampler.At(0, 0)), Equals, color.NRGBA{R: 200, A: 255})
+}
+
+func (suite *SamplerSuite) TestBicubicMatchesPixelCenters(checker *C) {
+	sampler := colorsource.NewSampler(suite.colorSource, colorsource.Bicubic, colorsource.EdgeNone)
+	checker.Assert(colorsource.EightBitColor(sampler.At(0.5, 0.5)), Equals, color.NRGBA{R: 200, A: 255})
+	checker.Assert(colorsource.EightBitColor(sampler.At(1.5, 0.5)), Equals, color.NRGBA{})
+}
+
+func (suite *SamplerSuite) TestBicubicIsSmoothBetweenPixels(checker *C) {
+	gradientSource := image.NewGray(image.Rect(0, 0, 4, 1))
+	for x := 0; x < 4; x++ {
+		gradientSource.SetGray(x, 0, color.Gray{Y: uint8(x * 60)})
+	}
+
+	sampler := colorsource.NewSampler(gradientSource, colorsource.Bicubic, colorsource.EdgeNone)
+	checker.Assert(colorsource.EightBitColor(sampler.At(2.0, 0.5)), Equals, color.NRGBA{R: 90, G: 90, B: 90, A: 255})
+}
+
+func newRedGreenBlueSource() image.Image {
+	colorSource := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	colorSource.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	colorSource.Set(1, 0, color.NRGBA{G: 255, A: 255})
+	colorSource.Set(2, 0, color.NRGBA{B: 255, A: 255})
+	return colorSource
+}
+
+func (suite *SamplerSuite) TestClampEdgeUsesTheClosestPixel(checker *C) {
+	sampler := colorsource.NewSampler(newRedGreenBlueSource(), colorsource.Nearest, colorsource.EdgeClamp)
+	checker.Assert(colorsource.EightBitColor(sampler.At(-3, 0.5)), Equals, color.NRGBA{R: 255, A: 255})
+	checker.Assert(colorsource.EightBitColor(sampler.At(3, 0.5)), Equals, color.NRGBA{B: 255, A: 255})
+	checker.Assert(colorsource.EightBitColor(sampler.At(1.5, 9)), Equals, color.NRGBA{G: 255, A: 255})
+}
+
+func (suite *SamplerSuite) TestWrapEdgeRepeatsTheImage(checker *C) {
+	sampler := colorsource.NewSampler(newRedGreenBlueSource(), colorsource.Nearest, colorsource.EdgeWrap)
+	checker.Assert(colorsource.EightBitColor(sampler.At(3.5, 0.5)), Equals, color.NRGBA{R: 255, A: 255})
+	checker.Assert(colorsource.EightBitColor(sampler.At(-0.5, 0.5)), Equals, color.NRGBA{B: 255, A: 255})
+	checker.Assert(colorsource.EightBitColor(sampler.At(-4.5, 7.5)), Equals, color.NRGBA{G: 255, A: 255})
+}
+
+func (suite *SamplerSuite) TestMirrorEdgeFlipsEveryOtherCopy(checker *C) {
+	sampler := colorsource.NewSampler(newRedGreenBlueSource(), colorsource.Nearest, colorsource.EdgeMirror)
+	checker.Assert(colorsource.EightBitColor(sampler.At(3.5, 0.5)), Equals, color.NRGBA{B: 255, A: 255})
+	checker.Assert(colorsource.EightBitColor(sampler.At(5.5, 0.5)), Equals, color.NRGBA{R: 255, A: 255})
+	checker.Assert(colorsource.EightBitColor(sampler.At(6.5, 0.5)), Equals, color.NRGBA{R: 255, A: 255})
+	checker.Assert(colorsource.EightBitColor(sampler.At(-0.5, 0.5)), Equals, color.NRGBA{R: 255, A: 255})
+	checker.Assert(colorsource.EightBitColor(sampler.At(-1.5, 0.5)), Equals, color.NRGBA{G: 255, A: 255})
+}
+
+func (suite *SamplerSuite) TestBilinearWrapBlendsAcrossTheSeam(checker *C) {
+	sampler := colorsource.NewSampler(newRedGreenBlueSource(), colorsource.Bilinear, colorsource.EdgeWrap)
+	checker.Assert(colorsource.EightBitColor(sampler.At(3, 0.5)), Equals, color.NRGBA{R: 128, B: 128, A: 255})
+}
+
+func (suite *SamplerSuite) TestSixteenBitSourcesKeepTheirPrecision(checker *C) {
+	sixteenBitSource := image.NewNRGBA64(image.Rect(0, 0, 2, 1))
+	sixteenBitSource.SetNRGBA64(0, 0, color.NRGBA64{R: 0x1234, A: 0xffff})
+	sixteenBitSource.SetNRGBA64(1, 0, color.NRGBA64{R: 0x1236, A: 0xffff})
+
+	nearestSampler := colorsource.NewSampler(sixteenBitSource, colorsource.Nearest, colorsource.EdgeClamp)
+	checker.Assert(nearestSampler.At(0.5, 0.5), Equals, color.NRGBA64{R: 0x1234, A: 0xffff})
+
+	bilinearSampler := colorsource.NewSampler(sixteenBitSource, colorsource.Bilinear, colorsource.EdgeClamp)
+	checker.Assert(bilinearSampler.At(1.0, 0.5), Equals, color.NRGBA64{R: 0x1235, A: 0xffff})
+}
+
+func (suite *SamplerSuite) TestHugeAndInfiniteCoordinatesUseTheEdgeMode(checker *C) {
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	huge := 0x1p100
+	infinity := math.Inf(1)
+
+	for _, mode := range []colorsource.SamplingMode{colorsource.Nearest, colorsource.Bilinear, colorsource.Bicubic} {
+		clampSampler := colorsource.NewSampler(newRedGreenBlueSource(), mode, colorsource.EdgeClamp)
+		checker.Assert(colorsource.EightBitColor(clampSampler.At(huge, 0.5)), Equals, blue, Commentf("%s", mode))
+		checker.Assert(colorsource.EightBitColor(clampSampler.At(-huge, 0.5)), Equals, red, Commentf("%s", mode))
+		checker.Assert(colorsource.EightBitColor(clampSampler.At(infinity, -infinity)), Equals, blue, Commentf("%s", mode))
+		checker.Assert(colorsource.EightBitColor(clampSampler.At(-infinity, infinity)), Equals, red, Commentf("%s", mode))
+
+		// 2^100 is 1 more than a multiple of 3, and 4 more than a multiple of 6.
+		wrapSampler := colorsource.NewSampler(newRedGreenBlueSource(), mode, colorsource.EdgeWrap)
+		checker.Assert(wrapSampler.At(huge, 0.5), Equals, wrapSampler.At(1, 0.5), Commentf("%s", mode))
+		checker.Assert(wrapSampler.At(-huge, 0.5), Equals, wrapSampler.At(2, 0.5), Commentf("%s", mode))
+		checker.Assert(wrapSampler.At(infinity, 0.5), Equals, wrapSampler.At(0, 0.5), Commentf("%s", mode))
+		checker.Assert(wrapSampler.At(-infinity, 0.5), Equals, wrapSampler.At(0, 0.5), Commentf("%s", mode))
+
+		mirrorSampler := colorsource.NewSampler(newRedGreenBlueSource(), mode, colorsource.EdgeMirror)
+		checker.Assert(mirrorSampler.At(huge, 0.5), Equals, mirrorSampler.At(4, 0.5), Commentf("%s", mode))
+		checker.Assert(mirrorSampler.At(-huge, 0.5), Equals, mirrorSampler.At(2, 0.5), Commentf("%s", mode))
+		checker.Assert(mirrorSampler.At(infinity, 0.5), Equals, mirrorSampler.At(0, 0.5), Commentf("%s", mode))
+		checker.Assert(mirrorSampler.At(-infinity, 0.5), Equals, mirrorSampler.At(0, 0.5), Commentf("%s", mode))
+	}
+
+	noEdgeSampler := colorsource.NewSampler(newRedGreenBlueSource(), colorsource.Nearest, colorsource.EdgeNone)
+	checker.Assert(colorsource.EightBitColor(noEdgeSampler.At(huge, 0.5)), Equals, color.NRGBA{})
+	checker.Assert(colorsource.EightBitColor(noEdgeSampler.At(-infinity, 0.5)), Equals, color.NRGBA{})
+	blendingNoEdgeSampler := colorsource.NewSampler(newRedGreenBlueSource(), colorsource.Bilinear, colorsource.EdgeNone)
+	checker.Assert(colorsource.EightBitColor(blendingNoEdgeSampler.At(huge, 0.5)), Equals, blue)
+	checker.Assert(colorsource.EightBitColor(blendingNoEdgeSampler.At(-infinity, 0.5)), Equals, red)
+}
@@ -0,0 +1,466 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"math"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultFramesPerSecond is used when an animation does not set its frame rate.
+const DefaultFramesPerSecond = 20
+
+// InterpolationMode decides how a value moves from one keyframe to the next.
+type InterpolationMode string
+
+// Interpolation modes.
+const (
+	// InterpolateLinearly moves at the same speed the whole way.
+	InterpolateLinearly InterpolationMode = "linear"
+	// EaseIn starts slowly and speeds up.
+	EaseIn InterpolationMode = "ease_in"
+	// EaseOut starts quickly and slows down.
+	EaseOut InterpolationMode = "ease_out"
+	// EaseInOut starts and ends slowly.
+	EaseInOut InterpolationMode = "ease_in_out"
+)
+
+// Keyframe sets a field's value at one frame of an animation.
+type Keyframe struct {
+	Frame int `json:"frame" yaml:"frame"`
+	// Value is a number, or a mapping of numbers like {real: 1, imaginary: 0}.
+	//   Values of integer fields are rounded, so they step from one keyframe's value to the next.
+	Value interface{} `json:"value" yaml:"value"`
+}
+
+// AnimationTrack moves one field of the command through its keyframes.
+//   Frames before the first keyframe and after the last one hold that keyframe's value.
+type AnimationTrack struct {
+	// Field is the path to the value, written with the formula file's keys and list indices,
+	//   like lattice_pattern.wave_packets[0].multiplier.
+	Field string `json:"field" yaml:"field"`
+	// Interpolation defaults to linear.
+	Interpolation InterpolationMode `json:"interpolation,omitempty" yaml:"interpolation,omitempty"`
+	Keyframes     []Keyframe        `json:"keyframes" yaml:"keyframes"`
+}
+
+// Animation renders the command once per frame, changing fields from frame to frame.
+type Animation struct {
+	Frames int `json:"frames" yaml:"frames"`
+	// FramesPerSecond defaults to DefaultFramesPerSecond.
+	FramesPerSecond float64           `json:"frames_per_second,omitempty" yaml:"frames_per_second,omitempty"`
+	Tracks          []*AnimationTrack `json:"tracks" yaml:"tracks"`
+}
+
+// Validate returns an error if the animation cannot be used.
+//   Fields are checked against a command by CreateSymmetryPattern.Validate.
+func (animation *Animation) Validate() error {
+	if animation.Frames < 1 {
+		return fmt.Errorf(`frames must be positive: %d`, animation.Frames)
+	}
+	if animation.FramesPerSecond < 0 {
+		return fmt.Errorf(`frames_per_second must be positive: %g`, animation.FramesPerSecond)
+	}
+	if len(animation.Tracks) == 0 {
+		return errors.New(`animation needs at least one track`)
+	}
+	for index, track := range animation.Tracks {
+		if trackErr := track.Validate(); trackErr != nil {
+			return fmt.Errorf(`tracks[%d]: %v`, index, trackErr)
+		}
+	}
+	return nil
+}
+
+// FrameRate returns the number of frames shown each second.
+func (animation *Animation) FrameRate() float64 {
+	if animation.FramesPerSecond == 0 {
+		return DefaultFramesPerSecond
+	}
+	return animation.FramesPerSecond
+}
+
+// FrameFilename numbers the filename for one frame of an image sequence,
+//   so pattern.png becomes pattern_0000.png, pattern_0001.png and so on.
+func (animation *Animation) FrameFilename(filename string, frame int) string {
+	digits := len(strconv.Itoa(animation.Frames - 1))
+	if digits < 4 {
+		digits = 4
+	}
+	extension := filepath.Ext(filename)
+	return fmt.Sprintf("%s_%0*d%s", strings.TrimSuffix(filename, extension), digits, frame, extension)
+}
+
+// Validate returns an error if the track cannot be used.
+func (track *AnimationTrack) Validate() error {
+	if _, pathErr := parseFieldPath(track.Field); pathErr != nil {
+		return pathErr
+	}
+	if !track.Interpolation.IsKnown() {
+		return fmt.Errorf(`unknown interpolation: %s`, track.Interpolation)
+	}
+	if len(track.Keyframes) == 0 {
+		return errors.New(`track needs at least one keyframe`)
+	}
+
+	for index := 1; index < len(track.Keyframes); index++ {
+		previous, next := track.Keyframes[index-1], track.Keyframes[index]
+		if next.Frame <= previous.Frame {
+			return fmt.Errorf(`keyframes must be in order of frame, without repeats: %d`, next.Frame)
+		}
+		if _, shapeErr := interpolateValues(previous.Value, next.Value, 0.5); shapeErr != nil {
+			return fmt.Errorf(`keyframes at frames %d and %d: %v`, previous.Frame, next.Frame, shapeErr)
+		}
+	}
+	return nil
+}
+
+// ValueAt returns the track's value at the given frame.
+func (track *AnimationTrack) ValueAt(frame int) (interface{}, error) {
+	keyframes := track.Keyframes
+	if frame <= keyframes[0].Frame {
+		return keyframes[0].Value, nil
+	}
+
+	for index := 1; index < len(keyframes); index++ {
+		previous, next := keyframes[index-1], keyframes[index]
+		if frame < next.Frame {
+			ratio := float64(frame-previous.Frame) / float64(next.Frame-previous.Frame)
+			return interpolateValues(previous.Value, next.Value, track.Interpolation.Ease(ratio))
+		}
+	}
+	return keyframes[len(keyframes)-1].Value, nil
+}
+
+// Ease bends a ratio from 0 to 1 according to the interpolation mode.
+func (mode InterpolationMode) Ease(ratio float64) float64 {
+	switch mode {
+	case EaseIn:
+		return ratio * ratio * ratio
+	case EaseOut:
+		remaining := 1 - ratio
+		return 1 - remaining*remaining*remaining
+	case EaseInOut:
+		return ratio * ratio * (3 - 2*ratio)
+	}
+	return ratio
+}
+
+// IsKnown returns true if the mode is one of the interpolation modes. An empty mode is linear.
+func (mode InterpolationMode) IsKnown() bool {
+	switch mode {
+	case "", InterpolateLinearly, EaseIn, EaseOut, EaseInOut:
+		return true
+	}
+	return false
+}
+
+// interpolateValues blends two keyframe values. A ratio of 0 returns the first value and 1 returns the second.
+//   Numbers are blended, mappings and lists are blended item by item, and anything else must be equal.
+func interpolateValues(from, to interface{}, ratio float64) (interface{}, error) {
+	fromNumber, fromIsNumber := numberFromValue(from)
+	toNumber, toIsNumber := numberFromValue(to)
+	if fromIsNumber && toIsNumber {
+		return fromNumber + (toNumber-fromNumber)*ratio, nil
+	}
+
+	fromMapping, fromIsMapping := mappingFromValue(from)
+	toMapping, toIsMapping := mappingFromValue(to)
+	if fromIsMapping && toIsMapping && len(fromMapping) == len(toMapping) {
+		blendedMapping := map[string]interface{}{}
+		for key, fromItem := range fromMapping {
+			toItem, found := toMapping[key]
+			if !found {
+				return nil, fmt.Errorf(`only one keyframe has %s`, key)
+			}
+			blendedItem, err := interpolateValues(fromItem, toItem, ratio)
+			if err != nil {
+				return nil, err
+			}
+			blendedMapping[key] = blendedItem
+		}
+		return blendedMapping, nil
+	}
+
+	fromList, fromIsList := from.([]interface{})
+	toList, toIsList := to.([]interface{})
+	if fromIsList && toIsList && len(fromList) == len(toList) {
+		blendedList := []interface{}{}
+		for index := range fromList {
+			blendedItem, err := interpolateValues(fromList[index], toList[index], ratio)
+			if err != nil {
+				return nil, err
+			}
+			blendedList = append(blendedList, blendedItem)
+		}
+		return blendedList, nil
+	}
+
+	if reflect.DeepEqual(from, to) {
+		return from, nil
+	}
+	return nil, fmt.Errorf(`cannot blend %v into %v`, from, to)
+}
+
+// numberFromValue returns the value as a float64 if it is a number.
+func numberFromValue(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case int:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	case uint64:
+		return float64(number), true
+	case float64:
+		return number, true
+	}
+	return 0, false
+}
+
+// mappingFromValue returns the value with string keys if it is a mapping.
+//   YAML mappings use interface{} keys and JSON objects use string keys.
+func mappingFromValue(value interface{}) (map[string]interface{}, bool) {
+	switch mapping := value.(type) {
+	case map[string]interface{}:
+		return mapping, true
+	case map[interface{}]interface{}:
+		mappingWithStringKeys := map[string]interface{}{}
+		for key, item := range mapping {
+			mappingWithStringKeys[fmt.Sprint(key)] = item
+		}
+		return mappingWithStringKeys, true
+	}
+	return nil, false
+}
+
+// roundIntegerFields rounds the numbers in the value that will be stored in integer fields.
+//   valueType is the type of the field the value is stored in. nil types are left alone.
+func roundIntegerFields(value interface{}, valueType reflect.Type) interface{} {
+	for valueType != nil && valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	if valueType == nil {
+		return value
+	}
+
+	switch valueType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if number, isNumber := numberFromValue(value); isNumber {
+			return int(math.Round(number))
+		}
+	case reflect.Struct:
+		if mapping, isMapping := mappingFromValue(value); isMapping {
+			roundedMapping := map[string]interface{}{}
+			for key, item := range mapping {
+				roundedMapping[key] = roundIntegerFields(item, structFieldTypeForKey(valueType, key))
+			}
+			return roundedMapping
+		}
+	case reflect.Slice:
+		if list, isList := value.([]interface{}); isList {
+			roundedList := []interface{}{}
+			for _, item := range list {
+				roundedList = append(roundedList, roundIntegerFields(item, valueType.Elem()))
+			}
+			return roundedList
+		}
+	}
+	return value
+}
+
+// fieldTypeAtPath follows the path through the marshaled command's types.
+//   It returns nil if the path cannot be followed.
+func fieldTypeAtPath(path string) reflect.Type {
+	steps, err := parseFieldPath(path)
+	if err != nil {
+		return nil
+	}
+
+	fieldType := reflect.TypeOf(CreateWallpaperCommandMarshal{})
+	for _, step := range steps {
+		for fieldType != nil && fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType == nil {
+			return nil
+		}
+		if step.isIndex {
+			if fieldType.Kind() != reflect.Slice {
+				return nil
+			}
+			fieldType = fieldType.Elem()
+			continue
+		}
+		if fieldType.Kind() != reflect.Struct {
+			return nil
+		}
+		fieldType = structFieldTypeForKey(fieldType, step.key)
+	}
+	return fieldType
+}
+
+// structFieldTypeForKey returns the type of the struct field that is marshaled with the YAML key.
+//   Untagged fields use their lowercased name, like the yaml package does.
+//   Untagged and inline struct fields are searched too, because types like SampleSourceMarshal
+//   and the overlays marshal those fields in their own place.
+func structFieldTypeForKey(structType reflect.Type, key string) reflect.Type {
+	for fieldIndex := 0; fieldIndex < structType.NumField(); fieldIndex++ {
+		field := structType.Field(fieldIndex)
+		isTagged := field.Tag.Get("yaml") != ""
+		isInline := strings.HasSuffix(field.Tag.Get("yaml"), ",inline")
+		fieldKey := strings.ToLower(field.Name)
+		if isTagged {
+			fieldKey = strings.Split(field.Tag.Get("yaml"), ",")[0]
+		}
+		if fieldKey == key {
+			return field.Type
+		}
+
+		embeddedType := field.Type
+		for embeddedType.Kind() == reflect.Ptr {
+			embeddedType = embeddedType.Elem()
+		}
+		if (!isTagged || isInline) && embeddedType.Kind() == reflect.Struct {
+			if nestedType := structFieldTypeForKey(embeddedType, key); nestedType != nil {
+				return nestedType
+			}
+		}
+	}
+	return nil
+}
+
+// fieldPathStep is either a key in a mapping or an index in a list.
+type fieldPathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+var fieldPathPart = regexp.MustCompile(`^([A-Za-z0-9_]+)((?:\[[0-9]+\])*)$`)
+var fieldPathIndex = regexp.MustCompile(`\[([0-9]+)\]`)
+
+// parseFieldPath splits a path like lattice_pattern.wave_packets[0].multiplier into steps.
+func parseFieldPath(path string) ([]fieldPathStep, error) {
+	if path == "" {
+		return nil, errors.New(`field is required`)
+	}
+
+	steps := []fieldPathStep{}
+	for _, part := range strings.Split(path, ".") {
+		partMatch := fieldPathPart.FindStringSubmatch(part)
+		if partMatch == nil {
+			return nil, fmt.Errorf(`cannot read field %s`, path)
+		}
+		steps = append(steps, fieldPathStep{key: partMatch[1]})
+		for _, indexMatch := range fieldPathIndex.FindAllStringSubmatch(partMatch[2], -1) {
+			index, _ := strconv.Atoi(indexMatch[1])
+			steps = append(steps, fieldPathStep{index: index, isIndex: true})
+		}
+	}
+	return steps, nil
+}
+
+// setFieldInTree replaces the value at the path inside a tree read from YAML.
+//   The field must already be in the tree, so misspelled paths are caught.
+func setFieldInTree(tree interface{}, path string, value interface{}) error {
+	steps, err := parseFieldPath(path)
+	if err != nil {
+		return err
+	}
+
+	container := tree
+	for stepIndex, step := range steps {
+		isLastStep := stepIndex == len(steps)-1
+		if step.isIndex {
+			list, isList := container.([]interface{})
+			if !isList || step.index >= len(list) {
+				return fmt.Errorf(`%s has no item %d`, path, step.index)
+			}
+			if isLastStep {
+				list[step.index] = value
+				return nil
+			}
+			container = list[step.index]
+			continue
+		}
+
+		mapping, isMapping := container.(map[interface{}]interface{})
+		if !isMapping {
+			return fmt.Errorf(`%s has no field %s`, path, step.key)
+		}
+		child, found := mapping[step.key]
+		if !found {
+			return fmt.Errorf(`%s has no field %s`, path, step.key)
+		}
+		if isLastStep {
+			mapping[step.key] = value
+			return nil
+		}
+		container = child
+	}
+	return nil
+}
+
+// AnimationFrame returns a copy of the command with every animation track set to its value at the frame.
+//   The copy has no animation, so it renders a single image.
+func (command *CreateSymmetryPattern) AnimationFrame(frame int) (*CreateSymmetryPattern, error) {
+	commandMarshal := command.MarshalObject()
+	commandMarshal.Animation = nil
+	commandYAML, err := yaml.Marshal(commandMarshal)
+	if err != nil {
+		return nil, err
+	}
+
+	var commandTree interface{}
+	if err := yaml.Unmarshal(commandYAML, &commandTree); err != nil {
+		return nil, err
+	}
+
+	if command.Animation != nil {
+		for _, track := range command.Animation.Tracks {
+			value, valueErr := track.ValueAt(frame)
+			if valueErr != nil {
+				return nil, fmt.Errorf(`%s: %v`, track.Field, valueErr)
+			}
+			value = roundIntegerFields(value, fieldTypeAtPath(track.Field))
+			if setErr := setFieldInTree(commandTree, track.Field, value); setErr != nil {
+				return nil, setErr
+			}
+		}
+	}
+
+	frameYAML, err := yaml.Marshal(commandTree)
+	if err != nil {
+		return nil, err
+	}
+	frameCommand, err := NewCreateWallpaperCommandFromYAML(frameYAML)
+	if err != nil {
+		return nil, fmt.Errorf(`frame %d: %v`, frame, err)
+	}
+	return frameCommand, nil
+}
+
+// validateAnimation returns an error if the animation cannot be applied to the command.
+//   Every frame is checked before rendering starts, because values between keyframes
+//   can break rules the keyframes follow, like a width passing through zero.
+func (command *CreateSymmetryPattern) validateAnimation() error {
+	if animationErr := command.Animation.Validate(); animationErr != nil {
+		return animationErr
+	}
+
+	for frame := 0; frame < command.Animation.Frames; frame++ {
+		frameCommand, frameErr := command.AnimationFrame(frame)
+		if frameErr != nil {
+			return frameErr
+		}
+		if frameErr = frameCommand.Validate(); frameErr != nil {
+			return fmt.Errorf(`frame %d: %v`, frame, frameErr)
+		}
+	}
+	return nil
+}
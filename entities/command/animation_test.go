@@ -0,0 +1,245 @@
+package command_test
+
+import (
+	. "gopkg.in/check.v1"
+	"wallpaper/entities/command"
+	"wallpaper/entities/utility"
+)
+
+type AnimationSuite struct {
+	wallpaperCommand *command.CreateSymmetryPattern
+}
+
+var _ = Suite(&AnimationSuite{})
+
+func (suite *AnimationSuite) SetUpTest(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_filename: spin.png
+output_size:
+  width: 4
+  height: 3
+sample_space:
+  minx: -1
+  miny: -1
+  maxx: 1
+  maxy: 1
+coloring: domain
+rosette_formula:
+  terms:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      power_n: 1
+      power_m: 0
+    -
+      multiplier:
+        real: 0
+        imaginary: 0
+      power_n: 2
+      power_m: 0
+animation:
+  frames: 5
+  tracks:
+    - field: rosette_formula.terms[1].multiplier
+      keyframes:
+        - frame: 0
+          value: {real: 0, imaginary: 0}
+        - frame: 4
+          value: {real: 2, imaginary: -4}
+`))
+	checker.Assert(err, IsNil)
+	suite.wallpaperCommand = wallpaperCommand
+}
+
+func (suite *AnimationSuite) TestCommandReadsTheAnimation(checker *C) {
+	animation := suite.wallpaperCommand.Animation
+	checker.Assert(animation, NotNil)
+	checker.Assert(animation.Frames, Equals, 5)
+	checker.Assert(animation.FrameRate(), Equals, float64(command.DefaultFramesPerSecond))
+	checker.Assert(animation.Tracks, HasLen, 1)
+	checker.Assert(animation.Tracks[0].Keyframes, HasLen, 2)
+	checker.Assert(suite.wallpaperCommand.Validate(), IsNil)
+}
+
+func (suite *AnimationSuite) TestAnimationFrameInterpolatesTheField(checker *C) {
+	frameCommand, err := suite.wallpaperCommand.AnimationFrame(1)
+	checker.Assert(err, IsNil)
+	checker.Assert(frameCommand.Animation, IsNil)
+
+	multiplier := frameCommand.RosetteFormula.Terms[1].Multiplier
+	checker.Assert(real(multiplier), utility.NumericallyCloseEnough{}, 0.5, 1e-6)
+	checker.Assert(imag(multiplier), utility.NumericallyCloseEnough{}, -1, 1e-6)
+	checker.Assert(frameCommand.RosetteFormula.Terms[0].Multiplier, Equals, complex(1, 0))
+}
+
+func (suite *AnimationSuite) TestAnimationFrameDoesNotModifyTheCommand(checker *C) {
+	_, err := suite.wallpaperCommand.AnimationFrame(4)
+	checker.Assert(err, IsNil)
+	checker.Assert(suite.wallpaperCommand.RosetteFormula.Terms[1].Multiplier, Equals, complex(0, 0))
+	checker.Assert(suite.wallpaperCommand.Animation, NotNil)
+}
+
+func (suite *AnimationSuite) TestFramesOutsideTheKeyframesHoldTheNearestValue(checker *C) {
+	track := &command.AnimationTrack{
+		Field: "lattice_pattern.multiplier.real",
+		Keyframes: []command.Keyframe{
+			{Frame: 2, Value: 1.0},
+			{Frame: 4, Value: 3.0},
+		},
+	}
+	before, err := track.ValueAt(0)
+	checker.Assert(err, IsNil)
+	checker.Assert(before, Equals, 1.0)
+
+	after, err := track.ValueAt(10)
+	checker.Assert(err, IsNil)
+	checker.Assert(after, Equals, 3.0)
+}
+
+func (suite *AnimationSuite) TestEasingChangesTheValuesBetweenKeyframes(checker *C) {
+	valueAtQuarter := func(interpolation command.InterpolationMode) float64 {
+		track := &command.AnimationTrack{
+			Field:         "lattice_pattern.multiplier.real",
+			Interpolation: interpolation,
+			Keyframes: []command.Keyframe{
+				{Frame: 0, Value: 0.0},
+				{Frame: 4, Value: 1.0},
+			},
+		}
+		value, err := track.ValueAt(1)
+		checker.Assert(err, IsNil)
+		return value.(float64)
+	}
+
+	checker.Assert(valueAtQuarter(command.InterpolateLinearly), utility.NumericallyCloseEnough{}, 0.25, 1e-6)
+	checker.Assert(valueAtQuarter(command.EaseIn), utility.NumericallyCloseEnough{}, 0.015625, 1e-6)
+	checker.Assert(valueAtQuarter(command.EaseOut), utility.NumericallyCloseEnough{}, 0.578125, 1e-6)
+	checker.Assert(valueAtQuarter(command.EaseInOut), utility.NumericallyCloseEnough{}, 0.15625, 1e-6)
+}
+
+func (suite *AnimationSuite) TestIntegerFieldsAreRounded(checker *C) {
+	suite.wallpaperCommand.Animation.Tracks = []*command.AnimationTrack{
+		{
+			Field: "rosette_formula.terms[0].power_n",
+			Keyframes: []command.Keyframe{
+				{Frame: 0, Value: 1},
+				{Frame: 4, Value: 3},
+			},
+		},
+	}
+	checker.Assert(suite.wallpaperCommand.Validate(), IsNil)
+
+	frameCommand, err := suite.wallpaperCommand.AnimationFrame(3)
+	checker.Assert(err, IsNil)
+	checker.Assert(frameCommand.RosetteFormula.Terms[0].PowerN, Equals, 3)
+}
+
+func (suite *AnimationSuite) TestJSONKeyframesCanBeInterpolated(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromJSON([]byte(`{
+		"output_filename": "spin.gif",
+		"output_size": {"width": 4, "height": 3},
+		"sample_space": {"minx": -1, "miny": -1, "maxx": 1, "maxy": 1},
+		"coloring": "domain",
+		"rosette_formula": {"terms": [{"multiplier": {"real": 1, "imaginary": 0}, "power_n": 1, "power_m": 0}]},
+		"animation": {
+			"frames": 3,
+			"tracks": [{
+				"field": "rosette_formula.terms[0].multiplier",
+				"keyframes": [
+					{"frame": 0, "value": {"real": 1, "imaginary": 0}},
+					{"frame": 2, "value": {"real": 0, "imaginary": 1}}
+				]
+			}]
+		}
+	}`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.Validate(), IsNil)
+
+	frameCommand, err := wallpaperCommand.AnimationFrame(1)
+	checker.Assert(err, IsNil)
+	checker.Assert(frameCommand.RosetteFormula.Terms[0].Multiplier, Equals, complex(0.5, 0.5))
+}
+
+func (suite *AnimationSuite) TestUnknownFieldsAreAnError(checker *C) {
+	suite.wallpaperCommand.Animation.Tracks[0].Field = "rosette_formula.terms[1].multiplyer"
+	err := suite.wallpaperCommand.Validate()
+	checker.Assert(err, ErrorMatches, "animation: rosette_formula.terms\\[1\\].multiplyer has no field multiplyer")
+
+	suite.wallpaperCommand.Animation.Tracks[0].Field = "rosette_formula.terms[7].multiplier"
+	err = suite.wallpaperCommand.Validate()
+	checker.Assert(err, ErrorMatches, "animation: rosette_formula.terms\\[7\\].multiplier has no item 7")
+
+	suite.wallpaperCommand.Animation.Tracks[0].Field = "rosette_formula..terms"
+	err = suite.wallpaperCommand.Validate()
+	checker.Assert(err, ErrorMatches, "animation: tracks\\[0\\]: cannot read field rosette_formula..terms")
+}
+
+func (suite *AnimationSuite) TestKeyframesMustHaveTheSameShape(checker *C) {
+	suite.wallpaperCommand.Animation.Tracks[0].Keyframes[1].Value = 2.0
+	err := suite.wallpaperCommand.Validate()
+	checker.Assert(err, ErrorMatches, "animation: tracks\\[0\\]: keyframes at frames 0 and 4: cannot blend .*")
+}
+
+func (suite *AnimationSuite) TestKeyframesMustBeInOrder(checker *C) {
+	suite.wallpaperCommand.Animation.Tracks[0].Keyframes[1].Frame = 0
+	err := suite.wallpaperCommand.Validate()
+	checker.Assert(err, ErrorMatches, "animation: tracks\\[0\\]: keyframes must be in order of frame, without repeats: 0")
+}
+
+func (suite *AnimationSuite) TestAnimationOptionsMustBeValid(checker *C) {
+	suite.wallpaperCommand.Animation.Frames = 0
+	checker.Assert(suite.wallpaperCommand.Validate(), ErrorMatches, "animation: frames must be positive: 0")
+
+	suite.wallpaperCommand.Animation.Frames = 5
+	suite.wallpaperCommand.Animation.Tracks[0].Interpolation = "bouncy"
+	checker.Assert(suite.wallpaperCommand.Validate(), ErrorMatches, "animation: tracks\\[0\\]: unknown interpolation: bouncy")
+
+	suite.wallpaperCommand.Animation.Tracks = nil
+	checker.Assert(suite.wallpaperCommand.Validate(), ErrorMatches, "animation: animation needs at least one track")
+}
+
+func (suite *AnimationSuite) TestFramesAreValidated(checker *C) {
+	suite.wallpaperCommand.Animation.Tracks = []*command.AnimationTrack{
+		{
+			Field: "output_size.width",
+			Keyframes: []command.Keyframe{
+				{Frame: 0, Value: 4},
+				{Frame: 4, Value: 0},
+			},
+		},
+	}
+	err := suite.wallpaperCommand.Validate()
+	checker.Assert(err, ErrorMatches, "animation: frame 4: output_size must be positive: 0x3")
+}
+
+func (suite *AnimationSuite) TestFramesBetweenKeyframesAreValidated(checker *C) {
+	suite.wallpaperCommand.Animation.Tracks = []*command.AnimationTrack{
+		{
+			Field: "sample_space.maxx",
+			Keyframes: []command.Keyframe{
+				{Frame: 0, Value: 1},
+				{Frame: 4, Value: -3},
+			},
+		},
+	}
+	err := suite.wallpaperCommand.Validate()
+	checker.Assert(err, ErrorMatches, "animation: frame 2: sample_space must have a nonzero width and height")
+}
+
+func (suite *AnimationSuite) TestAnimationSurvivesTheRoundTrip(checker *C) {
+	commandYAML, err := suite.wallpaperCommand.ToYAML()
+	checker.Assert(err, IsNil)
+
+	readCommand, err := command.NewCreateWallpaperCommandFromYAML(commandYAML)
+	checker.Assert(err, IsNil)
+	checker.Assert(readCommand.Animation, DeepEquals, suite.wallpaperCommand.Animation)
+}
+
+func (suite *AnimationSuite) TestFrameFilenamesAreNumbered(checker *C) {
+	animation := &command.Animation{Frames: 12}
+	checker.Assert(animation.FrameFilename("out/spin.png", 3), Equals, "out/spin_0003.png")
+
+	animation.Frames = 20000
+	checker.Assert(animation.FrameFilename("spin.png", 3), Equals, "spin_00003.png")
+}
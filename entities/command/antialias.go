@@ -0,0 +1,50 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// AntialiasMode decides where the extra samples inside each output pixel are taken.
+type AntialiasMode string
+
+// Antialias modes.
+const (
+	// AntialiasGrid spreads the samples evenly over a square grid, like 2x2 or 4x4.
+	AntialiasGrid AntialiasMode = "grid"
+	// AntialiasJitter places the samples at random spots inside each pixel.
+	//   The spots are the same every time the command is rendered.
+	AntialiasJitter AntialiasMode = "jitter"
+)
+
+// AntialiasOptions samples each output pixel several times and averages the colors.
+type AntialiasOptions struct {
+	Mode AntialiasMode `json:"mode" yaml:"mode"`
+	// Samples is the number of samples per pixel. Grids need a square number, like 4 (2x2) or 16 (4x4).
+	Samples int `json:"samples" yaml:"samples"`
+}
+
+// GridSize returns the number of samples along each side of a grid.
+func (options *AntialiasOptions) GridSize() int {
+	return int(math.Round(math.Sqrt(float64(options.Samples))))
+}
+
+// Validate returns an error if the options cannot be used.
+func (options *AntialiasOptions) Validate() error {
+	if options.Samples < 1 {
+		return errors.New(`samples must be positive`)
+	}
+
+	switch options.Mode {
+	case AntialiasGrid:
+		gridSize := options.GridSize()
+		if gridSize*gridSize != options.Samples {
+			return fmt.Errorf(`grid samples must be a square number like 4 or 16: %d`, options.Samples)
+		}
+	case AntialiasJitter:
+	default:
+		return fmt.Errorf(`unknown antialias mode: %s`, options.Mode)
+	}
+	return nil
+}
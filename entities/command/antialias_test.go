@@ -0,0 +1,43 @@
+package command_test
+
+import (
+	. "gopkg.in/check.v1"
+	"wallpaper/entities/command"
+)
+
+type AntialiasOptionsSuite struct {
+}
+
+var _ = Suite(&AntialiasOptionsSuite{})
+
+func (suite *AntialiasOptionsSuite) TestGridNeedsASquareNumberOfSamples(checker *C) {
+	checker.Assert((&command.AntialiasOptions{Mode: command.AntialiasGrid, Samples: 16}).Validate(), IsNil)
+	checker.Assert((&command.AntialiasOptions{Mode: command.AntialiasGrid, Samples: 16}).GridSize(), Equals, 4)
+
+	err := (&command.AntialiasOptions{Mode: command.AntialiasGrid, Samples: 8}).Validate()
+	checker.Assert(err, ErrorMatches, "grid samples must be a square number like 4 or 16: 8")
+}
+
+func (suite *AntialiasOptionsSuite) TestJitterAllowsAnyNumberOfSamples(checker *C) {
+	checker.Assert((&command.AntialiasOptions{Mode: command.AntialiasJitter, Samples: 7}).Validate(), IsNil)
+}
+
+func (suite *AntialiasOptionsSuite) TestSamplesMustBePositive(checker *C) {
+	err := (&command.AntialiasOptions{Mode: command.AntialiasJitter, Samples: 0}).Validate()
+	checker.Assert(err, ErrorMatches, "samples must be positive")
+}
+
+func (suite *AntialiasOptionsSuite) TestUnknownModeIsAnError(checker *C) {
+	err := (&command.AntialiasOptions{Mode: "blurry", Samples: 4}).Validate()
+	checker.Assert(err, ErrorMatches, "unknown antialias mode: blurry")
+}
+
+func (suite *AntialiasOptionsSuite) TestCommandReadsAntialiasOptions(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+antialias:
+  mode: grid
+  samples: 4
+`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.Antialias, DeepEquals, &command.AntialiasOptions{Mode: command.AntialiasGrid, Samples: 4})
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AutomaticColorValueSpace is written as `color_value_space: auto`.
+const AutomaticColorValueSpace = "auto"
+
+// PercentileRange chooses a range of values by percentile. 0 is the smallest value and 100 is the largest.
+type PercentileRange struct {
+	Low  float64 `json:"low" yaml:"low"`
+	High float64 `json:"high" yaml:"high"`
+}
+
+// DefaultColorValuePercentiles ignores the most extreme values on both ends.
+var DefaultColorValuePercentiles = PercentileRange{Low: 2, High: 98}
+
+// Validate returns an error if the range cannot be used.
+func (percentiles *PercentileRange) Validate() error {
+	if percentiles.Low < 0 || percentiles.High > 100 || percentiles.Low >= percentiles.High {
+		return fmt.Errorf(`percentiles must be from 0 to 100, with low less than high: %g - %g`, percentiles.Low, percentiles.High)
+	}
+	return nil
+}
+
+// ColorValueSpaceMarshal is either the corners of the color value space, or the word auto.
+type ColorValueSpaceMarshal struct {
+	Automatic bool
+	Corners   ComplexNumberCorners
+}
+
+// UnmarshalYAML reads the word auto, or the corners.
+func (colorValueSpace *ColorValueSpaceMarshal) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var word string
+	if unmarshal(&word) == nil {
+		return colorValueSpace.setAutomatic(word)
+	}
+	colorValueSpace.Automatic = false
+	return unmarshal(&colorValueSpace.Corners)
+}
+
+// UnmarshalJSON reads the word auto, or the corners.
+func (colorValueSpace *ColorValueSpaceMarshal) UnmarshalJSON(data []byte) error {
+	var word string
+	if json.Unmarshal(data, &word) == nil {
+		return colorValueSpace.setAutomatic(word)
+	}
+	colorValueSpace.Automatic = false
+	return json.Unmarshal(data, &colorValueSpace.Corners)
+}
+
+// MarshalYAML writes the word auto, or the corners.
+func (colorValueSpace ColorValueSpaceMarshal) MarshalYAML() (interface{}, error) {
+	if colorValueSpace.Automatic {
+		return AutomaticColorValueSpace, nil
+	}
+	return colorValueSpace.Corners, nil
+}
+
+// MarshalJSON writes the word auto, or the corners.
+func (colorValueSpace ColorValueSpaceMarshal) MarshalJSON() ([]byte, error) {
+	if colorValueSpace.Automatic {
+		return json.Marshal(AutomaticColorValueSpace)
+	}
+	return json.Marshal(colorValueSpace.Corners)
+}
+
+func (colorValueSpace *ColorValueSpaceMarshal) setAutomatic(word string) error {
+	if word != AutomaticColorValueSpace {
+		return fmt.Errorf(`color_value_space must be %s or have corners: %s`, AutomaticColorValueSpace, word)
+	}
+	colorValueSpace.Automatic = true
+	return nil
+}
+
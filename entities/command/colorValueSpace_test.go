@@ -0,0 +1,67 @@
+package command_test
+
+import (
+	"encoding/json"
+	. "gopkg.in/check.v1"
+	"gopkg.in/yaml.v2"
+	"wallpaper/entities/command"
+)
+
+type ColorValueSpaceSuite struct {
+}
+
+var _ = Suite(&ColorValueSpaceSuite{})
+
+func (suite *ColorValueSpaceSuite) TestAutoUsesDefaultPercentiles(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`color_value_space: auto`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.AutomaticColorValueSpace, DeepEquals, &command.PercentileRange{Low: 2, High: 98})
+}
+
+func (suite *ColorValueSpaceSuite) TestAutoWithPercentiles(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromJSON([]byte(`{
+		"color_value_space": "auto",
+		"color_value_percentiles": {"low": 5, "high": 90}
+	}`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.AutomaticColorValueSpace, DeepEquals, &command.PercentileRange{Low: 5, High: 90})
+}
+
+func (suite *ColorValueSpaceSuite) TestCornersAreNotAutomatic(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromJSON([]byte(`{
+		"color_value_space": {"minx": -1, "miny": -2, "maxx": 3, "maxy": 4}
+	}`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.AutomaticColorValueSpace, IsNil)
+	checker.Assert(wallpaperCommand.ColorValueSpace, Equals, command.ComplexNumberCorners{MinX: -1, MinY: -2, MaxX: 3, MaxY: 4})
+}
+
+func (suite *ColorValueSpaceSuite) TestOtherWordsAreErrors(checker *C) {
+	_, err := command.NewCreateWallpaperCommandFromYAML([]byte(`color_value_space: automatic`))
+	checker.Assert(err, ErrorMatches, "color_value_space must be auto or have corners: automatic")
+}
+
+func (suite *ColorValueSpaceSuite) TestPercentilesNeedAutomaticSpace(checker *C) {
+	_, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+color_value_percentiles:
+  low: 1
+  high: 99
+`))
+	checker.Assert(err, ErrorMatches, "color_value_percentiles needs color_value_space: auto")
+}
+
+func (suite *ColorValueSpaceSuite) TestPercentilesMustBeOrdered(checker *C) {
+	checker.Assert((&command.PercentileRange{Low: 0, High: 100}).Validate(), IsNil)
+	checker.Assert((&command.PercentileRange{Low: 50, High: 50}).Validate(), ErrorMatches, "percentiles must be from 0 to 100.*")
+	checker.Assert((&command.PercentileRange{Low: -1, High: 50}).Validate(), ErrorMatches, "percentiles must be from 0 to 100.*")
+}
+
+func (suite *ColorValueSpaceSuite) TestMarshalWritesAuto(checker *C) {
+	yamlBytes, err := yaml.Marshal(command.ColorValueSpaceMarshal{Automatic: true})
+	checker.Assert(err, IsNil)
+	checker.Assert(string(yamlBytes), Equals, "auto\n")
+
+	jsonBytes, err := json.Marshal(command.ColorValueSpaceMarshal{Corners: command.ComplexNumberCorners{MaxX: 1}})
+	checker.Assert(err, IsNil)
+	checker.Assert(string(jsonBytes), Equals, `{"minx":0,"miny":0,"maxx":1,"maxy":0}`)
+}
@@ -0,0 +1,26 @@
+package command
+
+// ColoringMode decides how transformed values are turned into colors.
+type ColoringMode string
+
+// Coloring modes.
+const (
+	// ColorBySourceImage finds each transformed value in the color value space, and uses that spot of the source image.
+	ColorBySourceImage ColoringMode = "source_image"
+	// ColorByDomain colors each transformed value with a color wheel. It does not need a source image.
+	ColorByDomain ColoringMode = "domain"
+	// ColorByPalette colors each transformed value with a palette of color stops. It does not need a source image.
+	ColorByPalette ColoringMode = "palette"
+)
+
+var knownColoringModes = map[ColoringMode]bool{
+	ColorBySourceImage: true,
+	ColorByDomain:      true,
+	ColorByPalette:     true,
+}
+
+// UsesSourceImage returns true if the command colors the pattern using a source image.
+//   Commands without a coloring mode use the source image.
+func (command *CreateSymmetryPattern) UsesSourceImage() bool {
+	return command.Coloring == "" || command.Coloring == ColorBySourceImage
+}
@@ -2,7 +2,13 @@ package command
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"gopkg.in/yaml.v2"
+	"path/filepath"
+	"strings"
+	"wallpaper/entities/colorsource"
+	"wallpaper/entities/encoder"
 	"wallpaper/entities/formula/frieze"
 	"wallpaper/entities/formula/rosette"
 	"wallpaper/entities/formula/wallpaper"
@@ -26,10 +32,40 @@ type WidthHeightDimensions struct {
 // CreateSymmetryPattern records the desired command to generate.
 type CreateSymmetryPattern struct {
 	SampleSpace				  ComplexNumberCorners               `json:"sample_space" yaml:"sample_space"`
+	// Viewport is used instead of SampleSpace when it is set.
+	Viewport *Viewport `json:"viewport" yaml:"viewport"`
 	OutputImageSize			  WidthHeightDimensions              `json:"output_size" yaml:"output_size"`
+	// OutputMode decides which part of the pattern is rendered. Empty renders the sample space.
+	OutputMode OutputMode `json:"output_mode" yaml:"output_mode"`
+	// FriezePeriod changes the strip rendered by the frieze_period output mode.
+	FriezePeriod *FriezePeriod `json:"frieze_period" yaml:"frieze_period"`
+	// OutputBitDepth is the number of bits used for each color channel of the output image, 8 or 16.
+	//   0 uses 8.
+	OutputBitDepth int `json:"output_bit_depth" yaml:"output_bit_depth"`
 	SampleSourceFilename	  string                                `json:"sample_source_filename" yaml:"sample_source_filename"`
+	// SampleSourceGenerator draws the source image. It is used instead of SampleSourceFilename when it is set.
+	SampleSourceGenerator *colorsource.Generator `json:"sample_source_generator" yaml:"sample_source_generator"`
 	OutputFilename			  string                              `json:"output_filename" yaml:"output_filename"`
+	// OutputEncoding changes how the output image is encoded. The format is chosen by OutputFilename's extension.
+	OutputEncoding *encoder.Options `json:"output_encoding" yaml:"output_encoding"`
 	ColorValueSpace			  ComplexNumberCorners               `json:"color_value_space" yaml:"color_value_space"`
+	// AutomaticColorValueSpace is set when the color value space should be chosen from the transformed values.
+	//   ColorValueSpace is ignored when this is set.
+	AutomaticColorValueSpace *PercentileRange `json:"color_value_percentiles" yaml:"color_value_percentiles"`
+	Coloring ColoringMode `json:"coloring" yaml:"coloring"`
+	DomainColoring *colorsource.DomainColoring `json:"domain_coloring" yaml:"domain_coloring"`
+	Palette *colorsource.Palette `json:"palette" yaml:"palette"`
+	SourceSampling colorsource.SamplingMode `json:"source_sampling" yaml:"source_sampling"`
+	OutOfRange *OutOfRangeOptions `json:"out_of_range" yaml:"out_of_range"`
+	Antialias *AntialiasOptions `json:"antialias" yaml:"antialias"`
+	// Animation renders several frames instead of one image.
+	Animation *Animation `json:"animation" yaml:"animation"`
+	// Morph blends the formula into a second formula.
+	Morph *Morph `json:"morph" yaml:"morph"`
+	// SymmetryOverlay draws the pattern's symmetry elements on the image, or writes them to an SVG file.
+	SymmetryOverlay *SymmetryOverlay `json:"symmetry_overlay" yaml:"symmetry_overlay"`
+	// LatticeOverlay outlines the lattice cells and shades a fundamental domain, on the image or in an SVG file.
+	LatticeOverlay *LatticeOverlay `json:"lattice_overlay" yaml:"lattice_overlay"`
 
 	RosetteFormula			  *rosette.Formula                    `json:"rosette_formula" yaml:"rosette_formula"`
 	FriezeFormula			  *frieze.Formula                      `json:"frieze_formula" yaml:"frieze_formula"`
@@ -39,14 +75,31 @@ type CreateSymmetryPattern struct {
 // CreateWallpaperCommandMarshal can be marshaled and converted to a CreateSymmetryPattern
 type CreateWallpaperCommandMarshal struct {
 	SampleSpace				ComplexNumberCorners                  `json:"sample_space" yaml:"sample_space"`
+	Viewport *Viewport `json:"viewport,omitempty" yaml:"viewport,omitempty"`
 	OutputImageSize			WidthHeightDimensions                 `json:"output_size" yaml:"output_size"`
-	SampleSourceFilename	string                                   `json:"sample_source_filename" yaml:"sample_source_filename"`
+	OutputMode OutputMode `json:"output_mode,omitempty" yaml:"output_mode,omitempty"`
+	FriezePeriod *FriezePeriod `json:"frieze_period,omitempty" yaml:"frieze_period,omitempty"`
+	OutputBitDepth int `json:"output_bit_depth,omitempty" yaml:"output_bit_depth,omitempty"`
+	SampleSourceFilename	string                                   `json:"sample_source_filename,omitempty" yaml:"sample_source_filename,omitempty"`
+	SampleSource *SampleSourceMarshal `json:"sample_source,omitempty" yaml:"sample_source,omitempty"`
 	OutputFilename			string                                 `json:"output_filename" yaml:"output_filename"`
-	ColorValueSpace			ComplexNumberCorners                  `json:"color_value_space" yaml:"color_value_space"`
+	OutputEncoding *encoder.Options `json:"output_encoding,omitempty" yaml:"output_encoding,omitempty"`
+	ColorValueSpace			ColorValueSpaceMarshal                `json:"color_value_space" yaml:"color_value_space"`
+	ColorValuePercentiles *PercentileRange `json:"color_value_percentiles,omitempty" yaml:"color_value_percentiles,omitempty"`
+	Coloring ColoringMode `json:"coloring,omitempty" yaml:"coloring,omitempty"`
+	DomainColoring *colorsource.DomainColoring `json:"domain_coloring,omitempty" yaml:"domain_coloring,omitempty"`
+	Palette *colorsource.Palette `json:"palette,omitempty" yaml:"palette,omitempty"`
+	SourceSampling colorsource.SamplingMode `json:"source_sampling,omitempty" yaml:"source_sampling,omitempty"`
+	OutOfRange *OutOfRangeOptions `json:"out_of_range,omitempty" yaml:"out_of_range,omitempty"`
+	Antialias *AntialiasOptions `json:"antialias,omitempty" yaml:"antialias,omitempty"`
+	Animation *Animation `json:"animation,omitempty" yaml:"animation,omitempty"`
+	Morph *MorphMarshal `json:"morph,omitempty" yaml:"morph,omitempty"`
+	SymmetryOverlay *SymmetryOverlay `json:"symmetry_overlay,omitempty" yaml:"symmetry_overlay,omitempty"`
+	LatticeOverlay *LatticeOverlay `json:"lattice_overlay,omitempty" yaml:"lattice_overlay,omitempty"`
 
-	RosetteFormula			*rosette.MarshaledFormula              `json:"rosette_formula" yaml:"rosette_formula"`
-	FriezeFormula			*frieze.MarshaledFormula                `json:"frieze_formula" yaml:"frieze_formula"`
-	LatticePattern *wallpaper.FormulaMarshal `json:"lattice_pattern" yaml:"lattice_pattern"`
+	RosetteFormula			*rosette.MarshaledFormula              `json:"rosette_formula,omitempty" yaml:"rosette_formula,omitempty"`
+	FriezeFormula			*frieze.MarshaledFormula                `json:"frieze_formula,omitempty" yaml:"frieze_formula,omitempty"`
+	LatticePattern *wallpaper.FormulaMarshal `json:"lattice_pattern,omitempty" yaml:"lattice_pattern,omitempty"`
 }
 
 // NewCreateWallpaperCommandFromYAML reads the data and returns a CreateSymmetryPattern from it.
@@ -59,6 +112,18 @@ func NewCreateWallpaperCommandFromJSON(data []byte) (*CreateSymmetryPattern, err
 	return newCreateWallpaperCommandFromDatastream(data, json.Unmarshal)
 }
 
+// NewCreateWallpaperCommandFromFileContents picks YAML or JSON based on the filename's extension,
+//   then reads the contents and returns a CreateSymmetryPattern from it.
+func NewCreateWallpaperCommandFromFileContents(filename string, contents []byte) (*CreateSymmetryPattern, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yml", ".yaml":
+		return NewCreateWallpaperCommandFromYAML(contents)
+	case ".json":
+		return NewCreateWallpaperCommandFromJSON(contents)
+	}
+	return nil, fmt.Errorf(`cannot tell the format of %s, use a .yml, .yaml or .json extension`, filename)
+}
+
 // newCreateWallpaperCommandFromDatastream consumes a given bytestream and tries to create a new object from it.
 func newCreateWallpaperCommandFromDatastream(data []byte, unmarshal utility.UnmarshalFunc) (*CreateSymmetryPattern, error) {
 	var unmarshalError error
@@ -71,10 +136,42 @@ func newCreateWallpaperCommandFromDatastream(data []byte, unmarshal utility.Unma
 
 	commandToCreate := &CreateSymmetryPattern{
 		SampleSpace:          commandToCreateMarshal.SampleSpace,
+		Viewport:             commandToCreateMarshal.Viewport,
 		OutputImageSize:      commandToCreateMarshal.OutputImageSize,
+		OutputMode:           commandToCreateMarshal.OutputMode,
+		FriezePeriod:         commandToCreateMarshal.FriezePeriod,
+		OutputBitDepth:       commandToCreateMarshal.OutputBitDepth,
 		SampleSourceFilename: commandToCreateMarshal.SampleSourceFilename,
 		OutputFilename:       commandToCreateMarshal.OutputFilename,
-		ColorValueSpace:      commandToCreateMarshal.ColorValueSpace,
+		OutputEncoding:       commandToCreateMarshal.OutputEncoding,
+		ColorValueSpace:      commandToCreateMarshal.ColorValueSpace.Corners,
+		Antialias:            commandToCreateMarshal.Antialias,
+		Coloring:             commandToCreateMarshal.Coloring,
+		DomainColoring:       commandToCreateMarshal.DomainColoring,
+		Palette:              commandToCreateMarshal.Palette,
+		SourceSampling:       commandToCreateMarshal.SourceSampling,
+		OutOfRange:           commandToCreateMarshal.OutOfRange,
+		Animation:            commandToCreateMarshal.Animation,
+		SymmetryOverlay:      commandToCreateMarshal.SymmetryOverlay,
+		LatticeOverlay:       commandToCreateMarshal.LatticeOverlay,
+	}
+
+	if commandToCreateMarshal.SampleSource != nil {
+		if commandToCreateMarshal.SampleSourceFilename != "" {
+			return nil, errors.New(`use sample_source or sample_source_filename, not both`)
+		}
+		commandToCreate.SampleSourceFilename = commandToCreateMarshal.SampleSource.Filename
+		commandToCreate.SampleSourceGenerator = commandToCreateMarshal.SampleSource.Generator
+	}
+
+	if commandToCreateMarshal.ColorValueSpace.Automatic {
+		percentiles := DefaultColorValuePercentiles
+		if commandToCreateMarshal.ColorValuePercentiles != nil {
+			percentiles = *commandToCreateMarshal.ColorValuePercentiles
+		}
+		commandToCreate.AutomaticColorValueSpace = &percentiles
+	} else if commandToCreateMarshal.ColorValuePercentiles != nil {
+		return nil, errors.New(`color_value_percentiles needs color_value_space: auto`)
 	}
 
 	if commandToCreateMarshal.RosetteFormula != nil {
@@ -89,5 +186,206 @@ func newCreateWallpaperCommandFromDatastream(data []byte, unmarshal utility.Unma
 		commandToCreate.LatticePattern = wallpaper.NewFormulaFromMarshalObject(*commandToCreateMarshal.LatticePattern)
 	}
 
+	if commandToCreateMarshal.Morph != nil {
+		commandToCreate.Morph = NewMorphFromMarshalObject(*commandToCreateMarshal.Morph)
+	}
+
 	return commandToCreate, nil
-}
\ No newline at end of file
+}
+
+// MarshalObject converts the command into an object that can be marshaled.
+//   Marshaling it and reading it back creates the same command.
+func (command *CreateSymmetryPattern) MarshalObject() *CreateWallpaperCommandMarshal {
+	commandMarshal := &CreateWallpaperCommandMarshal{
+		SampleSpace:          command.SampleSpace,
+		Viewport:             command.Viewport,
+		OutputImageSize:      command.OutputImageSize,
+		OutputMode:           command.OutputMode,
+		FriezePeriod:         command.FriezePeriod,
+		OutputBitDepth:       command.OutputBitDepth,
+		SampleSourceFilename: command.SampleSourceFilename,
+		OutputFilename:       command.OutputFilename,
+		OutputEncoding:       command.OutputEncoding,
+		ColorValueSpace:      ColorValueSpaceMarshal{Corners: command.ColorValueSpace},
+		Coloring:             command.Coloring,
+		DomainColoring:       command.DomainColoring,
+		Palette:              command.Palette,
+		SourceSampling:       command.SourceSampling,
+		OutOfRange:           command.OutOfRange,
+		Antialias:            command.Antialias,
+		Animation:            command.Animation,
+		SymmetryOverlay:      command.SymmetryOverlay,
+		LatticeOverlay:       command.LatticeOverlay,
+	}
+
+	if command.SampleSourceGenerator != nil {
+		commandMarshal.SampleSourceFilename = ""
+		commandMarshal.SampleSource = &SampleSourceMarshal{Generator: command.SampleSourceGenerator}
+	}
+
+	if command.AutomaticColorValueSpace != nil {
+		percentiles := *command.AutomaticColorValueSpace
+		commandMarshal.ColorValueSpace = ColorValueSpaceMarshal{Automatic: true}
+		commandMarshal.ColorValuePercentiles = &percentiles
+	}
+
+	if command.RosetteFormula != nil {
+		commandMarshal.RosetteFormula = command.RosetteFormula.MarshalObject()
+	}
+
+	if command.FriezeFormula != nil {
+		commandMarshal.FriezeFormula = command.FriezeFormula.MarshalObject()
+	}
+
+	if command.LatticePattern != nil {
+		commandMarshal.LatticePattern = command.LatticePattern.MarshalObject()
+	}
+
+	if command.Morph != nil {
+		commandMarshal.Morph = command.Morph.MarshalObject()
+	}
+
+	return commandMarshal
+}
+
+// ToYAML writes the command as YAML. NewCreateWallpaperCommandFromYAML can read it back.
+func (command *CreateSymmetryPattern) ToYAML() ([]byte, error) {
+	return yaml.Marshal(command.MarshalObject())
+}
+
+// Validate returns an error if the command cannot be used to render a pattern.
+func (command *CreateSymmetryPattern) Validate() error {
+	if command.Coloring != "" && !knownColoringModes[command.Coloring] {
+		return fmt.Errorf(`unknown coloring: %s`, command.Coloring)
+	}
+	if command.UsesSourceImage() && command.SampleSourceFilename == "" && command.SampleSourceGenerator == nil {
+		return errors.New(`sample_source or sample_source_filename is required`)
+	}
+	if command.SampleSourceGenerator != nil {
+		if generatorErr := command.SampleSourceGenerator.Validate(); generatorErr != nil {
+			return fmt.Errorf(`sample_source: %v`, generatorErr)
+		}
+	}
+	if command.OutputFilename == "" {
+		return errors.New(`output_filename is required`)
+	}
+	if _, formatErr := encoder.FormatForFilename(command.OutputFilename); formatErr != nil {
+		return fmt.Errorf(`output_filename: %v`, formatErr)
+	}
+	if command.OutputEncoding != nil {
+		if encodingErr := command.OutputEncoding.Validate(); encodingErr != nil {
+			return fmt.Errorf(`output_encoding: %v`, encodingErr)
+		}
+	}
+	if command.OutputImageSize.Width <= 0 || command.OutputImageSize.Height <= 0 {
+		return fmt.Errorf(
+			`output_size must be positive: %dx%d`,
+			command.OutputImageSize.Width,
+			command.OutputImageSize.Height,
+		)
+	}
+	if command.OutputBitDepth != 0 && command.OutputBitDepth != 8 && command.OutputBitDepth != 16 {
+		return fmt.Errorf(`output_bit_depth must be 8 or 16: %d`, command.OutputBitDepth)
+	}
+	if command.Viewport != nil {
+		if viewportErr := command.Viewport.Validate(); viewportErr != nil {
+			return fmt.Errorf(`viewport: %v`, viewportErr)
+		}
+	} else if command.RendersSampleSpace() && (command.SampleSpace.MinX == command.SampleSpace.MaxX || command.SampleSpace.MinY == command.SampleSpace.MaxY) {
+		return errors.New(`sample_space must have a nonzero width and height`)
+	}
+	if command.UsesSourceImage() {
+		if colorValueSpaceErr := command.validateColorValueSpace(); colorValueSpaceErr != nil {
+			return colorValueSpaceErr
+		}
+	}
+	if command.DomainColoring != nil {
+		if domainColoringErr := command.DomainColoring.Validate(); domainColoringErr != nil {
+			return fmt.Errorf(`domain_coloring: %v`, domainColoringErr)
+		}
+	}
+	if command.Coloring == ColorByPalette && command.Palette == nil {
+		return errors.New(`palette coloring needs a palette`)
+	}
+	if command.Palette != nil {
+		if paletteErr := command.Palette.Validate(); paletteErr != nil {
+			return fmt.Errorf(`palette: %v`, paletteErr)
+		}
+	}
+	if command.SourceSampling != "" && !command.SourceSampling.IsKnown() {
+		return fmt.Errorf(`unknown source_sampling: %s`, command.SourceSampling)
+	}
+	if command.OutOfRange != nil {
+		if outOfRangeErr := command.OutOfRange.Validate(); outOfRangeErr != nil {
+			return fmt.Errorf(`out_of_range: %v`, outOfRangeErr)
+		}
+	}
+	if command.Antialias != nil {
+		if antialiasErr := command.Antialias.Validate(); antialiasErr != nil {
+			return fmt.Errorf(`antialias: %v`, antialiasErr)
+		}
+	}
+
+	if command.RosetteFormula == nil && command.FriezeFormula == nil && command.LatticePattern == nil {
+		return errors.New(`no formula found`)
+	}
+	if command.RosetteFormula != nil {
+		if rosetteErr := command.RosetteFormula.Validate(); rosetteErr != nil {
+			return fmt.Errorf(`rosette_formula: %v`, rosetteErr)
+		}
+	}
+	if command.FriezeFormula != nil {
+		if friezeErr := command.FriezeFormula.Validate(); friezeErr != nil {
+			return fmt.Errorf(`frieze_formula: %v`, friezeErr)
+		}
+	}
+	if command.LatticePattern != nil {
+		if latticeErr := command.LatticePattern.Validate(); latticeErr != nil {
+			return fmt.Errorf(`lattice_pattern: %v`, latticeErr)
+		}
+	}
+	if outputModeErr := command.validateOutputMode(); outputModeErr != nil {
+		return outputModeErr
+	}
+	if command.Morph != nil {
+		if morphErr := command.Morph.validateFor(command); morphErr != nil {
+			return fmt.Errorf(`morph: %v`, morphErr)
+		}
+	}
+	if command.Animation != nil {
+		if animationErr := command.validateAnimation(); animationErr != nil {
+			return fmt.Errorf(`animation: %v`, animationErr)
+		}
+	}
+	if command.SymmetryOverlay != nil {
+		if overlayErr := command.validateSymmetryOverlay(); overlayErr != nil {
+			return fmt.Errorf(`symmetry_overlay: %v`, overlayErr)
+		}
+	}
+	if command.LatticeOverlay != nil {
+		if overlayErr := command.validateLatticeOverlay(); overlayErr != nil {
+			return fmt.Errorf(`lattice_overlay: %v`, overlayErr)
+		}
+	}
+	return nil
+}
+
+// formulaKey returns the key of the formula that is rendered, when the command has more than one.
+func (command *CreateSymmetryPattern) formulaKey() string {
+	return formulaKeyFor(command.FriezeFormula, command.RosetteFormula, command.LatticePattern)
+}
+
+// validateColorValueSpace returns an error if the color value space cannot be used to find colors in the source image.
+func (command *CreateSymmetryPattern) validateColorValueSpace() error {
+	if command.AutomaticColorValueSpace != nil {
+		if percentileErr := command.AutomaticColorValueSpace.Validate(); percentileErr != nil {
+			return fmt.Errorf(`color_value_percentiles: %v`, percentileErr)
+		}
+		return nil
+	}
+
+	if command.ColorValueSpace.MinX >= command.ColorValueSpace.MaxX || command.ColorValueSpace.MinY >= command.ColorValueSpace.MaxY {
+		return errors.New(`color_value_space minimums must be less than its maximums`)
+	}
+	return nil
+}
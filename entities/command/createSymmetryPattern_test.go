@@ -3,7 +3,10 @@ package command_test
 import (
 	. "gopkg.in/check.v1"
 	"testing"
+	"wallpaper/entities/colorsource"
 	"wallpaper/entities/command"
+	"wallpaper/entities/encoder"
+	"wallpaper/entities/formula/coefficient"
 	"wallpaper/entities/formula/wallpaper"
 )
 
@@ -198,4 +201,435 @@ lattice_pattern:
 
 	checker.Assert(wallpaperCommand.LatticePattern.WavePackets, HasLen, 1)
 
-}
\ No newline at end of file
+}
+type CreateWallpaperCommandFromFileSuite struct {
+	yamlByteStream []byte
+}
+
+var _ = Suite(&CreateWallpaperCommandFromFileSuite{})
+
+func (suite *CreateWallpaperCommandFromFileSuite) SetUpTest(checker *C) {
+	suite.yamlByteStream = []byte(`sample_source_filename: input.png
+output_filename: output.png
+output_size:
+  width: 800
+  height: 600
+sample_space:
+  minx: -1
+  miny: -1
+  maxx: 1
+  maxy: 1
+color_value_space:
+  minx: -50
+  miny: -10
+  maxx: 50
+  maxy: 10
+rosette_formula:
+  terms:
+    -
+      multiplier:
+        real: 1.0
+        imaginary: 0
+      power_n: 3
+      power_m: 0
+      coefficient_relationships:
+        - -M-N
+`)
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestYAMLExtensionsUseYAML(checker *C) {
+	for _, filename := range []string{"formula.yml", "formula.yaml", "FORMULA.YML"} {
+		wallpaperCommand, err := command.NewCreateWallpaperCommandFromFileContents(filename, suite.yamlByteStream)
+		checker.Assert(err, IsNil)
+		checker.Assert(wallpaperCommand.RosetteFormula.Terms, HasLen, 1)
+	}
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestJSONExtensionUsesJSON(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromFileContents("formula.json", []byte(`{
+		"sample_source_filename": "input.png",
+		"output_filename": "output.png"
+	}`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.OutputFilename, Equals, "output.png")
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestUnknownExtensionIsAnError(checker *C) {
+	_, err := command.NewCreateWallpaperCommandFromFileContents("formula.txt", suite.yamlByteStream)
+	checker.Assert(err, ErrorMatches, "cannot tell the format of formula.txt.*")
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestValidCommandHasNoErrors(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML(suite.yamlByteStream)
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.Validate(), IsNil)
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestValidateRequiresAPositiveOutputSize(checker *C) {
+	wallpaperCommand, _ := command.NewCreateWallpaperCommandFromYAML(suite.yamlByteStream)
+	wallpaperCommand.OutputImageSize.Height = 0
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "output_size must be positive: 800x0")
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestValidateRequiresAFormula(checker *C) {
+	wallpaperCommand, _ := command.NewCreateWallpaperCommandFromYAML(suite.yamlByteStream)
+	wallpaperCommand.RosetteFormula = nil
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "no formula found")
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestValidateRequiresAnOrderedColorValueSpace(checker *C) {
+	wallpaperCommand, _ := command.NewCreateWallpaperCommandFromYAML(suite.yamlByteStream)
+	wallpaperCommand.ColorValueSpace.MinX = 100
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "color_value_space minimums must be less than its maximums")
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestValidateChecksTheFormula(checker *C) {
+	wallpaperCommand, _ := command.NewCreateWallpaperCommandFromYAML(suite.yamlByteStream)
+	wallpaperCommand.RosetteFormula.Terms[0].CoefficientRelationships = []coefficient.Relationship{"-M-NF"}
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "rosette_formula: unknown coefficient relationship: -M-NF")
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestValidateChecksTheSourceSampling(checker *C) {
+	wallpaperCommand, _ := command.NewCreateWallpaperCommandFromYAML(append(suite.yamlByteStream, []byte("source_sampling: bicubic\n")...))
+	checker.Assert(wallpaperCommand.SourceSampling, Equals, colorsource.Bicubic)
+	checker.Assert(wallpaperCommand.Validate(), IsNil)
+
+	wallpaperCommand.SourceSampling = "blurry"
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "unknown source_sampling: blurry")
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestDomainColoringNeedsNoSourceImage(checker *C) {
+	wallpaperCommand, _ := command.NewCreateWallpaperCommandFromYAML(suite.yamlByteStream)
+	wallpaperCommand.SampleSourceFilename = ""
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "sample_source or sample_source_filename is required")
+
+	wallpaperCommand.Coloring = command.ColorByDomain
+	wallpaperCommand.ColorValueSpace = command.ComplexNumberCorners{}
+	checker.Assert(wallpaperCommand.UsesSourceImage(), Equals, false)
+	checker.Assert(wallpaperCommand.Validate(), IsNil)
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestValidateChecksTheColoring(checker *C) {
+	wallpaperCommand, _ := command.NewCreateWallpaperCommandFromYAML(suite.yamlByteStream)
+	wallpaperCommand.Coloring = "sepia"
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "unknown coloring: sepia")
+
+	wallpaperCommand.Coloring = command.ColorByDomain
+	wallpaperCommand.DomainColoring = &colorsource.DomainColoring{PhaseContours: -2}
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "domain_coloring: phase_contours cannot be negative")
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestPaletteColoring(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML(append(suite.yamlByteStream, []byte(`coloring: palette
+palette:
+  value: argument
+  cyclic: true
+  stops:
+    - position: 0
+      color: "#102030"
+    - position: 0.5
+      color: "#f0e0d0"
+`)...))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.Coloring, Equals, command.ColorByPalette)
+	checker.Assert(wallpaperCommand.Palette.Value, Equals, colorsource.Argument)
+	checker.Assert(wallpaperCommand.Palette.Cyclic, Equals, true)
+	checker.Assert(wallpaperCommand.Palette.Stops, HasLen, 2)
+	checker.Assert(wallpaperCommand.Palette.Stops[1].Color, Equals, "#f0e0d0")
+	checker.Assert(wallpaperCommand.UsesSourceImage(), Equals, false)
+	checker.Assert(wallpaperCommand.Validate(), IsNil)
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestValidateChecksThePalette(checker *C) {
+	wallpaperCommand, _ := command.NewCreateWallpaperCommandFromYAML(suite.yamlByteStream)
+	wallpaperCommand.Coloring = command.ColorByPalette
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "palette coloring needs a palette")
+
+	wallpaperCommand.Palette = &colorsource.Palette{Value: colorsource.Modulus}
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "palette: palette needs at least one stop")
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestOutputBitDepth(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML(append(suite.yamlByteStream, []byte("output_bit_depth: 16\n")...))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.OutputBitDepth, Equals, 16)
+	checker.Assert(wallpaperCommand.Validate(), IsNil)
+
+	wallpaperCommand.OutputBitDepth = 12
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "output_bit_depth must be 8 or 16: 12")
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestOutputEncoding(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML(append(suite.yamlByteStream, []byte(`output_encoding:
+  jpeg_quality: 75
+  gif_colors: 64
+  gif_dither: true
+`)...))
+	checker.Assert(err, IsNil)
+	checker.Assert(*wallpaperCommand.OutputEncoding, Equals, encoder.Options{JPEGQuality: 75, GIFColors: 64, GIFDither: true})
+	checker.Assert(wallpaperCommand.Validate(), IsNil)
+
+	wallpaperCommand.OutputEncoding.JPEGQuality = 0
+	wallpaperCommand.OutputEncoding.GIFColors = 300
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "output_encoding: gif_colors must be from 2 to 256: 300")
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestValidateChecksTheOutputFormat(checker *C) {
+	wallpaperCommand, _ := command.NewCreateWallpaperCommandFromYAML(suite.yamlByteStream)
+	wallpaperCommand.OutputFilename = "pattern.JPEG"
+	checker.Assert(wallpaperCommand.Validate(), IsNil)
+
+	wallpaperCommand.OutputFilename = "pattern.webp"
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "output_filename: cannot tell the image format of pattern.webp, .*")
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestValidateChecksTheOutputMode(checker *C) {
+	wallpaperCommand, _ := command.NewCreateWallpaperCommandFromYAML(suite.yamlByteStream)
+	checker.Assert(wallpaperCommand.RendersSampleSpace(), Equals, true)
+
+	wallpaperCommand.OutputMode = "poster"
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "unknown output_mode: poster")
+
+	wallpaperCommand.OutputMode = command.OutputLatticeTile
+	checker.Assert(wallpaperCommand.RendersSampleSpace(), Equals, false)
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "tile output needs a lattice_pattern")
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestTileOutputNeedsALatticeThatRepeatsAlongTheAxes(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`sample_source_filename: input.png
+output_filename: output.png
+output_size:
+  width: 800
+  height: 600
+output_mode: tile
+color_value_space:
+  minx: -2
+  miny: -2
+  maxx: 2
+  maxy: 2
+lattice_pattern:
+  lattice_type: generic
+  lattice_size:
+    width: 0.5
+    height: 1
+  multiplier:
+    real: 1
+    imaginary: 0
+  wave_packets:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      terms:
+        -
+          power_n: 1
+          power_m: -2
+`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.OutputMode, Equals, command.OutputLatticeTile)
+	checker.Assert(wallpaperCommand.Validate(), IsNil)
+
+	wallpaperCommand.LatticePattern.LatticeSize.Width = 1.41421356
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "tile output: the lattice does not repeat along both the real and imaginary axes")
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestFriezePeriodOutputMode(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`sample_source_filename: input.png
+output_filename: output.png
+output_size:
+  width: 800
+  height: 100
+output_mode: frieze_period
+frieze_period:
+  periods: 3
+sample_space:
+  minx: 0
+  miny: -1
+  maxx: 0
+  maxy: 1
+color_value_space:
+  minx: -2
+  miny: -2
+  maxx: 2
+  maxy: 2
+frieze_formula:
+  terms:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      power_n: 1
+      power_m: 0
+`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.OutputMode, Equals, command.OutputFriezePeriod)
+	checker.Assert(wallpaperCommand.FriezePeriods(), Equals, 3)
+	checker.Assert(wallpaperCommand.Validate(), IsNil)
+
+	wallpaperCommand.FriezePeriod = nil
+	checker.Assert(wallpaperCommand.FriezePeriods(), Equals, command.DefaultFriezePeriods)
+
+	wallpaperCommand.FriezePeriod = &command.FriezePeriod{Periods: 0}
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "frieze_period: periods must be positive: 0")
+
+	wallpaperCommand.FriezePeriod = nil
+	wallpaperCommand.SampleSpace.MaxY = -1
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "frieze_period output needs a sample_space with a nonzero height")
+}
+
+func (suite *CreateWallpaperCommandFromFileSuite) TestFriezePeriodNeedsAFrieze(checker *C) {
+	wallpaperCommand, _ := command.NewCreateWallpaperCommandFromYAML(suite.yamlByteStream)
+	wallpaperCommand.OutputMode = command.OutputFriezePeriod
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "frieze_period output needs a frieze_formula")
+}
+
+type CommandToYAMLSuite struct {
+}
+
+var _ = Suite(&CommandToYAMLSuite{})
+
+func assertYAMLRoundTripKeepsTheCommand(checker *C, yamlByteStream []byte) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML(yamlByteStream)
+	checker.Assert(err, IsNil)
+
+	marshaledCommand, err := wallpaperCommand.ToYAML()
+	checker.Assert(err, IsNil)
+	readBackCommand, err := command.NewCreateWallpaperCommandFromYAML(marshaledCommand)
+	checker.Assert(err, IsNil)
+	checker.Assert(readBackCommand, DeepEquals, wallpaperCommand)
+}
+
+func (suite *CommandToYAMLSuite) TestRosetteWithEveryOption(checker *C) {
+	assertYAMLRoundTripKeepsTheCommand(checker, []byte(`sample_source:
+  type: checkerboard
+  colors: ["#ff0000", "#0000ff"]
+  count: 4
+output_filename: output.png
+output_size:
+  width: 80
+  height: 60
+output_bit_depth: 16
+output_encoding:
+  jpeg_quality: 70
+viewport:
+  center:
+    real: 0.25
+    imaginary: -1e-3
+  zoom: 2
+  rotation: 30
+color_value_space: auto
+color_value_percentiles:
+  low: 5
+  high: 95
+source_sampling: bicubic
+out_of_range:
+  mode: background
+  color: "#10203040"
+antialias:
+  mode: jitter
+  samples: 3
+symmetry_overlay:
+  layer: svg
+  color: "#ffffff80"
+  line_width: 1.5
+rosette_formula:
+  terms:
+    -
+      multiplier:
+        real: 0.1
+        imaginary: -2.5e-7
+      power_n: 3
+      power_m: -2
+      ignore_complex_conjugate: true
+      coefficient_relationships:
+        - -M-N
+        - "+M+NF"
+`))
+}
+
+func (suite *CommandToYAMLSuite) TestFriezeWithPaletteColoring(checker *C) {
+	assertYAMLRoundTripKeepsTheCommand(checker, []byte(`output_filename: output.png
+output_size:
+  width: 80
+  height: 60
+output_mode: frieze_period
+frieze_period:
+  periods: 2
+sample_space:
+  minx: -3
+  miny: -1
+  maxx: 3
+  maxy: 1
+coloring: palette
+palette:
+  value: argument
+  scale: log
+  cyclic: true
+  stops:
+    - position: 0
+      color: "#000000"
+    - position: 0.5
+      color: "#ffffff"
+frieze_formula:
+  terms:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      power_n: 1
+      power_m: 0
+`))
+}
+
+func (suite *CommandToYAMLSuite) TestLatticeWithDomainColoring(checker *C) {
+	assertYAMLRoundTripKeepsTheCommand(checker, []byte(`output_filename: output.png
+output_size:
+  width: 80
+  height: 60
+output_mode: tile
+sample_space:
+  minx: -3
+  miny: -1
+  maxx: 3
+  maxy: 1
+color_value_space:
+  minx: -2
+  miny: -2
+  maxx: 2
+  maxy: 2
+coloring: domain
+domain_coloring:
+  modulus_contours: true
+  phase_contours: 6
+lattice_pattern:
+  lattice_type: rectangular
+  lattice_size:
+    width: 2
+    height: 0.5
+  multiplier:
+    real: 1
+    imaginary: 0
+  desired_symmetry: pmm
+  wave_packets:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0.5
+      terms:
+        -
+          power_n: 1
+          power_m: -2
+`))
+}
+
+func (suite *CommandToYAMLSuite) TestEmptyOptionsAreLeftOut(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`sample_source_filename: input.png
+output_filename: output.png
+`))
+	checker.Assert(err, IsNil)
+
+	marshaledCommand, err := wallpaperCommand.ToYAML()
+	checker.Assert(err, IsNil)
+	checker.Assert(string(marshaledCommand), Not(Matches), "(?s).*(viewport|output_mode|frieze_period|antialias|palette|rosette_formula|lattice_pattern).*")
+	checker.Assert(string(marshaledCommand), Matches, "(?s).*sample_source_filename: input.png.*")
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"wallpaper/entities/formula/wallpaper"
+)
+
+// Lattice overlay defaults.
+const (
+	DefaultLatticeOverlayColor       = "#ffffff"
+	DefaultLatticeOverlayDomainColor = "#ffff0080"
+	DefaultLatticeOverlayLineWidth   = 1.0
+)
+
+// LatticeOverlay outlines the cells of a lattice pattern's lattice,
+//   and shades one fundamental domain: the smallest region the symmetry group copies to fill the whole pattern.
+type LatticeOverlay struct {
+	OverlayOptions `yaml:",inline"`
+	// DomainColor fills the fundamental domain. Empty uses DefaultLatticeOverlayDomainColor.
+	DomainColor string `json:"domain_color" yaml:"domain_color"`
+	// Symmetry is the group whose fundamental domain is shaded. The pattern must have it.
+	//   Empty uses the lattice pattern's desired symmetry. If that is p1, the default, the most symmetric group the pattern has is used.
+	Symmetry wallpaper.Symmetry `json:"symmetry" yaml:"symmetry"`
+}
+
+// Validate returns an error if the overlay cannot be drawn.
+func (overlay *LatticeOverlay) Validate() error {
+	if optionsErr := overlay.OverlayOptions.Validate(); optionsErr != nil {
+		return optionsErr
+	}
+	if colorErr := validateOverlayColor("domain_color", overlay.DomainColor); colorErr != nil {
+		return colorErr
+	}
+	if overlay.Symmetry != "" && !overlay.Symmetry.IsKnown() {
+		return fmt.Errorf(`unknown symmetry: %s`, overlay.Symmetry)
+	}
+	return nil
+}
+
+// OverlayColor returns the cell edge color, or DefaultLatticeOverlayColor if none was given.
+func (overlay *LatticeOverlay) OverlayColor() string {
+	return overlay.colorOrDefault(DefaultLatticeOverlayColor)
+}
+
+// OverlayDomainColor returns the fundamental domain's color, or DefaultLatticeOverlayDomainColor if none was given.
+func (overlay *LatticeOverlay) OverlayDomainColor() string {
+	if overlay.DomainColor == "" {
+		return DefaultLatticeOverlayDomainColor
+	}
+	return overlay.DomainColor
+}
+
+// OverlayLineWidth returns the cell edge width, or DefaultLatticeOverlayLineWidth if none was given.
+func (overlay *LatticeOverlay) OverlayLineWidth() float64 {
+	return overlay.lineWidthOrDefault(DefaultLatticeOverlayLineWidth)
+}
+
+// LatticeOverlayFilename returns where the svg layer is written: the output filename ending in "_lattice.svg".
+//   It does not collide with the symmetry overlay's SVG file.
+func (command *CreateSymmetryPattern) LatticeOverlayFilename() string {
+	return overlayFilename(command.OutputFilename, "_lattice")
+}
+
+// validateLatticeOverlay returns an error if the overlay cannot be drawn for the command.
+func (command *CreateSymmetryPattern) validateLatticeOverlay() error {
+	if overlayErr := command.LatticeOverlay.Validate(); overlayErr != nil {
+		return overlayErr
+	}
+	if command.formulaKey() != "lattice_pattern" {
+		return errors.New(`only a lattice_pattern has a lattice to draw`)
+	}
+	if command.LatticeOverlay.WritesSVG() && command.Animation != nil {
+		return errors.New(`the svg layer cannot be used with an animation, use the image layer`)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package command_test
+
+import (
+	. "gopkg.in/check.v1"
+	"wallpaper/entities/command"
+	"wallpaper/entities/formula/wallpaper"
+)
+
+type LatticeOverlaySuite struct {
+}
+
+var _ = Suite(&LatticeOverlaySuite{})
+
+func (suite *LatticeOverlaySuite) TestEmptyOverlayUsesTheDefaults(checker *C) {
+	overlay := &command.LatticeOverlay{}
+	checker.Assert(overlay.Validate(), IsNil)
+	checker.Assert(overlay.OverlayColor(), Equals, command.DefaultLatticeOverlayColor)
+	checker.Assert(overlay.OverlayDomainColor(), Equals, command.DefaultLatticeOverlayDomainColor)
+	checker.Assert(overlay.OverlayLineWidth(), Equals, command.DefaultLatticeOverlayLineWidth)
+}
+
+func (suite *LatticeOverlaySuite) TestValidateChecksTheLatticeOptions(checker *C) {
+	checker.Assert((&command.LatticeOverlay{OverlayOptions: command.OverlayOptions{Layer: "pdf"}}).Validate(), ErrorMatches, "unknown layer: pdf")
+	checker.Assert((&command.LatticeOverlay{DomainColor: "red"}).Validate(), ErrorMatches, "domain_color: colors must look like #rrggbb or #rrggbbaa: red")
+	checker.Assert((&command.LatticeOverlay{Symmetry: "p5"}).Validate(), ErrorMatches, "unknown symmetry: p5")
+	checker.Assert((&command.LatticeOverlay{DomainColor: "#00000040", Symmetry: wallpaper.Cmm}).Validate(), IsNil)
+}
+
+func (suite *LatticeOverlaySuite) TestSVGLayerIsWrittenNextToTheOutput(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_filename: renders/pattern.png
+lattice_overlay:
+  layer: svg
+  symmetry: p4g
+`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.LatticeOverlay.Symmetry, Equals, wallpaper.P4g)
+	checker.Assert(wallpaperCommand.LatticeOverlayFilename(), Equals, "renders/pattern_lattice.svg")
+	checker.Assert(wallpaperCommand.SymmetryOverlayFilename(), Not(Equals), wallpaperCommand.LatticeOverlayFilename())
+}
+
+func (suite *LatticeOverlaySuite) TestOnlyLatticePatternsHaveALattice(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_filename: pattern.png
+output_size:
+  width: 10
+  height: 10
+sample_space:
+  minx: -1
+  miny: -1
+  maxx: 1
+  maxy: 1
+coloring: domain
+rosette_formula:
+  terms:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      power_n: 3
+      power_m: 0
+lattice_overlay:
+  layer: image
+`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "lattice_overlay: only a lattice_pattern has a lattice to draw")
+}
+
+func (suite *LatticeOverlaySuite) TestOverlayIsKeptWhenMarshaled(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_filename: pattern.png
+lattice_overlay:
+  color: "#000000"
+  line_width: 3
+`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.MarshalObject().LatticeOverlay, DeepEquals, &command.LatticeOverlay{OverlayOptions: command.OverlayOptions{Color: "#000000", LineWidth: 3}})
+}
@@ -0,0 +1,173 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"wallpaper/entities/formula/frieze"
+	"wallpaper/entities/formula/rosette"
+	"wallpaper/entities/formula/wallpaper"
+	"wallpaper/entities/utility"
+)
+
+// Morph blends the command's formula into a second formula of the same kind.
+//   Terms (or lattice wave packets) with the same powers have their multipliers interpolated,
+//   and terms found in only one of the formulas fade in or out.
+type Morph struct {
+	RosetteFormula *rosette.Formula
+	FriezeFormula  *frieze.Formula
+	LatticePattern *wallpaper.Formula
+	// Amount ranges from 0 (the command's formula) to 1 (the morph's formula).
+	//   An animation track on morph.amount morphs over time.
+	Amount float64
+	// Gradient morphs across the pattern instead. Amount is ignored when it is set.
+	Gradient *MorphGradient
+}
+
+// MorphGradient changes the morph's amount along a line through the sample space.
+type MorphGradient struct {
+	// Start is the point in the sample space where the amount is 0, and End is where it is 1.
+	//   Other points use the amount at the closest spot on the line between them,
+	//   so the amount stays at 0 before Start and at 1 past End.
+	Start utility.ComplexNumberForMarshal `json:"start" yaml:"start"`
+	End   utility.ComplexNumberForMarshal `json:"end" yaml:"end"`
+	// Interpolation defaults to linear.
+	Interpolation InterpolationMode `json:"interpolation,omitempty" yaml:"interpolation,omitempty"`
+}
+
+// MorphMarshal can be marshaled and converted to a Morph.
+type MorphMarshal struct {
+	RosetteFormula *rosette.MarshaledFormula `json:"rosette_formula,omitempty" yaml:"rosette_formula,omitempty"`
+	FriezeFormula  *frieze.MarshaledFormula  `json:"frieze_formula,omitempty" yaml:"frieze_formula,omitempty"`
+	LatticePattern *wallpaper.FormulaMarshal `json:"lattice_pattern,omitempty" yaml:"lattice_pattern,omitempty"`
+	Amount         float64                   `json:"amount" yaml:"amount"`
+	Gradient       *MorphGradient            `json:"gradient,omitempty" yaml:"gradient,omitempty"`
+}
+
+// NewMorphFromMarshalObject converts a marshaled morph into a Morph.
+func NewMorphFromMarshalObject(marshaledMorph MorphMarshal) *Morph {
+	morph := &Morph{
+		Amount:   marshaledMorph.Amount,
+		Gradient: marshaledMorph.Gradient,
+	}
+	if marshaledMorph.RosetteFormula != nil {
+		morph.RosetteFormula = rosette.NewRosetteFormulaFromMarshalObject(*marshaledMorph.RosetteFormula)
+	}
+	if marshaledMorph.FriezeFormula != nil {
+		morph.FriezeFormula = frieze.NewFriezeFormulaFromMarshalObject(*marshaledMorph.FriezeFormula)
+	}
+	if marshaledMorph.LatticePattern != nil {
+		morph.LatticePattern = wallpaper.NewFormulaFromMarshalObject(*marshaledMorph.LatticePattern)
+	}
+	return morph
+}
+
+// MarshalObject converts the morph into an object that can be marshaled.
+func (morph *Morph) MarshalObject() *MorphMarshal {
+	morphMarshal := &MorphMarshal{
+		Amount:   morph.Amount,
+		Gradient: morph.Gradient,
+	}
+	if morph.RosetteFormula != nil {
+		morphMarshal.RosetteFormula = morph.RosetteFormula.MarshalObject()
+	}
+	if morph.FriezeFormula != nil {
+		morphMarshal.FriezeFormula = morph.FriezeFormula.MarshalObject()
+	}
+	if morph.LatticePattern != nil {
+		morphMarshal.LatticePattern = morph.LatticePattern.MarshalObject()
+	}
+	return morphMarshal
+}
+
+// formulaKey returns the key of the formula the morph blends into.
+func (morph *Morph) formulaKey() string {
+	return formulaKeyFor(morph.FriezeFormula, morph.RosetteFormula, morph.LatticePattern)
+}
+
+// validateFor returns an error if the morph cannot blend the command's formula.
+func (morph *Morph) validateFor(command *CreateSymmetryPattern) error {
+	numberOfFormulas := 0
+	for _, hasFormula := range []bool{morph.RosetteFormula != nil, morph.FriezeFormula != nil, morph.LatticePattern != nil} {
+		if hasFormula {
+			numberOfFormulas++
+		}
+	}
+	if numberOfFormulas != 1 {
+		return errors.New(`morph needs exactly one rosette_formula, frieze_formula or lattice_pattern`)
+	}
+	if morph.formulaKey() != command.formulaKey() {
+		return fmt.Errorf(`morph needs a %s, like the command`, command.formulaKey())
+	}
+
+	if morph.RosetteFormula != nil {
+		if rosetteErr := morph.RosetteFormula.Validate(); rosetteErr != nil {
+			return fmt.Errorf(`rosette_formula: %v`, rosetteErr)
+		}
+	}
+	if morph.FriezeFormula != nil {
+		if friezeErr := morph.FriezeFormula.Validate(); friezeErr != nil {
+			return fmt.Errorf(`frieze_formula: %v`, friezeErr)
+		}
+	}
+	if morph.LatticePattern != nil {
+		if latticeErr := morph.LatticePattern.Validate(); latticeErr != nil {
+			return fmt.Errorf(`lattice_pattern: %v`, latticeErr)
+		}
+		morphSize, commandSize := morph.LatticePattern.LatticeSize, command.LatticePattern.LatticeSize
+		sameSize := (morphSize == nil && commandSize == nil) || (morphSize != nil && commandSize != nil && *morphSize == *commandSize)
+		if morph.LatticePattern.LatticeType != command.LatticePattern.LatticeType || !sameSize {
+			return errors.New(`lattice_pattern must use the same lattice_type and lattice_size as the command`)
+		}
+	}
+
+	if morph.Gradient != nil {
+		if gradientErr := morph.Gradient.Validate(); gradientErr != nil {
+			return fmt.Errorf(`gradient: %v`, gradientErr)
+		}
+	} else if morph.Amount < 0 || morph.Amount > 1 {
+		return fmt.Errorf(`amount must be from 0 to 1: %g`, morph.Amount)
+	}
+	return nil
+}
+
+// Validate returns an error if the gradient cannot be used.
+func (gradient *MorphGradient) Validate() error {
+	if gradient.Start == gradient.End {
+		return errors.New(`start and end must be different points`)
+	}
+	if !gradient.Interpolation.IsKnown() {
+		return fmt.Errorf(`unknown interpolation: %s`, gradient.Interpolation)
+	}
+	return nil
+}
+
+// AmountAt returns the morph's amount at the point in the sample space, from 0 to 1.
+func (gradient *MorphGradient) AmountAt(point complex128) float64 {
+	start := complex(gradient.Start.Real, gradient.Start.Imaginary)
+	direction := complex(gradient.End.Real, gradient.End.Imaginary) - start
+	offset := point - start
+
+	directionLengthSquared := real(direction)*real(direction) + imag(direction)*imag(direction)
+	amount := (real(offset)*real(direction) + imag(offset)*imag(direction)) / directionLengthSquared
+	if amount < 0 {
+		amount = 0
+	}
+	if amount > 1 {
+		amount = 1
+	}
+	return gradient.Interpolation.Ease(amount)
+}
+
+// formulaKeyFor returns the key of the formula that is rendered, out of the ones that are set.
+//   Friezes are picked first, then rosettes, then lattice patterns.
+func formulaKeyFor(friezeFormula *frieze.Formula, rosetteFormula *rosette.Formula, latticePattern *wallpaper.Formula) string {
+	switch {
+	case friezeFormula != nil:
+		return "frieze_formula"
+	case rosetteFormula != nil:
+		return "rosette_formula"
+	case latticePattern != nil:
+		return "lattice_pattern"
+	}
+	return ""
+}
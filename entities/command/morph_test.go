@@ -0,0 +1,135 @@
+package command_test
+
+import (
+	. "gopkg.in/check.v1"
+	"wallpaper/entities/command"
+	"wallpaper/entities/formula/frieze"
+	"wallpaper/entities/utility"
+)
+
+type MorphSuite struct {
+	wallpaperCommand *command.CreateSymmetryPattern
+}
+
+var _ = Suite(&MorphSuite{})
+
+func (suite *MorphSuite) SetUpTest(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_filename: morph.png
+output_size:
+  width: 4
+  height: 3
+sample_space:
+  minx: -1
+  miny: -1
+  maxx: 1
+  maxy: 1
+coloring: domain
+rosette_formula:
+  terms:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      power_n: 1
+      power_m: 0
+morph:
+  rosette_formula:
+    terms:
+      -
+        multiplier:
+          real: 0
+          imaginary: 1
+        power_n: 3
+        power_m: 0
+  amount: 0.25
+`))
+	checker.Assert(err, IsNil)
+	suite.wallpaperCommand = wallpaperCommand
+}
+
+func (suite *MorphSuite) TestCommandReadsTheMorph(checker *C) {
+	morph := suite.wallpaperCommand.Morph
+	checker.Assert(morph, NotNil)
+	checker.Assert(morph.Amount, Equals, 0.25)
+	checker.Assert(morph.RosetteFormula.Terms, HasLen, 1)
+	checker.Assert(morph.RosetteFormula.Terms[0].PowerN, Equals, 3)
+	checker.Assert(suite.wallpaperCommand.Validate(), IsNil)
+}
+
+func (suite *MorphSuite) TestMorphSurvivesTheRoundTrip(checker *C) {
+	suite.wallpaperCommand.Morph.Gradient = &command.MorphGradient{
+		Start:         utility.ComplexNumberForMarshal{Real: -1},
+		End:           utility.ComplexNumberForMarshal{Real: 1},
+		Interpolation: command.EaseInOut,
+	}
+	commandYAML, err := suite.wallpaperCommand.ToYAML()
+	checker.Assert(err, IsNil)
+
+	readCommand, err := command.NewCreateWallpaperCommandFromYAML(commandYAML)
+	checker.Assert(err, IsNil)
+	checker.Assert(readCommand.Morph, DeepEquals, suite.wallpaperCommand.Morph)
+}
+
+func (suite *MorphSuite) TestMorphAmountCanBeAnimated(checker *C) {
+	suite.wallpaperCommand.Animation = &command.Animation{
+		Frames: 3,
+		Tracks: []*command.AnimationTrack{
+			{
+				Field: "morph.amount",
+				Keyframes: []command.Keyframe{
+					{Frame: 0, Value: 0},
+					{Frame: 2, Value: 1},
+				},
+			},
+		},
+	}
+	checker.Assert(suite.wallpaperCommand.Validate(), IsNil)
+
+	frameCommand, err := suite.wallpaperCommand.AnimationFrame(1)
+	checker.Assert(err, IsNil)
+	checker.Assert(frameCommand.Morph.Amount, Equals, 0.5)
+}
+
+func (suite *MorphSuite) TestMorphNeedsTheSameKindOfFormula(checker *C) {
+	suite.wallpaperCommand.Morph.FriezeFormula = &frieze.Formula{Terms: suite.wallpaperCommand.Morph.RosetteFormula.Terms}
+	checker.Assert(suite.wallpaperCommand.Validate(), ErrorMatches, "morph: morph needs exactly one rosette_formula, frieze_formula or lattice_pattern")
+
+	suite.wallpaperCommand.Morph.RosetteFormula = nil
+	checker.Assert(suite.wallpaperCommand.Validate(), ErrorMatches, "morph: morph needs a rosette_formula, like the command")
+}
+
+func (suite *MorphSuite) TestMorphAmountMustBeInRange(checker *C) {
+	suite.wallpaperCommand.Morph.Amount = 1.5
+	checker.Assert(suite.wallpaperCommand.Validate(), ErrorMatches, "morph: amount must be from 0 to 1: 1.5")
+}
+
+func (suite *MorphSuite) TestGradientDoesNotRepeat(checker *C) {
+	suite.wallpaperCommand.Morph.Gradient = &command.MorphGradient{
+		Start: utility.ComplexNumberForMarshal{Real: -1},
+		End:   utility.ComplexNumberForMarshal{Real: 1},
+	}
+	checker.Assert(suite.wallpaperCommand.Validate(), IsNil)
+
+	suite.wallpaperCommand.OutputMode = command.OutputFriezePeriod
+	checker.Assert(suite.wallpaperCommand.Validate(), ErrorMatches, "frieze_period output cannot use a morph gradient, .*")
+}
+
+func (suite *MorphSuite) TestGradientNeedsTwoPoints(checker *C) {
+	suite.wallpaperCommand.Morph.Gradient = &command.MorphGradient{}
+	checker.Assert(suite.wallpaperCommand.Validate(), ErrorMatches, "morph: gradient: start and end must be different points")
+}
+
+func (suite *MorphSuite) TestGradientAmountFollowsTheLine(checker *C) {
+	gradient := &command.MorphGradient{
+		Start: utility.ComplexNumberForMarshal{Real: -1, Imaginary: 0},
+		End:   utility.ComplexNumberForMarshal{Real: 1, Imaginary: 0},
+	}
+	checker.Assert(gradient.AmountAt(complex(-1, 0)), Equals, 0.0)
+	checker.Assert(gradient.AmountAt(complex(0.5, 3)), Equals, 0.75)
+	checker.Assert(gradient.AmountAt(complex(-5, 0)), Equals, 0.0)
+	checker.Assert(gradient.AmountAt(complex(5, 0)), Equals, 1.0)
+
+	gradient.Interpolation = command.EaseInOut
+	checker.Assert(gradient.AmountAt(complex(0.5, 0)), utility.NumericallyCloseEnough{}, 0.84375, 1e-6)
+}
@@ -0,0 +1,55 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"wallpaper/entities/colorsource"
+)
+
+// OutOfRangeMode decides how to color transformed values that fall outside of the color value space.
+type OutOfRangeMode string
+
+// Out of range modes.
+const (
+	// OutOfRangeTransparent leaves the pixel transparent.
+	OutOfRangeTransparent OutOfRangeMode = "transparent"
+	// OutOfRangeClamp uses the closest color on the edge of the source image.
+	OutOfRangeClamp OutOfRangeMode = "clamp"
+	// OutOfRangeWrap repeats the source image, like tiles.
+	OutOfRangeWrap OutOfRangeMode = "wrap"
+	// OutOfRangeMirror repeats the source image, flipping every other copy.
+	OutOfRangeMirror OutOfRangeMode = "mirror"
+	// OutOfRangeBackground uses the background color.
+	OutOfRangeBackground OutOfRangeMode = "background"
+)
+
+var knownOutOfRangeModes = map[OutOfRangeMode]bool{
+	OutOfRangeTransparent: true,
+	OutOfRangeClamp:       true,
+	OutOfRangeWrap:        true,
+	OutOfRangeMirror:      true,
+	OutOfRangeBackground:  true,
+}
+
+// OutOfRangeOptions colors transformed values that fall outside of the color value space.
+type OutOfRangeOptions struct {
+	Mode OutOfRangeMode `json:"mode" yaml:"mode"`
+	// Color is the background color, like "#rrggbb" or "#rrggbbaa". Only the background mode uses it.
+	Color string `json:"color" yaml:"color"`
+}
+
+// Validate returns an error if the options cannot be used.
+func (options *OutOfRangeOptions) Validate() error {
+	if !knownOutOfRangeModes[options.Mode] {
+		return fmt.Errorf(`unknown out_of_range mode: %s`, options.Mode)
+	}
+	if options.Mode != OutOfRangeBackground {
+		return nil
+	}
+
+	if options.Color == "" {
+		return errors.New(`background mode needs a color`)
+	}
+	_, err := colorsource.ParseHexColor(options.Color)
+	return err
+}
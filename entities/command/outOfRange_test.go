@@ -0,0 +1,51 @@
+package command_test
+
+import (
+	. "gopkg.in/check.v1"
+	"wallpaper/entities/command"
+)
+
+type OutOfRangeOptionsSuite struct {
+}
+
+var _ = Suite(&OutOfRangeOptionsSuite{})
+
+func (suite *OutOfRangeOptionsSuite) TestModesWithoutColors(checker *C) {
+	for _, mode := range []command.OutOfRangeMode{
+		command.OutOfRangeTransparent,
+		command.OutOfRangeClamp,
+		command.OutOfRangeWrap,
+		command.OutOfRangeMirror,
+	} {
+		checker.Assert((&command.OutOfRangeOptions{Mode: mode}).Validate(), IsNil)
+	}
+}
+
+func (suite *OutOfRangeOptionsSuite) TestUnknownModeIsAnError(checker *C) {
+	err := (&command.OutOfRangeOptions{Mode: "stretch"}).Validate()
+	checker.Assert(err, ErrorMatches, "unknown out_of_range mode: stretch")
+}
+
+func (suite *OutOfRangeOptionsSuite) TestBackgroundNeedsAColor(checker *C) {
+	err := (&command.OutOfRangeOptions{Mode: command.OutOfRangeBackground}).Validate()
+	checker.Assert(err, ErrorMatches, "background mode needs a color")
+
+	err = (&command.OutOfRangeOptions{Mode: command.OutOfRangeBackground, Color: "blue"}).Validate()
+	checker.Assert(err, ErrorMatches, "colors must look like #rrggbb or #rrggbbaa: blue")
+
+	err = (&command.OutOfRangeOptions{Mode: command.OutOfRangeBackground, Color: "#0000ff"}).Validate()
+	checker.Assert(err, IsNil)
+}
+
+func (suite *OutOfRangeOptionsSuite) TestCommandReadsOutOfRangeOptions(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+out_of_range:
+  mode: background
+  color: "#102030"
+`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.OutOfRange, DeepEquals, &command.OutOfRangeOptions{
+		Mode:  command.OutOfRangeBackground,
+		Color: "#102030",
+	})
+}
@@ -0,0 +1,85 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+)
+
+// OutputMode decides which part of the pattern is rendered.
+type OutputMode string
+
+// Output modes.
+const (
+	// OutputSampleSpace renders the sample space, or the viewport when there is one.
+	OutputSampleSpace OutputMode = "sample_space"
+	// OutputLatticeTile renders one repeat of a lattice pattern, as a rectangle that tiles seamlessly.
+	//   The sample space and viewport are ignored, and the output height is chosen to match the output width.
+	OutputLatticeTile OutputMode = "tile"
+	// OutputFriezePeriod renders a whole number of periods of a frieze pattern, as a strip that loops seamlessly.
+	//   The strip starts at the sample space's minx and keeps its miny and maxy.
+	//   The output width is chosen to match the output height.
+	OutputFriezePeriod OutputMode = "frieze_period"
+)
+
+var knownOutputModes = map[OutputMode]bool{
+	OutputSampleSpace:  true,
+	OutputLatticeTile:  true,
+	OutputFriezePeriod: true,
+}
+
+// RendersSampleSpace returns true if the command renders its sample space or viewport.
+//   Commands without an output mode render the sample space.
+func (command *CreateSymmetryPattern) RendersSampleSpace() bool {
+	return command.OutputMode == "" || command.OutputMode == OutputSampleSpace
+}
+
+// FriezePeriod changes the strip rendered by the frieze_period output mode.
+type FriezePeriod struct {
+	// Periods is the number of times the frieze repeats across the strip.
+	Periods int `json:"periods" yaml:"periods"`
+}
+
+// DefaultFriezePeriods is used when the command has no frieze_period options.
+const DefaultFriezePeriods = 1
+
+// FriezePeriods returns the number of periods the frieze_period output mode renders.
+func (command *CreateSymmetryPattern) FriezePeriods() int {
+	if command.FriezePeriod == nil {
+		return DefaultFriezePeriods
+	}
+	return command.FriezePeriod.Periods
+}
+
+// validateOutputMode returns an error if the command's formula cannot be rendered in its output mode.
+func (command *CreateSymmetryPattern) validateOutputMode() error {
+	if command.OutputMode != "" && !knownOutputModes[command.OutputMode] {
+		return fmt.Errorf(`unknown output_mode: %s`, command.OutputMode)
+	}
+	if command.FriezePeriod != nil && command.FriezePeriod.Periods < 1 {
+		return fmt.Errorf(`frieze_period: periods must be positive: %d`, command.FriezePeriod.Periods)
+	}
+	if command.RendersSampleSpace() {
+		return nil
+	}
+
+	if command.Morph != nil && command.Morph.Gradient != nil {
+		return fmt.Errorf(`%s output cannot use a morph gradient, because the gradient does not repeat`, command.OutputMode)
+	}
+	switch command.OutputMode {
+	case OutputLatticeTile:
+		if command.formulaKey() != "lattice_pattern" {
+			return errors.New(`tile output needs a lattice_pattern`)
+		}
+		if _, repeatErr := command.LatticePattern.RectangularRepeat(); repeatErr != nil {
+			return fmt.Errorf(`tile output: %v`, repeatErr)
+		}
+	case OutputFriezePeriod:
+		if command.formulaKey() != "frieze_formula" {
+			return errors.New(`frieze_period output needs a frieze_formula`)
+		}
+		if command.SampleSpace.MinY == command.SampleSpace.MaxY {
+			return errors.New(`frieze_period output needs a sample_space with a nonzero height`)
+		}
+	}
+	return nil
+}
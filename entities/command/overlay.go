@@ -0,0 +1,86 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"wallpaper/entities/colorsource"
+)
+
+// OverlayLayer decides where an overlay is drawn.
+type OverlayLayer string
+
+// Overlay layers.
+const (
+	// OverlayOnImage draws the overlay on top of the output image.
+	OverlayOnImage OverlayLayer = "image"
+	// OverlaySVG writes the overlay to an SVG file the same size as the output image,
+	//   so it can be laid over the image or hidden.
+	OverlaySVG OverlayLayer = "svg"
+)
+
+var knownOverlayLayers = map[OverlayLayer]bool{
+	OverlayOnImage: true,
+	OverlaySVG:     true,
+}
+
+// OverlayOptions are the options every overlay has. Overlays embed them, so the options sit next to their own.
+type OverlayOptions struct {
+	// Layer is where the overlay is drawn. Empty draws it on the image.
+	Layer OverlayLayer `json:"layer" yaml:"layer"`
+	// Color is the color of the overlay's lines, like "#rrggbb" or "#rrggbbaa". Empty uses the overlay's default.
+	Color string `json:"color" yaml:"color"`
+	// LineWidth is the width of the overlay's lines, in output pixels. 0 uses the overlay's default.
+	LineWidth float64 `json:"line_width" yaml:"line_width"`
+}
+
+// Validate returns an error if the layer is unknown, the color cannot be parsed or the line width is negative.
+func (options *OverlayOptions) Validate() error {
+	if options.Layer != "" && !knownOverlayLayers[options.Layer] {
+		return fmt.Errorf(`unknown layer: %s`, options.Layer)
+	}
+	if colorErr := validateOverlayColor("color", options.Color); colorErr != nil {
+		return colorErr
+	}
+	if options.LineWidth < 0 {
+		return fmt.Errorf(`line_width cannot be negative: %g`, options.LineWidth)
+	}
+	return nil
+}
+
+// WritesSVG returns true if the overlay is written to a separate SVG file.
+func (options *OverlayOptions) WritesSVG() bool {
+	return options.Layer == OverlaySVG
+}
+
+// colorOrDefault returns the color, or defaultColor if none was given.
+func (options *OverlayOptions) colorOrDefault(defaultColor string) string {
+	if options.Color == "" {
+		return defaultColor
+	}
+	return options.Color
+}
+
+// lineWidthOrDefault returns the line width, or defaultLineWidth if none was given.
+func (options *OverlayOptions) lineWidthOrDefault(defaultLineWidth float64) float64 {
+	if options.LineWidth == 0 {
+		return defaultLineWidth
+	}
+	return options.LineWidth
+}
+
+// validateOverlayColor returns an error if the color is set but cannot be parsed. name is the option's key.
+func validateOverlayColor(name, color string) error {
+	if color == "" {
+		return nil
+	}
+	if _, err := colorsource.ParseHexColor(color); err != nil {
+		return fmt.Errorf(`%s: %v`, name, err)
+	}
+	return nil
+}
+
+// overlayFilename returns the output filename with the suffix and an .svg extension, like "pattern_lattice.svg".
+func overlayFilename(outputFilename, suffix string) string {
+	return strings.TrimSuffix(outputFilename, filepath.Ext(outputFilename)) + suffix + ".svg"
+}
@@ -0,0 +1,49 @@
+package command_test
+
+import (
+	"encoding/json"
+	. "gopkg.in/check.v1"
+	"wallpaper/entities/command"
+)
+
+type OverlayOptionsSuite struct {
+}
+
+var _ = Suite(&OverlayOptionsSuite{})
+
+func (suite *OverlayOptionsSuite) TestEmptyOptionsDrawOnTheImage(checker *C) {
+	options := &command.OverlayOptions{}
+	checker.Assert(options.Validate(), IsNil)
+	checker.Assert(options.WritesSVG(), Equals, false)
+}
+
+func (suite *OverlayOptionsSuite) TestValidateChecksEachOption(checker *C) {
+	checker.Assert((&command.OverlayOptions{Layer: "pdf"}).Validate(), ErrorMatches, "unknown layer: pdf")
+	checker.Assert((&command.OverlayOptions{Color: "red"}).Validate(), ErrorMatches, "color: colors must look like #rrggbb or #rrggbbaa: red")
+	checker.Assert((&command.OverlayOptions{LineWidth: -1}).Validate(), ErrorMatches, "line_width cannot be negative: -1")
+	checker.Assert((&command.OverlayOptions{Layer: command.OverlaySVG, Color: "#00000040", LineWidth: 0.5}).Validate(), IsNil)
+	checker.Assert((&command.OverlayOptions{Layer: command.OverlaySVG}).WritesSVG(), Equals, true)
+}
+
+func (suite *OverlayOptionsSuite) TestOptionsSitNextToEachOverlaysOwnOptions(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_filename: renders/pattern.png
+symmetry_overlay:
+  layer: svg
+  color: "#00ff00"
+  line_width: 3
+lattice_overlay:
+  layer: svg
+  color: "#000000"
+  line_width: 0.5
+  domain_color: "#00ff0080"
+`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.SymmetryOverlay.OverlayOptions, Equals, command.OverlayOptions{Layer: command.OverlaySVG, Color: "#00ff00", LineWidth: 3})
+	checker.Assert(wallpaperCommand.LatticeOverlay.OverlayOptions, Equals, command.OverlayOptions{Layer: command.OverlaySVG, Color: "#000000", LineWidth: 0.5})
+	checker.Assert(wallpaperCommand.LatticeOverlay.DomainColor, Equals, "#00ff0080")
+
+	marshaledJSON, err := json.Marshal(wallpaperCommand.MarshalObject().LatticeOverlay)
+	checker.Assert(err, IsNil)
+	checker.Assert(string(marshaledJSON), Equals, `{"layer":"svg","color":"#000000","line_width":0.5,"domain_color":"#00ff0080","symmetry":""}`)
+}
@@ -0,0 +1,50 @@
+package command
+
+import (
+	"encoding/json"
+	"wallpaper/entities/colorsource"
+)
+
+// SampleSourceMarshal is either the filename of a source image, or a generator that draws one.
+type SampleSourceMarshal struct {
+	Filename  string
+	Generator *colorsource.Generator
+}
+
+// UnmarshalYAML reads a filename, or a generator.
+func (sampleSource *SampleSourceMarshal) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var filename string
+	if unmarshal(&filename) == nil {
+		sampleSource.Filename = filename
+		return nil
+	}
+	sampleSource.Generator = &colorsource.Generator{}
+	return unmarshal(sampleSource.Generator)
+}
+
+// UnmarshalJSON reads a filename, or a generator.
+func (sampleSource *SampleSourceMarshal) UnmarshalJSON(data []byte) error {
+	var filename string
+	if json.Unmarshal(data, &filename) == nil {
+		sampleSource.Filename = filename
+		return nil
+	}
+	sampleSource.Generator = &colorsource.Generator{}
+	return json.Unmarshal(data, sampleSource.Generator)
+}
+
+// MarshalYAML writes a filename, or a generator.
+func (sampleSource SampleSourceMarshal) MarshalYAML() (interface{}, error) {
+	if sampleSource.Generator != nil {
+		return sampleSource.Generator, nil
+	}
+	return sampleSource.Filename, nil
+}
+
+// MarshalJSON writes a filename, or a generator.
+func (sampleSource SampleSourceMarshal) MarshalJSON() ([]byte, error) {
+	if sampleSource.Generator != nil {
+		return json.Marshal(sampleSource.Generator)
+	}
+	return json.Marshal(sampleSource.Filename)
+}
@@ -0,0 +1,69 @@
+package command_test
+
+import (
+	. "gopkg.in/check.v1"
+	"gopkg.in/yaml.v2"
+	"wallpaper/entities/colorsource"
+	"wallpaper/entities/command"
+)
+
+type SampleSourceSuite struct {
+}
+
+var _ = Suite(&SampleSourceSuite{})
+
+func (suite *SampleSourceSuite) TestSampleSourceCanBeAFilename(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`sample_source: example/rainbow_stripe.png`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.SampleSourceFilename, Equals, "example/rainbow_stripe.png")
+	checker.Assert(wallpaperCommand.SampleSourceGenerator, IsNil)
+}
+
+func (suite *SampleSourceSuite) TestSampleSourceCanBeAGenerator(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+sample_source:
+  type: stripes
+  width: 64
+  height: 32
+  count: 4
+  colors:
+    - "#ff0000"
+    - "#00ff00"
+`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.SampleSourceFilename, Equals, "")
+	checker.Assert(wallpaperCommand.SampleSourceGenerator, DeepEquals, &colorsource.Generator{
+		Type:   colorsource.Stripes,
+		Width:  64,
+		Height: 32,
+		Count:  4,
+		Colors: []string{"#ff0000", "#00ff00"},
+	})
+}
+
+func (suite *SampleSourceSuite) TestSampleSourceGeneratorFromJSON(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromJSON([]byte(`{"sample_source": {"type": "color_wheel"}}`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.SampleSourceGenerator, DeepEquals, &colorsource.Generator{Type: colorsource.ColorWheel})
+}
+
+func (suite *SampleSourceSuite) TestOnlyOneSampleSourceIsAllowed(checker *C) {
+	_, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+sample_source: a.png
+sample_source_filename: b.png
+`))
+	checker.Assert(err, ErrorMatches, "use sample_source or sample_source_filename, not both")
+}
+
+func (suite *SampleSourceSuite) TestMarshalWritesTheSameForm(checker *C) {
+	filenameBytes, err := yaml.Marshal(command.SampleSourceMarshal{Filename: "a.png"})
+	checker.Assert(err, IsNil)
+	checker.Assert(string(filenameBytes), Equals, "a.png\n")
+
+	generatorBytes, err := yaml.Marshal(command.SampleSourceMarshal{Generator: &colorsource.Generator{Type: colorsource.Rings}})
+	checker.Assert(err, IsNil)
+
+	var roundTrip command.SampleSourceMarshal
+	checker.Assert(yaml.Unmarshal(generatorBytes, &roundTrip), IsNil)
+	checker.Assert(roundTrip.Generator, DeepEquals, &colorsource.Generator{Type: colorsource.Rings})
+}
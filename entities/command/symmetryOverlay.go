@@ -0,0 +1,43 @@
+package command
+
+import (
+	"errors"
+)
+
+// Symmetry overlay defaults.
+const (
+	DefaultSymmetryOverlayColor     = "#ff00ff"
+	DefaultSymmetryOverlayLineWidth = 2.0
+)
+
+// SymmetryOverlay draws the pattern's mirror lines, glide axes and rotation centers.
+//   Glide axes are dashed, and each n-fold rotation center is marked with an n-sided shape.
+type SymmetryOverlay struct {
+	OverlayOptions `yaml:",inline"`
+}
+
+// OverlayColor returns the color of the lines and markers, or DefaultSymmetryOverlayColor if none was given.
+func (overlay *SymmetryOverlay) OverlayColor() string {
+	return overlay.colorOrDefault(DefaultSymmetryOverlayColor)
+}
+
+// OverlayLineWidth returns the line width, or DefaultSymmetryOverlayLineWidth if none was given.
+func (overlay *SymmetryOverlay) OverlayLineWidth() float64 {
+	return overlay.lineWidthOrDefault(DefaultSymmetryOverlayLineWidth)
+}
+
+// SymmetryOverlayFilename returns where the svg layer is written: the output filename with an .svg extension.
+func (command *CreateSymmetryPattern) SymmetryOverlayFilename() string {
+	return overlayFilename(command.OutputFilename, "")
+}
+
+// validateSymmetryOverlay returns an error if the overlay cannot be drawn for the command.
+func (command *CreateSymmetryPattern) validateSymmetryOverlay() error {
+	if overlayErr := command.SymmetryOverlay.Validate(); overlayErr != nil {
+		return overlayErr
+	}
+	if command.SymmetryOverlay.WritesSVG() && command.Animation != nil {
+		return errors.New(`the svg layer cannot be used with an animation, use the image layer`)
+	}
+	return nil
+}
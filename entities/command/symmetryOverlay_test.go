@@ -0,0 +1,66 @@
+package command_test
+
+import (
+	. "gopkg.in/check.v1"
+	"wallpaper/entities/command"
+)
+
+type SymmetryOverlaySuite struct {
+}
+
+var _ = Suite(&SymmetryOverlaySuite{})
+
+func (suite *SymmetryOverlaySuite) TestEmptyOverlayUsesTheDefaults(checker *C) {
+	overlay := &command.SymmetryOverlay{}
+	checker.Assert(overlay.OverlayColor(), Equals, command.DefaultSymmetryOverlayColor)
+	checker.Assert(overlay.OverlayLineWidth(), Equals, command.DefaultSymmetryOverlayLineWidth)
+}
+
+func (suite *SymmetryOverlaySuite) TestSVGLayerIsWrittenNextToTheOutput(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_filename: renders/pattern.png
+symmetry_overlay:
+  layer: svg
+`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.SymmetryOverlayFilename(), Equals, "renders/pattern.svg")
+}
+
+func (suite *SymmetryOverlaySuite) TestCommandValidatesTheOverlay(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_filename: pattern.gif
+output_size:
+  width: 10
+  height: 10
+sample_space:
+  minx: -1
+  miny: -1
+  maxx: 1
+  maxy: 1
+coloring: domain
+rosette_formula:
+  terms:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      power_n: 3
+      power_m: 0
+animation:
+  frames: 2
+  tracks:
+    - field: rosette_formula.terms[0].multiplier
+      keyframes:
+        - frame: 0
+          value: {real: 1, imaginary: 0}
+        - frame: 1
+          value: {real: 2, imaginary: 0}
+symmetry_overlay:
+  layer: svg
+`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.Validate(), ErrorMatches, "symmetry_overlay: the svg layer cannot be used with an animation, use the image layer")
+
+	wallpaperCommand.SymmetryOverlay.Layer = command.OverlayOnImage
+	checker.Assert(wallpaperCommand.Validate(), IsNil)
+}
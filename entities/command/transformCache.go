@@ -0,0 +1,55 @@
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"wallpaper/entities/formula/frieze"
+	"wallpaper/entities/formula/rosette"
+	"wallpaper/entities/formula/wallpaper"
+)
+
+// transformCacheVersion changes whenever the same inputs would calculate different transformed values,
+//   so caches written by older versions are not reused.
+const transformCacheVersion = 1
+
+// transformCacheInputs lists every option that changes which points are transformed, or how.
+//   Coloring options, overlays and output files are left out, so changing them reuses the cache.
+type transformCacheInputs struct {
+	Version         int                       `json:"version"`
+	SampleSpace     ComplexNumberCorners      `json:"sample_space"`
+	Viewport        *Viewport                 `json:"viewport"`
+	OutputImageSize WidthHeightDimensions     `json:"output_size"`
+	OutputMode      OutputMode                `json:"output_mode"`
+	FriezePeriod    *FriezePeriod             `json:"frieze_period"`
+	Antialias       *AntialiasOptions         `json:"antialias"`
+	Morph           *MorphMarshal             `json:"morph"`
+	RosetteFormula  *rosette.MarshaledFormula `json:"rosette_formula"`
+	FriezeFormula   *frieze.MarshaledFormula  `json:"frieze_formula"`
+	LatticePattern  *wallpaper.FormulaMarshal `json:"lattice_pattern"`
+}
+
+// TransformCacheKey returns a hash of the formula, sample space, output size and antialiasing.
+//   Two commands with the same key transform the same points into the same values,
+//   even if they color them differently.
+func (command *CreateSymmetryPattern) TransformCacheKey() (string, error) {
+	commandMarshal := command.MarshalObject()
+	inputs, err := json.Marshal(transformCacheInputs{
+		Version:         transformCacheVersion,
+		SampleSpace:     commandMarshal.SampleSpace,
+		Viewport:        commandMarshal.Viewport,
+		OutputImageSize: commandMarshal.OutputImageSize,
+		OutputMode:      commandMarshal.OutputMode,
+		FriezePeriod:    commandMarshal.FriezePeriod,
+		Antialias:       commandMarshal.Antialias,
+		Morph:           commandMarshal.Morph,
+		RosetteFormula:  commandMarshal.RosetteFormula,
+		FriezeFormula:   commandMarshal.FriezeFormula,
+		LatticePattern:  commandMarshal.LatticePattern,
+	})
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(inputs)
+	return hex.EncodeToString(hash[:]), nil
+}
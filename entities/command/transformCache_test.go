@@ -0,0 +1,79 @@
+package command_test
+
+import (
+	. "gopkg.in/check.v1"
+	"wallpaper/entities/command"
+)
+
+type TransformCacheKeySuite struct {
+}
+
+var _ = Suite(&TransformCacheKeySuite{})
+
+func newTransformCacheCommand(checker *C) *command.CreateSymmetryPattern {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_filename: pattern.png
+output_size:
+  width: 10
+  height: 10
+sample_space:
+  minx: -1
+  miny: -1
+  maxx: 1
+  maxy: 1
+sample_source_filename: source.png
+color_value_space:
+  minx: -2
+  miny: -2
+  maxx: 2
+  maxy: 2
+rosette_formula:
+  terms:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      power_n: 3
+      power_m: 0
+`))
+	checker.Assert(err, IsNil)
+	return wallpaperCommand
+}
+
+func transformCacheKey(checker *C, wallpaperCommand *command.CreateSymmetryPattern) string {
+	key, err := wallpaperCommand.TransformCacheKey()
+	checker.Assert(err, IsNil)
+	return key
+}
+
+func (suite *TransformCacheKeySuite) TestColoringOptionsKeepTheKey(checker *C) {
+	key := transformCacheKey(checker, newTransformCacheCommand(checker))
+	checker.Assert(key, Matches, "[0-9a-f]{64}")
+
+	recolored := newTransformCacheCommand(checker)
+	recolored.OutputFilename = "recolored.png"
+	recolored.SampleSourceFilename = "other_source.png"
+	recolored.ColorValueSpace = command.ComplexNumberCorners{MinX: -5, MinY: -5, MaxX: 5, MaxY: 5}
+	recolored.SymmetryOverlay = &command.SymmetryOverlay{}
+	checker.Assert(transformCacheKey(checker, recolored), Equals, key)
+}
+
+func (suite *TransformCacheKeySuite) TestTransformOptionsChangeTheKey(checker *C) {
+	key := transformCacheKey(checker, newTransformCacheCommand(checker))
+
+	otherFormula := newTransformCacheCommand(checker)
+	otherFormula.RosetteFormula.Terms[0].PowerN = 4
+	checker.Assert(transformCacheKey(checker, otherFormula), Not(Equals), key)
+
+	otherSize := newTransformCacheCommand(checker)
+	otherSize.OutputImageSize = command.WidthHeightDimensions{Width: 20, Height: 10}
+	checker.Assert(transformCacheKey(checker, otherSize), Not(Equals), key)
+
+	otherSampleSpace := newTransformCacheCommand(checker)
+	otherSampleSpace.SampleSpace.MaxX = 2
+	checker.Assert(transformCacheKey(checker, otherSampleSpace), Not(Equals), key)
+
+	antialiased := newTransformCacheCommand(checker)
+	antialiased.Antialias = &command.AntialiasOptions{Samples: 4}
+	checker.Assert(transformCacheKey(checker, antialiased), Not(Equals), key)
+}
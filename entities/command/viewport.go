@@ -0,0 +1,25 @@
+package command
+
+import (
+	"errors"
+	"wallpaper/entities/utility"
+)
+
+// Viewport describes the sample space by its center instead of its corners.
+//   The sample space grows and shrinks with the output size, so the pattern is never stretched.
+type Viewport struct {
+	Center utility.ComplexNumberForMarshal `json:"center" yaml:"center"`
+	// Zoom is the distance between neighboring pixels in the sample space.
+	Zoom float64 `json:"zoom" yaml:"zoom"`
+	// Rotation turns the viewport around its center, in degrees.
+	Rotation float64 `json:"rotation" yaml:"rotation"`
+}
+
+// Validate returns an error if the viewport cannot be used.
+func (viewport *Viewport) Validate() error {
+	if viewport.Zoom <= 0 {
+		return errors.New(`zoom must be positive`)
+	}
+	return nil
+}
+
@@ -0,0 +1,34 @@
+package command_test
+
+import (
+	. "gopkg.in/check.v1"
+	"wallpaper/entities/command"
+	"wallpaper/entities/utility"
+)
+
+type ViewportSuite struct {
+}
+
+var _ = Suite(&ViewportSuite{})
+
+func (suite *ViewportSuite) TestCommandReadsViewport(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+viewport:
+  center:
+    real: 1
+    imaginary: -2
+  zoom: 1e-2
+  rotation: 30
+`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.Viewport, DeepEquals, &command.Viewport{
+		Center:   utility.ComplexNumberForMarshal{Real: 1, Imaginary: -2},
+		Zoom:     1e-2,
+		Rotation: 30,
+	})
+}
+
+func (suite *ViewportSuite) TestZoomMustBePositive(checker *C) {
+	checker.Assert((&command.Viewport{Zoom: 0}).Validate(), ErrorMatches, "zoom must be positive")
+	checker.Assert((&command.Viewport{Zoom: 0.5}).Validate(), IsNil)
+}
@@ -0,0 +1,41 @@
+package encoder
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"math"
+	"time"
+)
+
+// EncodeAnimatedGIF writes the frames as a GIF that loops forever, showing each frame for frameDelay.
+//   GIF delays are counted in hundredths of a second, so frameDelay is rounded to the nearest one.
+//   Each frame gets its own palette, chosen the same way as a single GIF with these options.
+//   options may be nil to use the defaults.
+func EncodeAnimatedGIF(writer io.Writer, frames []image.Image, frameDelay time.Duration, options *Options) error {
+	if len(frames) == 0 {
+		return errors.New(`an animated gif needs at least one frame`)
+	}
+
+	delayInHundredths := int(math.Round(frameDelay.Seconds() * 100))
+	if delayInHundredths < 1 {
+		delayInHundredths = 1
+	}
+
+	gifOptions := options.GIFOptions()
+	animation := &gif.GIF{}
+	for _, frame := range frames {
+		bounds := frame.Bounds()
+		palette := gifOptions.Quantizer.Quantize(make(color.Palette, 0, gifOptions.NumColors), frame)
+		palettedFrame := image.NewPaletted(bounds, palette)
+		gifOptions.Drawer.Draw(palettedFrame, bounds, frame, bounds.Min)
+
+		animation.Image = append(animation.Image, palettedFrame)
+		animation.Delay = append(animation.Delay, delayInHundredths)
+		// Clearing each frame keeps transparent pixels from showing the frame before.
+		animation.Disposal = append(animation.Disposal, gif.DisposalBackground)
+	}
+	return gif.EncodeAll(writer, animation)
+}
@@ -0,0 +1,68 @@
+package encoder_test
+
+import (
+	"bytes"
+	. "gopkg.in/check.v1"
+	"image"
+	"image/color"
+	"image/gif"
+	"time"
+	"wallpaper/entities/encoder"
+)
+
+type AnimatedGIFSuite struct {
+	frames []image.Image
+}
+
+var _ = Suite(&AnimatedGIFSuite{})
+
+func (suite *AnimatedGIFSuite) SetUpTest(checker *C) {
+	suite.frames = []image.Image{}
+	for _, frameColor := range []color.NRGBA{
+		{R: 255, A: 255},
+		{G: 255, A: 255},
+		{B: 255, A: 0},
+	} {
+		frame := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+		for y := 0; y < 2; y++ {
+			for x := 0; x < 3; x++ {
+				frame.SetNRGBA(x, y, frameColor)
+			}
+		}
+		suite.frames = append(suite.frames, frame)
+	}
+}
+
+func (suite *AnimatedGIFSuite) TestEveryFrameIsWrittenWithTheDelay(checker *C) {
+	var buffer bytes.Buffer
+	err := encoder.EncodeAnimatedGIF(&buffer, suite.frames, 50*time.Millisecond, nil)
+	checker.Assert(err, IsNil)
+
+	decodedGIF, err := gif.DecodeAll(&buffer)
+	checker.Assert(err, IsNil)
+	checker.Assert(decodedGIF.Image, HasLen, 3)
+	checker.Assert(decodedGIF.Delay, DeepEquals, []int{5, 5, 5})
+	checker.Assert(decodedGIF.LoopCount, Equals, 0)
+	checker.Assert(decodedGIF.Disposal, DeepEquals, []byte{gif.DisposalBackground, gif.DisposalBackground, gif.DisposalBackground})
+
+	checker.Assert(color.NRGBAModel.Convert(decodedGIF.Image[0].At(1, 1)), Equals, color.Color(color.NRGBA{R: 255, A: 255}))
+	checker.Assert(color.NRGBAModel.Convert(decodedGIF.Image[1].At(1, 1)), Equals, color.Color(color.NRGBA{G: 255, A: 255}))
+	_, _, _, alpha := decodedGIF.Image[2].At(1, 1).RGBA()
+	checker.Assert(alpha, Equals, uint32(0))
+}
+
+func (suite *AnimatedGIFSuite) TestShortDelaysAreAtLeastOneHundredth(checker *C) {
+	var buffer bytes.Buffer
+	err := encoder.EncodeAnimatedGIF(&buffer, suite.frames[:1], time.Millisecond, nil)
+	checker.Assert(err, IsNil)
+
+	decodedGIF, err := gif.DecodeAll(&buffer)
+	checker.Assert(err, IsNil)
+	checker.Assert(decodedGIF.Delay, DeepEquals, []int{1})
+}
+
+func (suite *AnimatedGIFSuite) TestAnimatedGIFNeedsFrames(checker *C) {
+	var buffer bytes.Buffer
+	err := encoder.EncodeAnimatedGIF(&buffer, []image.Image{}, time.Second, nil)
+	checker.Assert(err, ErrorMatches, "an animated gif needs at least one frame")
+}
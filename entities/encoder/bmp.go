@@ -0,0 +1,69 @@
+package encoder
+
+import (
+	"bufio"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+)
+
+// BMP header sizes, in bytes.
+const (
+	bmpFileHeaderSize = 14
+	// bmpInfoHeaderSize is the size of a BITMAPV4HEADER, the smallest header that can describe an alpha channel.
+	bmpInfoHeaderSize = 108
+	bmpBytesPerPixel  = 4
+)
+
+// encodeBMP writes the image as an uncompressed 32 bit BMP, with 8 bits each for blue, green, red and alpha.
+//   Rows are written from the bottom up, which every BMP reader understands.
+func encodeBMP(writer io.Writer, outputImage image.Image) error {
+	bounds := outputImage.Bounds()
+	pixelDataSize := bounds.Dx() * bounds.Dy() * bmpBytesPerPixel
+
+	header := make([]byte, bmpFileHeaderSize+bmpInfoHeaderSize)
+	header[0], header[1] = 'B', 'M'
+	binary.LittleEndian.PutUint32(header[2:], uint32(len(header)+pixelDataSize))
+	binary.LittleEndian.PutUint32(header[10:], uint32(len(header)))
+
+	infoHeader := header[bmpFileHeaderSize:]
+	binary.LittleEndian.PutUint32(infoHeader[0:], bmpInfoHeaderSize)
+	binary.LittleEndian.PutUint32(infoHeader[4:], uint32(bounds.Dx()))
+	binary.LittleEndian.PutUint32(infoHeader[8:], uint32(bounds.Dy()))
+	binary.LittleEndian.PutUint16(infoHeader[12:], 1)
+	binary.LittleEndian.PutUint16(infoHeader[14:], bmpBytesPerPixel*8)
+	// Compression 3 (BI_BITFIELDS) means the channel masks below say where each channel is.
+	binary.LittleEndian.PutUint32(infoHeader[16:], 3)
+	binary.LittleEndian.PutUint32(infoHeader[20:], uint32(pixelDataSize))
+	// 2835 pixels per meter is 72 pixels per inch.
+	binary.LittleEndian.PutUint32(infoHeader[24:], 2835)
+	binary.LittleEndian.PutUint32(infoHeader[28:], 2835)
+	binary.LittleEndian.PutUint32(infoHeader[40:], 0x00ff0000)
+	binary.LittleEndian.PutUint32(infoHeader[44:], 0x0000ff00)
+	binary.LittleEndian.PutUint32(infoHeader[48:], 0x000000ff)
+	binary.LittleEndian.PutUint32(infoHeader[52:], 0xff000000)
+	// The color space is sRGB.
+	copy(infoHeader[56:], []byte("BGRs"))
+
+	bufferedWriter := bufio.NewWriter(writer)
+	if _, err := bufferedWriter.Write(header); err != nil {
+		return err
+	}
+
+	row := make([]byte, bounds.Dx()*bmpBytesPerPixel)
+	for y := bounds.Max.Y - 1; y >= bounds.Min.Y; y-- {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			pixelColor := color.NRGBAModel.Convert(outputImage.At(x, y)).(color.NRGBA)
+			offset := (x - bounds.Min.X) * bmpBytesPerPixel
+			row[offset] = pixelColor.B
+			row[offset+1] = pixelColor.G
+			row[offset+2] = pixelColor.R
+			row[offset+3] = pixelColor.A
+		}
+		if _, err := bufferedWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	return bufferedWriter.Flush()
+}
@@ -0,0 +1,50 @@
+package encoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	. "gopkg.in/check.v1"
+	"image"
+	"image/color"
+	"wallpaper/entities/encoder"
+)
+
+type BMPSuite struct {
+}
+
+var _ = Suite(&BMPSuite{})
+
+func (suite *BMPSuite) TestHeaderDescribesTheImage(checker *C) {
+	outputImage := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	encoded := &bytes.Buffer{}
+	checker.Assert(encoder.Encode(encoded, outputImage, encoder.BMP, nil), IsNil)
+	data := encoded.Bytes()
+
+	checker.Assert(string(data[0:2]), Equals, "BM")
+	checker.Assert(binary.LittleEndian.Uint32(data[2:]), Equals, uint32(len(data)))
+	checker.Assert(binary.LittleEndian.Uint32(data[10:]), Equals, uint32(122))
+	checker.Assert(binary.LittleEndian.Uint32(data[18:]), Equals, uint32(3))
+	checker.Assert(binary.LittleEndian.Uint32(data[22:]), Equals, uint32(2))
+	checker.Assert(binary.LittleEndian.Uint16(data[28:]), Equals, uint16(32))
+	checker.Assert(len(data), Equals, 122+3*2*4)
+}
+
+func (suite *BMPSuite) TestPixelsAreWrittenBottomUpAsBGRA(checker *C) {
+	outputImage := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	outputImage.SetNRGBA(0, 0, color.NRGBA{R: 1, G: 2, B: 3, A: 4})
+	outputImage.SetNRGBA(1, 1, color.NRGBA{R: 5, G: 6, B: 7, A: 255})
+	encoded := &bytes.Buffer{}
+	checker.Assert(encoder.Encode(encoded, outputImage, encoder.BMP, nil), IsNil)
+	pixels := encoded.Bytes()[122:]
+
+	checker.Assert(pixels[4:8], DeepEquals, []byte{7, 6, 5, 255})
+	checker.Assert(pixels[8:12], DeepEquals, []byte{3, 2, 1, 4})
+}
+
+func (suite *BMPSuite) TestSixteenBitImagesKeepTheTopBits(checker *C) {
+	outputImage := image.NewNRGBA64(image.Rect(0, 0, 1, 1))
+	outputImage.SetNRGBA64(0, 0, color.NRGBA64{R: 0x12ff, G: 0x3400, B: 0x5680, A: 0xffff})
+	encoded := &bytes.Buffer{}
+	checker.Assert(encoder.Encode(encoded, outputImage, encoder.BMP, nil), IsNil)
+	checker.Assert(encoded.Bytes()[122:], DeepEquals, []byte{0x56, 0x34, 0x12, 0xff})
+}
@@ -0,0 +1,121 @@
+package encoder
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Format is an image file format the output image can be written in.
+type Format string
+
+// Formats.
+const (
+	PNG  Format = "png"
+	JPEG Format = "jpeg"
+	GIF  Format = "gif"
+	BMP  Format = "bmp"
+	TIFF Format = "tiff"
+)
+
+var formatsByExtension = map[string]Format{
+	".png":  PNG,
+	".jpg":  JPEG,
+	".jpeg": JPEG,
+	".gif":  GIF,
+	".bmp":  BMP,
+	".tif":  TIFF,
+	".tiff": TIFF,
+}
+
+// FormatForFilename picks the format based on the filename's extension.
+func FormatForFilename(filename string) (Format, error) {
+	format, found := formatsByExtension[strings.ToLower(filepath.Ext(filename))]
+	if !found {
+		return "", fmt.Errorf(`cannot tell the image format of %s, use a .png, .jpg, .jpeg, .gif, .bmp, .tif or .tiff extension`, filename)
+	}
+	return format, nil
+}
+
+// Default encoding options.
+const (
+	DefaultJPEGQuality = 90
+	DefaultGIFColors   = 256
+)
+
+// Options change how some formats are encoded. Formats ignore the options they do not use.
+type Options struct {
+	// JPEGQuality ranges from 1 (smallest files) to 100 (best looking). 0 uses DefaultJPEGQuality.
+	JPEGQuality int `json:"jpeg_quality,omitempty" yaml:"jpeg_quality,omitempty"`
+	// GIFColors is the most colors a GIF palette can have, from 2 to 256. 0 uses DefaultGIFColors.
+	//   One of them is used for transparent pixels, if there are any.
+	GIFColors int `json:"gif_colors,omitempty" yaml:"gif_colors,omitempty"`
+	// GIFDither spreads the difference between each pixel and its palette color to its neighbors,
+	//   so gradients look smooth instead of banded.
+	GIFDither bool `json:"gif_dither,omitempty" yaml:"gif_dither,omitempty"`
+}
+
+// Validate returns an error if the options are out of range.
+func (options *Options) Validate() error {
+	if options.JPEGQuality != 0 && (options.JPEGQuality < 1 || options.JPEGQuality > 100) {
+		return fmt.Errorf(`jpeg_quality must be from 1 to 100: %d`, options.JPEGQuality)
+	}
+	if options.GIFColors != 0 && (options.GIFColors < 2 || options.GIFColors > 256) {
+		return fmt.Errorf(`gif_colors must be from 2 to 256: %d`, options.GIFColors)
+	}
+	return nil
+}
+
+func (options *Options) jpegQuality() int {
+	if options == nil || options.JPEGQuality == 0 {
+		return DefaultJPEGQuality
+	}
+	return options.JPEGQuality
+}
+
+func (options *Options) gifColors() int {
+	if options == nil || options.GIFColors == 0 {
+		return DefaultGIFColors
+	}
+	return options.GIFColors
+}
+
+// GIFOptions returns the options the gif package needs to encode a single frame.
+//   Palettes are chosen with a MedianCutQuantizer.
+func (options *Options) GIFOptions() *gif.Options {
+	var drawer draw.Drawer = draw.Src
+	if options != nil && options.GIFDither {
+		drawer = draw.FloydSteinberg
+	}
+	return &gif.Options{
+		NumColors: options.gifColors(),
+		Quantizer: &MedianCutQuantizer{},
+		Drawer:    drawer,
+	}
+}
+
+// Encode writes the image to writer in the given format.
+//   options may be nil to use the defaults.
+//   JPEG files cannot store transparency, so transparent pixels become black.
+//   BMP files store 8 bits per channel. PNG and TIFF files store 16 bits per channel for 16 bit images.
+func Encode(writer io.Writer, outputImage image.Image, format Format, options *Options) error {
+	switch format {
+	case PNG:
+		return png.Encode(writer, outputImage)
+	case JPEG:
+		return jpeg.Encode(writer, outputImage, &jpeg.Options{Quality: options.jpegQuality()})
+	case GIF:
+		return gif.Encode(writer, outputImage, options.GIFOptions())
+	case BMP:
+		return encodeBMP(writer, outputImage)
+	case TIFF:
+		return encodeTIFF(writer, outputImage)
+	}
+	return fmt.Errorf(`unknown image format: %s`, format)
+}
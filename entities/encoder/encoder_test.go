@@ -0,0 +1,133 @@
+package encoder_test
+
+import (
+	"bytes"
+	"errors"
+	. "gopkg.in/check.v1"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+	"wallpaper/entities/encoder"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type EncoderSuite struct {
+	outputImage *image.NRGBA
+}
+
+var _ = Suite(&EncoderSuite{})
+
+func (suite *EncoderSuite) SetUpTest(checker *C) {
+	suite.outputImage = image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	suite.outputImage.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	suite.outputImage.SetNRGBA(1, 0, color.NRGBA{G: 255, A: 255})
+	suite.outputImage.SetNRGBA(2, 0, color.NRGBA{B: 255, A: 255})
+	suite.outputImage.SetNRGBA(0, 1, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	suite.outputImage.SetNRGBA(1, 1, color.NRGBA{A: 255})
+}
+
+func (suite *EncoderSuite) TestFormatForFilename(checker *C) {
+	for filename, expectedFormat := range map[string]encoder.Format{
+		"pattern.png":      encoder.PNG,
+		"pattern.JPG":      encoder.JPEG,
+		"out/pattern.jpeg": encoder.JPEG,
+		"pattern.gif":      encoder.GIF,
+		"pattern.bmp":      encoder.BMP,
+		"pattern.tif":      encoder.TIFF,
+		"pattern.tiff":     encoder.TIFF,
+	} {
+		format, err := encoder.FormatForFilename(filename)
+		checker.Assert(err, IsNil)
+		checker.Assert(format, Equals, expectedFormat)
+	}
+}
+
+func (suite *EncoderSuite) TestFormatForUnknownExtension(checker *C) {
+	_, err := encoder.FormatForFilename("pattern.webp")
+	checker.Assert(err, ErrorMatches, "cannot tell the image format of pattern.webp, .*")
+}
+
+func (suite *EncoderSuite) TestValidate(checker *C) {
+	checker.Assert((&encoder.Options{}).Validate(), IsNil)
+	checker.Assert((&encoder.Options{JPEGQuality: 101}).Validate(), ErrorMatches, "jpeg_quality must be from 1 to 100: 101")
+	checker.Assert((&encoder.Options{GIFColors: 1}).Validate(), ErrorMatches, "gif_colors must be from 2 to 256: 1")
+}
+
+func (suite *EncoderSuite) TestPNGKeepsEveryPixel(checker *C) {
+	encoded := &bytes.Buffer{}
+	checker.Assert(encoder.Encode(encoded, suite.outputImage, encoder.PNG, nil), IsNil)
+
+	decodedImage, err := png.Decode(encoded)
+	checker.Assert(err, IsNil)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			checker.Assert(color.NRGBAModel.Convert(decodedImage.At(x, y)), Equals, suite.outputImage.At(x, y))
+		}
+	}
+}
+
+func (suite *EncoderSuite) TestJPEGQualityChangesTheFileSize(checker *C) {
+	noisyImage := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for index := range noisyImage.Pix {
+		noisyImage.Pix[index] = uint8(index * 37 % 251)
+	}
+
+	lowQuality := &bytes.Buffer{}
+	checker.Assert(encoder.Encode(lowQuality, noisyImage, encoder.JPEG, &encoder.Options{JPEGQuality: 10}), IsNil)
+	highQuality := &bytes.Buffer{}
+	checker.Assert(encoder.Encode(highQuality, noisyImage, encoder.JPEG, &encoder.Options{JPEGQuality: 95}), IsNil)
+	checker.Assert(lowQuality.Len() < highQuality.Len(), Equals, true)
+
+	_, err := jpeg.Decode(highQuality)
+	checker.Assert(err, IsNil)
+}
+
+func (suite *EncoderSuite) TestGIFUsesAtMostGIFColors(checker *C) {
+	encoded := &bytes.Buffer{}
+	checker.Assert(encoder.Encode(encoded, suite.outputImage, encoder.GIF, &encoder.Options{GIFColors: 5}), IsNil)
+
+	decodedImage, err := gif.Decode(encoded)
+	checker.Assert(err, IsNil)
+	colorsUsed := map[color.Color]bool{}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			colorsUsed[decodedImage.At(x, y)] = true
+		}
+	}
+	checker.Assert(len(colorsUsed) <= 5, Equals, true)
+}
+
+func (suite *EncoderSuite) TestGIFKeepsExactColorsWhenTheyFit(checker *C) {
+	suite.outputImage.SetNRGBA(2, 1, color.NRGBA{R: 10, G: 20, B: 30, A: 255})
+	encoded := &bytes.Buffer{}
+	checker.Assert(encoder.Encode(encoded, suite.outputImage, encoder.GIF, &encoder.Options{GIFDither: true}), IsNil)
+
+	decodedImage, err := gif.Decode(encoded)
+	checker.Assert(err, IsNil)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			checker.Assert(color.NRGBAModel.Convert(decodedImage.At(x, y)), Equals, suite.outputImage.At(x, y))
+		}
+	}
+}
+
+func (suite *EncoderSuite) TestUnknownFormat(checker *C) {
+	err := encoder.Encode(&bytes.Buffer{}, suite.outputImage, "webp", nil)
+	checker.Assert(err, ErrorMatches, "unknown image format: webp")
+}
+
+type failingWriter struct{}
+
+func (writer failingWriter) Write(data []byte) (int, error) {
+	return 0, errors.New("disk is full")
+}
+
+func (suite *EncoderSuite) TestEncodingErrorsAreReturned(checker *C) {
+	for _, format := range []encoder.Format{encoder.PNG, encoder.JPEG, encoder.GIF, encoder.BMP, encoder.TIFF} {
+		checker.Assert(encoder.Encode(failingWriter{}, suite.outputImage, format, nil), ErrorMatches, "disk is full")
+	}
+}
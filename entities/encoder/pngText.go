@@ -0,0 +1,244 @@
+package encoder
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"unicode/utf8"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// TextChunk is a keyword and text stored in a PNG file.
+type TextChunk struct {
+	Keyword string
+	Text    string
+}
+
+// EncodePNGWithText writes the image as a PNG, followed by the text chunks.
+//   Text written only with Latin-1 characters is stored in tEXt chunks.
+//   Other text is stored in compressed iTXt chunks, which can hold any UTF-8.
+func EncodePNGWithText(writer io.Writer, outputImage image.Image, textChunks []TextChunk) error {
+	encodedImage := &bytes.Buffer{}
+	if err := png.Encode(encodedImage, outputImage); err != nil {
+		return err
+	}
+	pngData := encodedImage.Bytes()
+
+	// Text chunks go right before IEND, the last chunk.
+	const iendChunkLength = 12
+	imageChunks := pngData[:len(pngData)-iendChunkLength]
+	if _, err := writer.Write(imageChunks); err != nil {
+		return err
+	}
+	for _, textChunk := range textChunks {
+		chunkType, chunkData, err := textChunk.encode()
+		if err != nil {
+			return err
+		}
+		if err := writePNGChunk(writer, chunkType, chunkData); err != nil {
+			return err
+		}
+	}
+	_, err := writer.Write(pngData[len(pngData)-iendChunkLength:])
+	return err
+}
+
+// encode returns the chunk type and data for the text chunk.
+func (textChunk TextChunk) encode() (string, []byte, error) {
+	if len(textChunk.Keyword) < 1 || len(textChunk.Keyword) > 79 {
+		return "", nil, fmt.Errorf(`png text keywords must have 1 to 79 characters: %q`, textChunk.Keyword)
+	}
+
+	chunkData := &bytes.Buffer{}
+	chunkData.WriteString(textChunk.Keyword)
+	chunkData.WriteByte(0)
+
+	if isLatin1(textChunk.Text) {
+		for _, character := range textChunk.Text {
+			chunkData.WriteByte(byte(character))
+		}
+		return "tEXt", chunkData.Bytes(), nil
+	}
+
+	// Compression flag and method, then empty language and translated keyword.
+	chunkData.Write([]byte{1, 0, 0, 0})
+	compressor := zlib.NewWriter(chunkData)
+	if _, err := compressor.Write([]byte(textChunk.Text)); err != nil {
+		return "", nil, err
+	}
+	if err := compressor.Close(); err != nil {
+		return "", nil, err
+	}
+	return "iTXt", chunkData.Bytes(), nil
+}
+
+// isLatin1 returns true if every character can be stored in a tEXt chunk.
+func isLatin1(text string) bool {
+	for _, character := range text {
+		if character > 0xff {
+			return false
+		}
+	}
+	return true
+}
+
+func writePNGChunk(writer io.Writer, chunkType string, chunkData []byte) error {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(len(chunkData)))
+	copy(header[4:], chunkType)
+
+	checksum := crc32.NewIEEE()
+	checksum.Write(header[4:])
+	checksum.Write(chunkData)
+	footer := make([]byte, 4)
+	binary.BigEndian.PutUint32(footer, checksum.Sum32())
+
+	for _, part := range [][]byte{header, chunkData, footer} {
+		if _, err := writer.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maximumPNGChunkLength is the longest chunk the PNG specification allows.
+const maximumPNGChunkLength = 1<<31 - 1
+
+// maximumTextChunkLength limits how much memory one text chunk can take. Embedded formulas are far shorter.
+const maximumTextChunkLength = 16 << 20
+
+// isTextChunkType returns true for the chunk types ReadPNGText decodes.
+func isTextChunkType(chunkType string) bool {
+	return chunkType == "tEXt" || chunkType == "zTXt" || chunkType == "iTXt"
+}
+
+// ReadPNGText returns the tEXt, zTXt and iTXt chunks of a PNG file, in the order they appear.
+func ReadPNGText(reader io.Reader) ([]TextChunk, error) {
+	signature := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(reader, signature); err != nil || !bytes.Equal(signature, pngSignature) {
+		return nil, errors.New(`not a png file`)
+	}
+
+	textChunks := []TextChunk{}
+	header := make([]byte, 8)
+	storedChecksum := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return nil, fmt.Errorf(`png file ends too soon: %v`, err)
+		}
+		chunkLength := binary.BigEndian.Uint32(header)
+		chunkType := string(header[4:])
+		if chunkLength > maximumPNGChunkLength {
+			return nil, fmt.Errorf(`png %s chunk is too long: %d bytes`, chunkType, chunkLength)
+		}
+
+		checksum := crc32.NewIEEE()
+		checksum.Write(header[4:])
+		var chunkData []byte
+		if isTextChunkType(chunkType) {
+			if chunkLength > maximumTextChunkLength {
+				return nil, fmt.Errorf(`png %s chunk is too long: %d bytes`, chunkType, chunkLength)
+			}
+			chunkData = make([]byte, chunkLength)
+			if _, err := io.ReadFull(reader, chunkData); err != nil {
+				return nil, fmt.Errorf(`png file ends too soon: %v`, err)
+			}
+			checksum.Write(chunkData)
+		} else if _, err := io.CopyN(checksum, reader, int64(chunkLength)); err != nil {
+			// Other chunks are only checked, so they are never held in memory.
+			return nil, fmt.Errorf(`png file ends too soon: %v`, err)
+		}
+
+		if _, err := io.ReadFull(reader, storedChecksum); err != nil {
+			return nil, fmt.Errorf(`png file ends too soon: %v`, err)
+		}
+		if checksum.Sum32() != binary.BigEndian.Uint32(storedChecksum) {
+			return nil, fmt.Errorf(`png %s chunk is corrupt`, chunkType)
+		}
+
+		if chunkType == "IEND" {
+			return textChunks, nil
+		}
+		if isTextChunkType(chunkType) {
+			textChunk, err := decodeTextChunk(chunkType, chunkData)
+			if err != nil {
+				return nil, err
+			}
+			textChunks = append(textChunks, textChunk)
+		}
+	}
+}
+
+func decodeTextChunk(chunkType string, chunkData []byte) (TextChunk, error) {
+	keywordEnd := bytes.IndexByte(chunkData, 0)
+	if keywordEnd < 0 {
+		return TextChunk{}, fmt.Errorf(`png %s chunk has no keyword`, chunkType)
+	}
+	keyword := latin1ToUTF8(chunkData[:keywordEnd])
+	rest := chunkData[keywordEnd+1:]
+
+	switch chunkType {
+	case "tEXt":
+		return TextChunk{Keyword: keyword, Text: latin1ToUTF8(rest)}, nil
+	case "zTXt":
+		if len(rest) < 1 {
+			return TextChunk{}, errors.New(`png zTXt chunk is too short`)
+		}
+		text, err := decompress(rest[1:])
+		if err != nil {
+			return TextChunk{}, err
+		}
+		return TextChunk{Keyword: keyword, Text: latin1ToUTF8(text)}, nil
+	}
+
+	if len(rest) < 2 {
+		return TextChunk{}, errors.New(`png iTXt chunk is too short`)
+	}
+	compressed := rest[0] == 1
+	rest = rest[2:]
+	for field := 0; field < 2; field++ {
+		fieldEnd := bytes.IndexByte(rest, 0)
+		if fieldEnd < 0 {
+			return TextChunk{}, errors.New(`png iTXt chunk is too short`)
+		}
+		rest = rest[fieldEnd+1:]
+	}
+
+	text := rest
+	if compressed {
+		var err error
+		text, err = decompress(rest)
+		if err != nil {
+			return TextChunk{}, err
+		}
+	}
+	if !utf8.Valid(text) {
+		return TextChunk{}, fmt.Errorf(`png iTXt chunk %s is not UTF-8`, keyword)
+	}
+	return TextChunk{Keyword: keyword, Text: string(text)}, nil
+}
+
+func decompress(compressedData []byte) ([]byte, error) {
+	decompressor, err := zlib.NewReader(bytes.NewReader(compressedData))
+	if err != nil {
+		return nil, err
+	}
+	defer decompressor.Close()
+	return ioutil.ReadAll(decompressor)
+}
+
+func latin1ToUTF8(latin1 []byte) string {
+	characters := make([]rune, len(latin1))
+	for index, character := range latin1 {
+		characters[index] = rune(character)
+	}
+	return string(characters)
+}
@@ -0,0 +1,137 @@
+package encoder_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	. "gopkg.in/check.v1"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"wallpaper/entities/encoder"
+)
+
+type PNGTextSuite struct {
+	outputImage *image.NRGBA
+}
+
+var _ = Suite(&PNGTextSuite{})
+
+func (suite *PNGTextSuite) SetUpTest(checker *C) {
+	suite.outputImage = image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	suite.outputImage.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+}
+
+func (suite *PNGTextSuite) TestTextCanBeReadBack(checker *C) {
+	textChunks := []encoder.TextChunk{
+		{Keyword: "Software", Text: "wallpaper 1.0"},
+		{Keyword: "Comment", Text: "café"},
+		{Keyword: "Title", Text: "形 → pattern\nsecond line"},
+	}
+	encoded := &bytes.Buffer{}
+	checker.Assert(encoder.EncodePNGWithText(encoded, suite.outputImage, textChunks), IsNil)
+
+	readTextChunks, err := encoder.ReadPNGText(bytes.NewReader(encoded.Bytes()))
+	checker.Assert(err, IsNil)
+	checker.Assert(readTextChunks, DeepEquals, textChunks)
+}
+
+func (suite *PNGTextSuite) TestTheImageIsUnchanged(checker *C) {
+	encoded := &bytes.Buffer{}
+	checker.Assert(encoder.EncodePNGWithText(encoded, suite.outputImage, []encoder.TextChunk{{Keyword: "Comment", Text: "hello"}}), IsNil)
+
+	decodedImage, err := png.Decode(encoded)
+	checker.Assert(err, IsNil)
+	checker.Assert(decodedImage.At(0, 0), Equals, color.Color(color.NRGBA{R: 255, A: 255}))
+	checker.Assert(decodedImage.At(1, 0), Equals, color.Color(color.NRGBA{}))
+}
+
+func (suite *PNGTextSuite) TestPNGsWithoutTextHaveNoChunks(checker *C) {
+	encoded := &bytes.Buffer{}
+	checker.Assert(png.Encode(encoded, suite.outputImage), IsNil)
+
+	textChunks, err := encoder.ReadPNGText(encoded)
+	checker.Assert(err, IsNil)
+	checker.Assert(textChunks, HasLen, 0)
+}
+
+func (suite *PNGTextSuite) TestKeywordsMustFit(checker *C) {
+	err := encoder.EncodePNGWithText(&bytes.Buffer{}, suite.outputImage, []encoder.TextChunk{{Keyword: "", Text: "hello"}})
+	checker.Assert(err, ErrorMatches, `png text keywords must have 1 to 79 characters: ""`)
+}
+
+func (suite *PNGTextSuite) TestOtherFilesAreNotPNGs(checker *C) {
+	_, err := encoder.ReadPNGText(bytes.NewReader([]byte("GIF89a")))
+	checker.Assert(err, ErrorMatches, "not a png file")
+}
+
+func (suite *PNGTextSuite) TestCorruptChunksAreReported(checker *C) {
+	encoded := &bytes.Buffer{}
+	checker.Assert(encoder.EncodePNGWithText(encoded, suite.outputImage, []encoder.TextChunk{{Keyword: "Comment", Text: "hello"}}), IsNil)
+	corrupted := encoded.Bytes()
+	textStart := bytes.Index(corrupted, []byte("hello"))
+	corrupted[textStart] = 'j'
+
+	_, err := encoder.ReadPNGText(bytes.NewReader(corrupted))
+	checker.Assert(err, ErrorMatches, "png tEXt chunk is corrupt")
+}
+
+// pngChunk returns a whole PNG chunk, with its length and checksum.
+func pngChunk(chunkType string, chunkData []byte) []byte {
+	chunk := make([]byte, 8, 12+len(chunkData))
+	binary.BigEndian.PutUint32(chunk, uint32(len(chunkData)))
+	copy(chunk[4:], chunkType)
+	chunk = append(chunk, chunkData...)
+	checksum := make([]byte, 4)
+	binary.BigEndian.PutUint32(checksum, crc32.ChecksumIEEE(chunk[4:]))
+	return append(chunk, checksum...)
+}
+
+func (suite *PNGTextSuite) TestCompressedChunksWrittenByOtherTools(checker *C) {
+	compressedText := &bytes.Buffer{}
+	compressor := zlib.NewWriter(compressedText)
+	compressor.Write([]byte("squeezed"))
+	compressor.Close()
+
+	pngData := []byte("\x89PNG\r\n\x1a\n")
+	pngData = append(pngData, pngChunk("zTXt", append([]byte("Comment\x00\x00"), compressedText.Bytes()...))...)
+	pngData = append(pngData, pngChunk("iTXt", []byte("Title\x00\x00\x00en\x00Titel\x00plain"))...)
+	pngData = append(pngData, pngChunk("IEND", nil)...)
+
+	textChunks, err := encoder.ReadPNGText(bytes.NewReader(pngData))
+	checker.Assert(err, IsNil)
+	checker.Assert(textChunks, DeepEquals, []encoder.TextChunk{
+		{Keyword: "Comment", Text: "squeezed"},
+		{Keyword: "Title", Text: "plain"},
+	})
+}
+
+func (suite *PNGTextSuite) TestOverlongChunksAreRejectedBeforeReading(checker *C) {
+	pngData := []byte("\x89PNG\r\n\x1a\n")
+	pngData = append(pngData, 0xff, 0xff, 0xff, 0xf0)
+	pngData = append(pngData, "IDAT"...)
+	_, err := encoder.ReadPNGText(bytes.NewReader(pngData))
+	checker.Assert(err, ErrorMatches, "png IDAT chunk is too long: 4294967280 bytes")
+
+	pngData = []byte("\x89PNG\r\n\x1a\n")
+	pngData = append(pngData, 0x10, 0, 0, 0)
+	pngData = append(pngData, "tEXt"...)
+	_, err = encoder.ReadPNGText(bytes.NewReader(pngData))
+	checker.Assert(err, ErrorMatches, "png tEXt chunk is too long: 268435456 bytes")
+}
+
+func (suite *PNGTextSuite) TestLongImageChunksAreSkipped(checker *C) {
+	pngData := []byte("\x89PNG\r\n\x1a\n")
+	pngData = append(pngData, pngChunk("IDAT", make([]byte, 1<<20))...)
+	pngData = append(pngData, pngChunk("tEXt", []byte("Comment\x00after the image"))...)
+	pngData = append(pngData, pngChunk("IEND", nil)...)
+
+	textChunks, err := encoder.ReadPNGText(bytes.NewReader(pngData))
+	checker.Assert(err, IsNil)
+	checker.Assert(textChunks, DeepEquals, []encoder.TextChunk{{Keyword: "Comment", Text: "after the image"}})
+
+	truncated := pngData[:len(pngData)/2]
+	_, err = encoder.ReadPNGText(bytes.NewReader(truncated))
+	checker.Assert(err, ErrorMatches, "png file ends too soon: .*")
+}
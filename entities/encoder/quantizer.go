@@ -0,0 +1,161 @@
+package encoder
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// MedianCutQuantizer picks a palette that fits the colors of an image.
+//   It repeatedly splits the group of colors with the widest range in half, so each palette color
+//   stands for about the same number of pixels.
+//   Mostly transparent pixels get their own transparent palette color.
+type MedianCutQuantizer struct {
+}
+
+// colorCount is one color of the image, and how many pixels use it.
+type colorCount struct {
+	channels [3]uint8
+	pixels   int
+}
+
+// colorBox is a group of colors that will share one palette color.
+type colorBox struct {
+	colors []colorCount
+}
+
+// Quantize adds up to cap(palette)-len(palette) colors to palette, and returns it.
+func (quantizer *MedianCutQuantizer) Quantize(palette color.Palette, sourceImage image.Image) color.Palette {
+	numberOfColors := cap(palette) - len(palette)
+	if numberOfColors <= 0 {
+		return palette
+	}
+
+	pixelsByColor := map[[3]uint8]int{}
+	hasTransparentPixels := false
+	bounds := sourceImage.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			pixelColor := color.NRGBAModel.Convert(sourceImage.At(x, y)).(color.NRGBA)
+			if pixelColor.A < 0x80 {
+				hasTransparentPixels = true
+				continue
+			}
+			pixelsByColor[[3]uint8{pixelColor.R, pixelColor.G, pixelColor.B}]++
+		}
+	}
+
+	if hasTransparentPixels {
+		palette = append(palette, color.RGBA{R: 0, G: 0, B: 0, A: 0})
+		numberOfColors--
+	}
+	if len(pixelsByColor) == 0 || numberOfColors <= 0 {
+		return palette
+	}
+
+	allColors := make([]colorCount, 0, len(pixelsByColor))
+	for channels, pixels := range pixelsByColor {
+		allColors = append(allColors, colorCount{channels: channels, pixels: pixels})
+	}
+	sort.Slice(allColors, func(i, j int) bool {
+		return lessChannels(allColors[i].channels, allColors[j].channels)
+	})
+
+	boxes := []colorBox{{colors: allColors}}
+	for len(boxes) < numberOfColors {
+		boxIndex, channel := widestBox(boxes)
+		if boxIndex < 0 {
+			break
+		}
+		firstHalf, secondHalf := boxes[boxIndex].splitAtMedian(channel)
+		boxes[boxIndex] = firstHalf
+		boxes = append(boxes, secondHalf)
+	}
+
+	for _, box := range boxes {
+		palette = append(palette, box.averageColor())
+	}
+	return palette
+}
+
+func lessChannels(first, second [3]uint8) bool {
+	for channel := range first {
+		if first[channel] != second[channel] {
+			return first[channel] < second[channel]
+		}
+	}
+	return false
+}
+
+// widestBox returns the box with the widest range in any channel, and that channel.
+//   It returns -1 if every box has only one color.
+func widestBox(boxes []colorBox) (int, int) {
+	widestIndex := -1
+	widestChannel := 0
+	widestRange := 0
+	for boxIndex, box := range boxes {
+		if len(box.colors) < 2 {
+			continue
+		}
+		for channel := 0; channel < 3; channel++ {
+			channelRange := box.channelRange(channel)
+			if channelRange > widestRange {
+				widestIndex, widestChannel, widestRange = boxIndex, channel, channelRange
+			}
+		}
+	}
+	return widestIndex, widestChannel
+}
+
+func (box colorBox) channelRange(channel int) int {
+	minimum, maximum := 255, 0
+	for _, count := range box.colors {
+		value := int(count.channels[channel])
+		if value < minimum {
+			minimum = value
+		}
+		if value > maximum {
+			maximum = value
+		}
+	}
+	return maximum - minimum
+}
+
+// splitAtMedian sorts the colors by the channel, and splits them so each half covers about half of the pixels.
+//   Both halves have at least one color.
+func (box colorBox) splitAtMedian(channel int) (colorBox, colorBox) {
+	sort.SliceStable(box.colors, func(i, j int) bool {
+		return box.colors[i].channels[channel] < box.colors[j].channels[channel]
+	})
+
+	totalPixels := 0
+	for _, count := range box.colors {
+		totalPixels += count.pixels
+	}
+
+	splitIndex := 1
+	pixelsBeforeSplit := box.colors[0].pixels
+	for splitIndex < len(box.colors)-1 && pixelsBeforeSplit*2 < totalPixels {
+		pixelsBeforeSplit += box.colors[splitIndex].pixels
+		splitIndex++
+	}
+	return colorBox{colors: box.colors[:splitIndex]}, colorBox{colors: box.colors[splitIndex:]}
+}
+
+// averageColor mixes the box's colors, weighted by the number of pixels that use them.
+func (box colorBox) averageColor() color.Color {
+	var totals [3]int
+	totalPixels := 0
+	for _, count := range box.colors {
+		for channel, value := range count.channels {
+			totals[channel] += int(value) * count.pixels
+		}
+		totalPixels += count.pixels
+	}
+	return color.RGBA{
+		R: uint8((totals[0] + totalPixels/2) / totalPixels),
+		G: uint8((totals[1] + totalPixels/2) / totalPixels),
+		B: uint8((totals[2] + totalPixels/2) / totalPixels),
+		A: 255,
+	}
+}
@@ -0,0 +1,57 @@
+package encoder_test
+
+import (
+	. "gopkg.in/check.v1"
+	"image"
+	"image/color"
+	"wallpaper/entities/encoder"
+)
+
+type MedianCutQuantizerSuite struct {
+}
+
+var _ = Suite(&MedianCutQuantizerSuite{})
+
+func (suite *MedianCutQuantizerSuite) TestFewColorsAreKeptExactly(checker *C) {
+	sourceImage := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	sourceImage.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	sourceImage.SetNRGBA(1, 0, color.NRGBA{G: 255, A: 255})
+	sourceImage.SetNRGBA(2, 0, color.NRGBA{G: 255, A: 255})
+
+	palette := (&encoder.MedianCutQuantizer{}).Quantize(make(color.Palette, 0, 16), sourceImage)
+	checker.Assert(palette, HasLen, 2)
+	checker.Assert(palette.Index(color.NRGBA{R: 255, A: 255}) != palette.Index(color.NRGBA{G: 255, A: 255}), Equals, true)
+	checker.Assert(palette.Convert(color.NRGBA{R: 255, A: 255}), Equals, color.Color(color.RGBA{R: 255, A: 255}))
+}
+
+func (suite *MedianCutQuantizerSuite) TestPaletteStaysWithinItsCapacity(checker *C) {
+	gradient := image.NewNRGBA(image.Rect(0, 0, 256, 1))
+	for x := 0; x < 256; x++ {
+		gradient.SetNRGBA(x, 0, color.NRGBA{R: uint8(x), G: uint8(255 - x), A: 255})
+	}
+
+	palette := (&encoder.MedianCutQuantizer{}).Quantize(make(color.Palette, 0, 4), gradient)
+	checker.Assert(palette, HasLen, 4)
+	checker.Assert(palette.Convert(color.NRGBA{R: 0, G: 255, A: 255}).(color.RGBA).R < 64, Equals, true)
+	checker.Assert(palette.Convert(color.NRGBA{R: 255, G: 0, A: 255}).(color.RGBA).R > 192, Equals, true)
+}
+
+func (suite *MedianCutQuantizerSuite) TestTransparentPixelsGetTheirOwnColor(checker *C) {
+	sourceImage := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	sourceImage.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+
+	palette := (&encoder.MedianCutQuantizer{}).Quantize(make(color.Palette, 0, 2), sourceImage)
+	checker.Assert(palette, HasLen, 2)
+	checker.Assert(palette.Convert(color.NRGBA{}), Equals, color.Color(color.RGBA{}))
+}
+
+func (suite *MedianCutQuantizerSuite) TestPalettesAreRepeatable(checker *C) {
+	noisyImage := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	for index := range noisyImage.Pix {
+		noisyImage.Pix[index] = uint8(index*37%251) | 0x0f
+	}
+
+	firstPalette := (&encoder.MedianCutQuantizer{}).Quantize(make(color.Palette, 0, 16), noisyImage)
+	secondPalette := (&encoder.MedianCutQuantizer{}).Quantize(make(color.Palette, 0, 16), noisyImage)
+	checker.Assert(secondPalette, DeepEquals, firstPalette)
+}
@@ -0,0 +1,125 @@
+package encoder
+
+import (
+	"bufio"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+)
+
+// TIFF field types.
+const (
+	tiffShort    = 3
+	tiffLong     = 4
+	tiffRational = 5
+)
+
+// tiffField is one entry of a TIFF image file directory.
+type tiffField struct {
+	tag       uint16
+	fieldType uint16
+	count     uint32
+	// value holds the value itself when it fits in 4 bytes, or the offset of the value when it does not.
+	value uint32
+}
+
+// encodeTIFF writes the image as an uncompressed little endian TIFF, with red, green, blue and (unassociated) alpha.
+//   16 bit images are written with 16 bits per channel. Other images use 8 bits.
+func encodeTIFF(writer io.Writer, outputImage image.Image) error {
+	bounds := outputImage.Bounds()
+	bitsPerSample := 8
+	if _, isSixteenBit := outputImage.(*image.NRGBA64); isSixteenBit {
+		bitsPerSample = 16
+	}
+	bytesPerPixel := 4 * bitsPerSample / 8
+	pixelDataSize := bounds.Dx() * bounds.Dy() * bytesPerPixel
+
+	const numberOfFields = 14
+	const headerSize = 8
+	const directorySize = 2 + numberOfFields*12 + 4
+	const bitsPerSampleOffset = headerSize + directorySize
+	const xResolutionOffset = bitsPerSampleOffset + 8
+	const yResolutionOffset = xResolutionOffset + 8
+	const pixelDataOffset = yResolutionOffset + 8
+
+	fields := []tiffField{
+		{tag: 256, fieldType: tiffLong, count: 1, value: uint32(bounds.Dx())},
+		{tag: 257, fieldType: tiffLong, count: 1, value: uint32(bounds.Dy())},
+		{tag: 258, fieldType: tiffShort, count: 4, value: bitsPerSampleOffset},
+		// Compression: none
+		{tag: 259, fieldType: tiffShort, count: 1, value: 1},
+		// Photometric interpretation: RGB
+		{tag: 262, fieldType: tiffShort, count: 1, value: 2},
+		// Strip offsets: the whole image is one strip.
+		{tag: 273, fieldType: tiffLong, count: 1, value: pixelDataOffset},
+		// Samples per pixel
+		{tag: 277, fieldType: tiffShort, count: 1, value: 4},
+		// Rows per strip
+		{tag: 278, fieldType: tiffLong, count: 1, value: uint32(bounds.Dy())},
+		// Strip byte counts
+		{tag: 279, fieldType: tiffLong, count: 1, value: uint32(pixelDataSize)},
+		{tag: 282, fieldType: tiffRational, count: 1, value: xResolutionOffset},
+		{tag: 283, fieldType: tiffRational, count: 1, value: yResolutionOffset},
+		// Planar configuration: channels are interleaved.
+		{tag: 284, fieldType: tiffShort, count: 1, value: 1},
+		// Resolution unit: inches
+		{tag: 296, fieldType: tiffShort, count: 1, value: 2},
+		// Extra samples: the fourth sample is unassociated alpha.
+		{tag: 338, fieldType: tiffShort, count: 1, value: 2},
+	}
+
+	header := make([]byte, pixelDataOffset)
+	copy(header, []byte("II*\x00"))
+	binary.LittleEndian.PutUint32(header[4:], headerSize)
+
+	binary.LittleEndian.PutUint16(header[headerSize:], numberOfFields)
+	for fieldIndex, field := range fields {
+		entry := header[headerSize+2+fieldIndex*12:]
+		binary.LittleEndian.PutUint16(entry[0:], field.tag)
+		binary.LittleEndian.PutUint16(entry[2:], field.fieldType)
+		binary.LittleEndian.PutUint32(entry[4:], field.count)
+		if field.fieldType == tiffShort && field.count == 1 {
+			binary.LittleEndian.PutUint16(entry[8:], uint16(field.value))
+		} else {
+			binary.LittleEndian.PutUint32(entry[8:], field.value)
+		}
+	}
+
+	for sample := 0; sample < 4; sample++ {
+		binary.LittleEndian.PutUint16(header[bitsPerSampleOffset+sample*2:], uint16(bitsPerSample))
+	}
+	for _, resolutionOffset := range []int{xResolutionOffset, yResolutionOffset} {
+		binary.LittleEndian.PutUint32(header[resolutionOffset:], 72)
+		binary.LittleEndian.PutUint32(header[resolutionOffset+4:], 1)
+	}
+
+	bufferedWriter := bufio.NewWriter(writer)
+	if _, err := bufferedWriter.Write(header); err != nil {
+		return err
+	}
+
+	row := make([]byte, bounds.Dx()*bytesPerPixel)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			offset := (x - bounds.Min.X) * bytesPerPixel
+			if bitsPerSample == 16 {
+				pixelColor := color.NRGBA64Model.Convert(outputImage.At(x, y)).(color.NRGBA64)
+				binary.LittleEndian.PutUint16(row[offset:], pixelColor.R)
+				binary.LittleEndian.PutUint16(row[offset+2:], pixelColor.G)
+				binary.LittleEndian.PutUint16(row[offset+4:], pixelColor.B)
+				binary.LittleEndian.PutUint16(row[offset+6:], pixelColor.A)
+				continue
+			}
+			pixelColor := color.NRGBAModel.Convert(outputImage.At(x, y)).(color.NRGBA)
+			row[offset] = pixelColor.R
+			row[offset+1] = pixelColor.G
+			row[offset+2] = pixelColor.B
+			row[offset+3] = pixelColor.A
+		}
+		if _, err := bufferedWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	return bufferedWriter.Flush()
+}
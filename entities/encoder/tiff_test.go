@@ -0,0 +1,67 @@
+package encoder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	. "gopkg.in/check.v1"
+	"image"
+	"image/color"
+	"wallpaper/entities/encoder"
+)
+
+type TIFFSuite struct {
+}
+
+var _ = Suite(&TIFFSuite{})
+
+// readTIFFFields returns the first value of each field in the TIFF's first image file directory.
+//   Fields with more than one value return the offset of their values.
+func readTIFFFields(data []byte) map[uint16]uint32 {
+	directoryOffset := binary.LittleEndian.Uint32(data[4:])
+	numberOfFields := int(binary.LittleEndian.Uint16(data[directoryOffset:]))
+	fields := map[uint16]uint32{}
+	for fieldIndex := 0; fieldIndex < numberOfFields; fieldIndex++ {
+		entry := data[int(directoryOffset)+2+fieldIndex*12:]
+		if binary.LittleEndian.Uint16(entry[2:]) == 3 && binary.LittleEndian.Uint32(entry[4:]) == 1 {
+			fields[binary.LittleEndian.Uint16(entry[0:])] = uint32(binary.LittleEndian.Uint16(entry[8:]))
+			continue
+		}
+		fields[binary.LittleEndian.Uint16(entry[0:])] = binary.LittleEndian.Uint32(entry[8:])
+	}
+	return fields
+}
+
+func (suite *TIFFSuite) TestEightBitImage(checker *C) {
+	outputImage := image.NewNRGBA(image.Rect(0, 0, 2, 3))
+	outputImage.SetNRGBA(1, 2, color.NRGBA{R: 1, G: 2, B: 3, A: 4})
+	encoded := &bytes.Buffer{}
+	checker.Assert(encoder.Encode(encoded, outputImage, encoder.TIFF, nil), IsNil)
+	data := encoded.Bytes()
+
+	checker.Assert(string(data[0:4]), Equals, "II*\x00")
+	fields := readTIFFFields(data)
+	checker.Assert(fields[256], Equals, uint32(2))
+	checker.Assert(fields[257], Equals, uint32(3))
+	checker.Assert(binary.LittleEndian.Uint16(data[fields[258]:]), Equals, uint16(8))
+	checker.Assert(fields[259], Equals, uint32(1))
+	checker.Assert(fields[277], Equals, uint32(4))
+	checker.Assert(fields[279], Equals, uint32(2*3*4))
+	checker.Assert(fields[338], Equals, uint32(2))
+
+	pixels := data[fields[273]:]
+	checker.Assert(len(pixels), Equals, 2*3*4)
+	checker.Assert(pixels[len(pixels)-4:], DeepEquals, []byte{1, 2, 3, 4})
+}
+
+func (suite *TIFFSuite) TestSixteenBitImage(checker *C) {
+	outputImage := image.NewNRGBA64(image.Rect(0, 0, 1, 1))
+	outputImage.SetNRGBA64(0, 0, color.NRGBA64{R: 0x1234, G: 0x5678, B: 0x9abc, A: 0xffff})
+	encoded := &bytes.Buffer{}
+	checker.Assert(encoder.Encode(encoded, outputImage, encoder.TIFF, nil), IsNil)
+	data := encoded.Bytes()
+
+	fields := readTIFFFields(data)
+	checker.Assert(binary.LittleEndian.Uint16(data[fields[258]+6:]), Equals, uint16(16))
+	checker.Assert(fields[279], Equals, uint32(8))
+	checker.Assert(data[fields[273]:], DeepEquals, []byte{0x34, 0x12, 0x78, 0x56, 0xbc, 0x9a, 0xff, 0xff})
+}
@@ -138,3 +138,28 @@ const (
 	MinusNPlusMNegateMultiplierIfOddPowerSum  Relationship = "-N+MF(N+M)"
 )
 
+
+// knownRelationships lists every Relationship that GenerateCoefficientSets understands.
+var knownRelationships = map[Relationship]bool{
+	PlusNPlusM:                                true,
+	PlusMPlusN:                                true,
+	MinusNMinusM:                              true,
+	MinusMMinusN:                              true,
+	PlusMPlusNNegateMultiplierIfOddPowerSum:   true,
+	MinusMMinusNNegateMultiplierIfOddPowerSum: true,
+	PlusMMinusSumNAndM:                        true,
+	MinusSumNAndMPlusN:                        true,
+	PlusMMinusN:                               true,
+	MinusMPlusN:                               true,
+	PlusNMinusM:                               true,
+	PlusNMinusMNegateMultiplierIfOddPowerN:    true,
+	MinusNPlusMNegateMultiplierIfOddPowerN:    true,
+	MinusNPlusM:                               true,
+	PlusNMinusMNegateMultiplierIfOddPowerSum:  true,
+	MinusNPlusMNegateMultiplierIfOddPowerSum:  true,
+}
+
+// IsKnown returns true if the relationship is one of the defined Relationship constants.
+func (relationship Relationship) IsKnown() bool {
+	return knownRelationships[relationship]
+}
@@ -263,3 +263,12 @@ func (suite *CoefficientPairFeatures) TestMinusNPlusMNegateMultiplierIfOddPowerS
 	checker.Assert(newSetsWithOddSumPower[0].NegateMultiplier, Equals, true)
 }
 
+
+func (suite *CoefficientPairFeatures) TestDefinedRelationshipsAreKnown(checker *C) {
+	checker.Assert(coefficient.PlusNPlusM.IsKnown(), Equals, true)
+	checker.Assert(coefficient.MinusMMinusNNegateMultiplierIfOddPowerSum.IsKnown(), Equals, true)
+}
+
+func (suite *CoefficientPairFeatures) TestMisspelledRelationshipIsNotKnown(checker *C) {
+	checker.Assert(coefficient.Relationship("-M-NF").IsKnown(), Equals, false)
+}
@@ -70,6 +70,14 @@ func NewEisensteinFormulaTermFromMarshalObject(marshalObject EisensteinFormulaTe
 	}
 }
 
+// MarshalObject converts the term into an object that can be marshaled.
+func (term EisensteinFormulaTerm) MarshalObject() *EisensteinFormulaTermMarshal {
+	return &EisensteinFormulaTermMarshal{
+		PowerN: term.PowerN,
+		PowerM: term.PowerM,
+	}
+}
+
 // GetAllPossibleTermRelationships returns a list of relationships that all of the terms conform to.
 func GetAllPossibleTermRelationships(
 	term1, term2 *EisensteinFormulaTerm,
@@ -0,0 +1,96 @@
+package exponential
+
+import (
+	"fmt"
+)
+
+// termPresence notes which of two aligned lists a term came from.
+type termPresence int
+
+const (
+	inBothLists termPresence = iota
+	onlyInFromList
+	onlyInToList
+)
+
+// termMatchKey identifies terms that can be blended into each other.
+//   Terms match when they have the same powers, complex conjugate setting and coefficient relationships.
+func termMatchKey(term *RosetteFriezeTerm) string {
+	return fmt.Sprintf("%d %d %t %v", term.PowerN, term.PowerM, term.IgnoreComplexConjugate, term.CoefficientRelationships)
+}
+
+// AlignTerms lines up two lists of terms so they can be blended.
+//   Both returned lists hold every term in the same order: the from terms first, then the to terms without a match.
+//   A term missing from one list is copied into it with a multiplier of 0.
+//   If several terms match, they are paired in the order they appear.
+//   The given terms are not modified.
+func AlignTerms(fromTerms, toTerms []*RosetteFriezeTerm) ([]*RosetteFriezeTerm, []*RosetteFriezeTerm) {
+	alignedFrom, alignedTo, _ := alignTerms(fromTerms, toTerms)
+	return alignedFrom, alignedTo
+}
+
+// BlendTerms interpolates the multipliers of matching terms.
+//   A ratio of 0 returns the from terms and 1 returns the to terms.
+//   Terms without a match fade in or out, and are left out where their multiplier would be 0.
+func BlendTerms(fromTerms, toTerms []*RosetteFriezeTerm, ratio float64) []*RosetteFriezeTerm {
+	alignedFrom, alignedTo, presence := alignTerms(fromTerms, toTerms)
+
+	blendedTerms := []*RosetteFriezeTerm{}
+	for index, fromTerm := range alignedFrom {
+		if (presence[index] == onlyInToList && ratio == 0) || (presence[index] == onlyInFromList && ratio == 1) {
+			continue
+		}
+
+		blendedTerm := *fromTerm
+		blendedTerm.Multiplier = fromTerm.Multiplier + (alignedTo[index].Multiplier-fromTerm.Multiplier)*complex(ratio, 0)
+		blendedTerms = append(blendedTerms, &blendedTerm)
+	}
+	return blendedTerms
+}
+
+// alignTerms lines up the terms like AlignTerms, and notes where each aligned term came from.
+func alignTerms(fromTerms, toTerms []*RosetteFriezeTerm) ([]*RosetteFriezeTerm, []*RosetteFriezeTerm, []termPresence) {
+	unmatchedToIndicesByKey := map[string][]int{}
+	for toIndex, toTerm := range toTerms {
+		key := termMatchKey(toTerm)
+		unmatchedToIndicesByKey[key] = append(unmatchedToIndicesByKey[key], toIndex)
+	}
+
+	alignedFrom := []*RosetteFriezeTerm{}
+	alignedTo := []*RosetteFriezeTerm{}
+	presence := []termPresence{}
+	toTermIsMatched := make([]bool, len(toTerms))
+	for _, fromTerm := range fromTerms {
+		copiedFromTerm := *fromTerm
+		alignedFrom = append(alignedFrom, &copiedFromTerm)
+
+		key := termMatchKey(fromTerm)
+		if toIndices := unmatchedToIndicesByKey[key]; len(toIndices) > 0 {
+			unmatchedToIndicesByKey[key] = toIndices[1:]
+			toTermIsMatched[toIndices[0]] = true
+			copiedToTerm := *toTerms[toIndices[0]]
+			alignedTo = append(alignedTo, &copiedToTerm)
+			presence = append(presence, inBothLists)
+			continue
+		}
+
+		fadedTerm := *fromTerm
+		fadedTerm.Multiplier = 0
+		alignedTo = append(alignedTo, &fadedTerm)
+		presence = append(presence, onlyInFromList)
+	}
+
+	for toIndex, toTerm := range toTerms {
+		if toTermIsMatched[toIndex] {
+			continue
+		}
+		copiedToTerm := *toTerm
+		alignedTo = append(alignedTo, &copiedToTerm)
+
+		fadedTerm := *toTerm
+		fadedTerm.Multiplier = 0
+		alignedFrom = append(alignedFrom, &fadedTerm)
+		presence = append(presence, onlyInToList)
+	}
+	return alignedFrom, alignedTo, presence
+}
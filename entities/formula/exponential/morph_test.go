@@ -0,0 +1,70 @@
+package exponential_test
+
+import (
+	. "gopkg.in/check.v1"
+	"wallpaper/entities/formula/coefficient"
+	"wallpaper/entities/formula/exponential"
+)
+
+type MorphTermsSuite struct {
+	fromTerms []*exponential.RosetteFriezeTerm
+	toTerms   []*exponential.RosetteFriezeTerm
+}
+
+var _ = Suite(&MorphTermsSuite{})
+
+func (suite *MorphTermsSuite) SetUpTest(checker *C) {
+	suite.fromTerms = []*exponential.RosetteFriezeTerm{
+		{Multiplier: complex(2, 0), PowerN: 1, PowerM: 0},
+		{Multiplier: complex(1, 1), PowerN: 3, PowerM: -1},
+	}
+	suite.toTerms = []*exponential.RosetteFriezeTerm{
+		{Multiplier: complex(0, 4), PowerN: 5, PowerM: 0},
+		{Multiplier: complex(4, 0), PowerN: 1, PowerM: 0},
+	}
+}
+
+func (suite *MorphTermsSuite) TestAlignTermsMatchesPowers(checker *C) {
+	alignedFrom, alignedTo := exponential.AlignTerms(suite.fromTerms, suite.toTerms)
+
+	checker.Assert(alignedFrom, DeepEquals, []*exponential.RosetteFriezeTerm{
+		{Multiplier: complex(2, 0), PowerN: 1, PowerM: 0},
+		{Multiplier: complex(1, 1), PowerN: 3, PowerM: -1},
+		{Multiplier: complex(0, 0), PowerN: 5, PowerM: 0},
+	})
+	checker.Assert(alignedTo, DeepEquals, []*exponential.RosetteFriezeTerm{
+		{Multiplier: complex(4, 0), PowerN: 1, PowerM: 0},
+		{Multiplier: complex(0, 0), PowerN: 3, PowerM: -1},
+		{Multiplier: complex(0, 4), PowerN: 5, PowerM: 0},
+	})
+}
+
+func (suite *MorphTermsSuite) TestAlignTermsDoesNotModifyTheTerms(checker *C) {
+	alignedFrom, _ := exponential.AlignTerms(suite.fromTerms, suite.toTerms)
+	alignedFrom[0].Multiplier = complex(100, 0)
+	checker.Assert(suite.fromTerms[0].Multiplier, Equals, complex(2, 0))
+}
+
+func (suite *MorphTermsSuite) TestTermsWithDifferentRelationshipsDoNotMatch(checker *C) {
+	suite.toTerms[1].CoefficientRelationships = []coefficient.Relationship{coefficient.MinusNMinusM}
+	alignedFrom, _ := exponential.AlignTerms(suite.fromTerms, suite.toTerms)
+	checker.Assert(alignedFrom, HasLen, 4)
+}
+
+func (suite *MorphTermsSuite) TestBlendTermsInterpolatesMultipliers(checker *C) {
+	blendedTerms := exponential.BlendTerms(suite.fromTerms, suite.toTerms, 0.25)
+
+	checker.Assert(blendedTerms, DeepEquals, []*exponential.RosetteFriezeTerm{
+		{Multiplier: complex(2.5, 0), PowerN: 1, PowerM: 0},
+		{Multiplier: complex(0.75, 0.75), PowerN: 3, PowerM: -1},
+		{Multiplier: complex(0, 1), PowerN: 5, PowerM: 0},
+	})
+}
+
+func (suite *MorphTermsSuite) TestBlendTermsLeavesOutFadedTermsAtTheEnds(checker *C) {
+	checker.Assert(exponential.BlendTerms(suite.fromTerms, suite.toTerms, 0), DeepEquals, suite.fromTerms)
+	checker.Assert(exponential.BlendTerms(suite.fromTerms, suite.toTerms, 1), DeepEquals, []*exponential.RosetteFriezeTerm{
+		{Multiplier: complex(4, 0), PowerN: 1, PowerM: 0},
+		{Multiplier: complex(0, 4), PowerN: 5, PowerM: 0},
+	})
+}
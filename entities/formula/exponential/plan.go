@@ -0,0 +1,78 @@
+package exponential
+
+import (
+	"wallpaper/entities/formula/coefficient"
+	"wallpaper/entities/formula/result"
+)
+
+// PlanEntry is one locked coefficient set, expanded from a RosetteFriezeTerm.
+type PlanEntry struct {
+	// TermIndex is the position of the term this entry was expanded from.
+	TermIndex              int
+	PowerN                 int
+	PowerM                 int
+	Multiplier             complex128
+	IgnoreComplexConjugate bool
+}
+
+// EvaluationPlan is a flat list of every coefficient set a group of terms expands into.
+//   Building it once lets formulas skip GenerateCoefficientSets for every point they calculate.
+type EvaluationPlan struct {
+	// Entries are grouped by term, in the same order as the terms.
+	Entries       []PlanEntry
+	NumberOfTerms int
+}
+
+// NewEvaluationPlan expands each term and its CoefficientRelationships into PlanEntries.
+//   Every term includes itself (+N+M) before the relationships it lists.
+func NewEvaluationPlan(terms []*RosetteFriezeTerm) *EvaluationPlan {
+	plan := &EvaluationPlan{
+		Entries:       []PlanEntry{},
+		NumberOfTerms: len(terms),
+	}
+
+	for termIndex, term := range terms {
+		coefficientRelationships := []coefficient.Relationship{coefficient.PlusNPlusM}
+		coefficientRelationships = append(coefficientRelationships, term.CoefficientRelationships...)
+		coefficientSets := coefficient.Pairing{
+			PowerN: term.PowerN,
+			PowerM: term.PowerM,
+		}.GenerateCoefficientSets(coefficientRelationships)
+
+		for _, relationshipSet := range coefficientSets {
+			multiplier := term.Multiplier
+			if relationshipSet.NegateMultiplier == true {
+				multiplier *= -1
+			}
+			plan.Entries = append(plan.Entries, PlanEntry{
+				TermIndex:              termIndex,
+				PowerN:                 relationshipSet.PowerN,
+				PowerM:                 relationshipSet.PowerM,
+				Multiplier:             multiplier,
+				IgnoreComplexConjugate: term.IgnoreComplexConjugate,
+			})
+		}
+	}
+	return plan
+}
+
+// EntryCalculator calculates the value of a single PlanEntry at z.
+type EntryCalculator func(z complex128, powerN, powerM int, multiplier complex128, ignoreComplexConjugate bool) complex128
+
+// Calculate adds up every entry at z using calculateEntry.
+//   Contributions are reported per term, not per entry.
+func (plan *EvaluationPlan) Calculate(z complex128, calculateEntry EntryCalculator) *result.CalculationResultForFormula {
+	calculationResult := &result.CalculationResultForFormula{
+		Total:              complex(0, 0),
+		ContributionByTerm: make([]complex128, plan.NumberOfTerms),
+	}
+
+	for _, entry := range plan.Entries {
+		calculationResult.ContributionByTerm[entry.TermIndex] += calculateEntry(z, entry.PowerN, entry.PowerM, entry.Multiplier, entry.IgnoreComplexConjugate)
+	}
+
+	for _, termResult := range calculationResult.ContributionByTerm {
+		calculationResult.Total += termResult
+	}
+	return calculationResult
+}
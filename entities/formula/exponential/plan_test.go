@@ -0,0 +1,57 @@
+package exponential_test
+
+import (
+	. "gopkg.in/check.v1"
+	"wallpaper/entities/formula/coefficient"
+	"wallpaper/entities/formula/exponential"
+)
+
+type EvaluationPlanSuite struct {
+	terms []*exponential.RosetteFriezeTerm
+}
+
+var _ = Suite(&EvaluationPlanSuite{})
+
+func (suite *EvaluationPlanSuite) SetUpTest(checker *C) {
+	suite.terms = []*exponential.RosetteFriezeTerm{
+		{
+			Multiplier: complex(2, 1),
+			PowerN:     1,
+			PowerM:     2,
+			CoefficientRelationships: []coefficient.Relationship{
+				coefficient.MinusNMinusM,
+				coefficient.PlusMPlusNNegateMultiplierIfOddPowerSum,
+			},
+		},
+		{
+			Multiplier:             complex(-1, 0),
+			PowerN:                 4,
+			PowerM:                 0,
+			IgnoreComplexConjugate: true,
+		},
+	}
+}
+
+func (suite *EvaluationPlanSuite) TestPlanExpandsEveryRelationship(checker *C) {
+	plan := exponential.NewEvaluationPlan(suite.terms)
+
+	checker.Assert(plan.NumberOfTerms, Equals, 2)
+	checker.Assert(plan.Entries, DeepEquals, []exponential.PlanEntry{
+		{TermIndex: 0, PowerN: 1, PowerM: 2, Multiplier: complex(2, 1)},
+		{TermIndex: 0, PowerN: -1, PowerM: -2, Multiplier: complex(2, 1)},
+		{TermIndex: 0, PowerN: 2, PowerM: 1, Multiplier: complex(-2, -1)},
+		{TermIndex: 1, PowerN: 4, PowerM: 0, Multiplier: complex(-1, 0), IgnoreComplexConjugate: true},
+	})
+}
+
+func (suite *EvaluationPlanSuite) TestCalculateReportsContributionsByTerm(checker *C) {
+	plan := exponential.NewEvaluationPlan(suite.terms)
+	sumOfPowersTimesMultiplier := func(z complex128, powerN, powerM int, multiplier complex128, ignoreComplexConjugate bool) complex128 {
+		return complex(float64(powerN+powerM), 0) * multiplier
+	}
+
+	calculation := plan.Calculate(complex(0, 0), sumOfPowersTimesMultiplier)
+
+	checker.Assert(calculation.ContributionByTerm, DeepEquals, []complex128{complex(-6, -3), complex(-4, 0)})
+	checker.Assert(calculation.Total, Equals, complex(-10, -3))
+}
@@ -2,6 +2,7 @@ package exponential
 
 import (
 	"encoding/json"
+	"fmt"
 	"gopkg.in/yaml.v2"
 	"wallpaper/entities/formula/coefficient"
 	"wallpaper/entities/utility"
@@ -13,7 +14,7 @@ type TermMarshalable struct {
 	PowerN						int								`json:"power_n" yaml:"power_n"`
 	PowerM						int								`json:"power_m" yaml:"power_m"`
 	IgnoreComplexConjugate		bool							`json:"ignore_complex_conjugate" yaml:"ignore_complex_conjugate"`
-	CoefficientRelationships	[]coefficient.Relationship		`json:"coefficient_relationships" yaml:"coefficient_relationships"`
+	CoefficientRelationships	[]coefficient.Relationship		`json:"coefficient_relationships,omitempty" yaml:"coefficient_relationships,omitempty"`
 }
 
 // RosetteFriezeTerm is used in Friezes and Rosettes, applying different calculations to them.
@@ -63,3 +64,24 @@ func NewTermFromMarshalObject(marshalObject TermMarshalable) *RosetteFriezeTerm
 		CoefficientRelationships:	marshalObject.CoefficientRelationships,
 	}
 }
+
+// MarshalObject converts the term into an object that can be marshaled.
+func (term *RosetteFriezeTerm) MarshalObject() *TermMarshalable {
+	return &TermMarshalable{
+		Multiplier:               utility.ComplexNumberForMarshal{Real: real(term.Multiplier), Imaginary: imag(term.Multiplier)},
+		PowerN:                   term.PowerN,
+		PowerM:                   term.PowerM,
+		IgnoreComplexConjugate:   term.IgnoreComplexConjugate,
+		CoefficientRelationships: term.CoefficientRelationships,
+	}
+}
+
+// Validate returns an error if the term uses a coefficient relationship that cannot be generated.
+func (term *RosetteFriezeTerm) Validate() error {
+	for _, relationship := range term.CoefficientRelationships {
+		if !relationship.IsKnown() {
+			return fmt.Errorf(`unknown coefficient relationship: %s`, relationship)
+		}
+	}
+	return nil
+}
@@ -63,3 +63,22 @@ func (suite *ExponentialTerm) TestCreateTermFromJSON(checker *C) {
 	checker.Assert(term.CoefficientRelationships[0], Equals, coefficient.Relationship(coefficient.MinusMMinusN))
 	checker.Assert(term.CoefficientRelationships[1], Equals, coefficient.Relationship(coefficient.PlusMPlusNNegateMultiplierIfOddPowerSum))
 }
+
+func (suite *ExponentialTerm) TestValidTermHasNoErrors(checker *C) {
+	term := exponential.RosetteFriezeTerm{
+		PowerN:                   3,
+		PowerM:                   0,
+		CoefficientRelationships: []coefficient.Relationship{coefficient.MinusMMinusN},
+	}
+	checker.Assert(term.Validate(), IsNil)
+}
+
+func (suite *ExponentialTerm) TestTermWithUnknownRelationshipIsInvalid(checker *C) {
+	term := exponential.RosetteFriezeTerm{
+		PowerN:                   3,
+		PowerM:                   0,
+		CoefficientRelationships: []coefficient.Relationship{"+M+NF"},
+	}
+	err := term.Validate()
+	checker.Assert(err, ErrorMatches, "unknown coefficient relationship: \\+M\\+NF")
+}
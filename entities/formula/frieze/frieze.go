@@ -1,7 +1,9 @@
 package frieze
 
 import (
+	"errors"
 	"gopkg.in/yaml.v2"
+	"math"
 	"math/cmplx"
 	"wallpaper/entities/formula/coefficient"
 	"wallpaper/entities/formula/exponential"
@@ -9,45 +11,49 @@ import (
 	"wallpaper/entities/utility"
 )
 
+// Period is the distance along the real axis after which every frieze repeats.
+//   Each term raises e to an integer multiple of iz, so moving z by 2π leaves every term unchanged.
+const Period = 2 * math.Pi
+
 // Formula is used to generate frieze patterns.
 type Formula struct {
 	Terms []*exponential.RosetteFriezeTerm
 }
 
 // Calculate applies the Frieze formula to the complex number z.
+//   To calculate many points, Compile the formula once and use the CompiledFormula instead.
 func (friezeFormula Formula) Calculate(z complex128) *result.CalculationResultForFormula {
-	result := &result.CalculationResultForFormula{
-		Total: complex(0,0),
-		ContributionByTerm: []complex128{},
-	}
-
-	for _, term := range friezeFormula.Terms {
-		termResult := friezeFormula.calculateTerm(term, z)
-		result.Total += termResult
-		result.ContributionByTerm = append(result.ContributionByTerm, termResult)
-	}
+	return friezeFormula.Compile().Calculate(z)
+}
 
-	return result
+// CompiledFormula calculates a Formula using an EvaluationPlan built once,
+//   instead of expanding the coefficient relationships for every point.
+type CompiledFormula struct {
+	plan *exponential.EvaluationPlan
 }
 
-func (friezeFormula *Formula) calculateTerm(term *exponential.RosetteFriezeTerm, z complex128) complex128 {
-	sum := complex(0.0,0.0)
+// Compile expands the formula's terms into a CompiledFormula.
+//   Changes to the formula's terms after compiling are not seen by the CompiledFormula.
+func (friezeFormula Formula) Compile() *CompiledFormula {
+	return &CompiledFormula{plan: exponential.NewEvaluationPlan(friezeFormula.Terms)}
+}
 
-	coefficientRelationships := []coefficient.Relationship{coefficient.PlusNPlusM}
-	coefficientRelationships = append(coefficientRelationships, term.CoefficientRelationships...)
-	coefficientSets := coefficient.Pairing{
-		PowerN:     term.PowerN,
-		PowerM:     term.PowerM,
-	}.GenerateCoefficientSets(coefficientRelationships)
+// Calculate applies the compiled formula to the complex number z.
+func (compiledFormula *CompiledFormula) Calculate(z complex128) *result.CalculationResultForFormula {
+	return compiledFormula.plan.Calculate(z, CalculateEulerTerm)
+}
 
-	for _, relationshipSet := range coefficientSets {
-		multiplier := term.Multiplier
-		if relationshipSet.NegateMultiplier == true {
-			multiplier *= -1
+// Validate returns an error if the formula cannot be calculated.
+func (friezeFormula Formula) Validate() error {
+	if len(friezeFormula.Terms) == 0 {
+		return errors.New(`formula must have at least one term`)
+	}
+	for _, term := range friezeFormula.Terms {
+		if termErr := term.Validate(); termErr != nil {
+			return termErr
 		}
-		sum += CalculateEulerTerm(z, relationshipSet.PowerN, relationshipSet.PowerM, multiplier, term.IgnoreComplexConjugate)
 	}
-	return sum
+	return nil
 }
 
 // Symmetry notes the kinds of symmetries the formula contains.
@@ -179,3 +185,26 @@ func NewFriezeFormulaFromMarshalObject(marshalObject MarshaledFormula) *Formula
 	}
 	return &Formula{Terms: terms}
 }
+
+// MarshalObject converts the formula into an object that can be marshaled.
+func (formula *Formula) MarshalObject() *MarshaledFormula {
+	termMarshals := []*exponential.TermMarshalable{}
+	for _, term := range formula.Terms {
+		termMarshals = append(termMarshals, term.MarshalObject())
+	}
+	return &MarshaledFormula{Terms: termMarshals}
+}
+
+// Morph blends two frieze formulas. A ratio of 0 returns the from formula and 1 returns the to formula.
+//   Terms with the same powers have their multipliers interpolated, and the other terms fade in or out.
+func Morph(from, to *Formula, ratio float64) *Formula {
+	return &Formula{Terms: exponential.BlendTerms(from.Terms, to.Terms, ratio)}
+}
+
+// AlignForMorph returns copies of both formulas with the same terms in the same order.
+//   Terms found in only one formula are added to the other with a multiplier of 0.
+//   Results of the copies can be blended point by point, and their contributions by term line up.
+func AlignForMorph(from, to *Formula) (*Formula, *Formula) {
+	alignedFrom, alignedTo := exponential.AlignTerms(from.Terms, to.Terms)
+	return &Formula{Terms: alignedFrom}, &Formula{Terms: alignedTo}
+}
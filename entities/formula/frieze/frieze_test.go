@@ -294,3 +294,59 @@ func (suite *FriezeFormulaSuite) TestCreateFriezeFormulaWithJSON(checker *C) {
 	checker.Assert(rosetteFormula.Terms[0].IgnoreComplexConjugate, Equals, false)
 	checker.Assert(rosetteFormula.Terms[1].CoefficientRelationships[0], Equals, coefficient.Relationship(coefficient.MinusMMinusNNegateMultiplierIfOddPowerSum))
 }
+
+func (suite *FriezeFormulaSuite) TestFormulaWithoutTermsIsInvalid(checker *C) {
+	friezeFormula := frieze.Formula{Terms: []*exponential.RosetteFriezeTerm{}}
+	checker.Assert(friezeFormula.Validate(), ErrorMatches, "formula must have at least one term")
+}
+
+func (suite *FriezeFormulaSuite) TestFormulaWithKnownRelationshipsIsValid(checker *C) {
+	friezeFormula := frieze.Formula{
+		Terms: []*exponential.RosetteFriezeTerm{
+			{
+				Multiplier:               complex(1, 0),
+				PowerN:                   3,
+				PowerM:                   2,
+				CoefficientRelationships: []coefficient.Relationship{coefficient.MinusNMinusM},
+			},
+		},
+	}
+	checker.Assert(friezeFormula.Validate(), IsNil)
+}
+
+func (suite *FriezeFormulaSuite) TestCompiledFormulaMatchesFormula(checker *C) {
+	friezeFormula := frieze.Formula{
+		Terms: []*exponential.RosetteFriezeTerm{
+			{
+				Multiplier: complex(-1, 2e-2),
+				PowerN:     3,
+				PowerM:     2,
+				CoefficientRelationships: []coefficient.Relationship{
+					coefficient.MinusNMinusM,
+					coefficient.MinusMMinusNNegateMultiplierIfOddPowerSum,
+					coefficient.PlusMPlusNNegateMultiplierIfOddPowerSum,
+				},
+			},
+		},
+	}
+	compiledFormula := friezeFormula.Compile()
+
+	for _, z := range []complex128{complex(0.3, -0.2), complex(-6, 0.8), complex(math.Pi, 0)} {
+		checker.Assert(compiledFormula.Calculate(z), DeepEquals, friezeFormula.Calculate(z))
+	}
+}
+
+func (suite *FriezeFormulaSuite) TestMorphFadesInUnmatchedTerms(checker *C) {
+	from := &frieze.Formula{Terms: []*exponential.RosetteFriezeTerm{
+		{Multiplier: complex(1, 0), PowerN: 1, PowerM: 1},
+	}}
+	to := &frieze.Formula{Terms: []*exponential.RosetteFriezeTerm{
+		{Multiplier: complex(0, 2), PowerN: 2, PowerM: -2},
+	}}
+
+	blendedFormula := frieze.Morph(from, to, 0.5)
+	checker.Assert(blendedFormula.Terms, DeepEquals, []*exponential.RosetteFriezeTerm{
+		{Multiplier: complex(0.5, 0), PowerN: 1, PowerM: 1},
+		{Multiplier: complex(0, 1), PowerN: 2, PowerM: -2},
+	})
+}
@@ -76,3 +76,8 @@ func (lattice *Pair) ConvertToLatticeCoordinates(cartesianPoint complex128) comp
 
 	return complex(scalarForVector1, scalarForVector2)
 }
+
+// ConvertToCartesianCoordinates converts a point from lattice coordinates back to cartesian coordinates.
+func (lattice *Pair) ConvertToCartesianCoordinates(latticePoint complex128) complex128 {
+	return complex(real(latticePoint), 0)*lattice.XLatticeVector + complex(imag(latticePoint), 0)*lattice.YLatticeVector
+}
@@ -72,3 +72,14 @@ func (suite *LatticeVectorSuite) TestConvertToLatticeVectorEvenIfFirstVectorHasZ
 	checker.Assert(real(latticeCoordinate), utility.NumericallyCloseEnough{}, 2.0, 1e-6)
 	checker.Assert(imag(latticeCoordinate), utility.NumericallyCloseEnough{}, 1.0, 1e-6)
 }
+
+func (suite *LatticeVectorSuite) TestConvertToCartesianUndoesConvertToLattice(checker *C) {
+	rhombicLattice := latticevector.Pair{
+		XLatticeVector: complex(0.5, 1),
+		YLatticeVector: complex(0.5, -1),
+	}
+
+	cartesianPoint := rhombicLattice.ConvertToCartesianCoordinates(complex(0.625, 0.875))
+	checker.Assert(real(cartesianPoint), utility.NumericallyCloseEnough{}, 0.75, 1e-6)
+	checker.Assert(imag(cartesianPoint), utility.NumericallyCloseEnough{}, -0.25, 1e-6)
+}
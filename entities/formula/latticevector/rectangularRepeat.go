@@ -0,0 +1,112 @@
+package latticevector
+
+import (
+	"errors"
+	"math"
+)
+
+// maximumRepeatMultiple limits how many copies of each lattice vector are combined while looking for a repeat.
+const maximumRepeatMultiple = 64
+
+// RectangularRepeat describes how a pattern built on the lattice repeats along the real and imaginary axes.
+type RectangularRepeat struct {
+	// Width and Height are the sides of the smallest rectangle that repeats in a straight grid.
+	//   Moving the pattern by Width along the real axis, or by Height along the imaginary axis, leaves it unchanged.
+	//   For hexagonal and rhombic lattices this rectangle holds more than one lattice cell.
+	Width  float64
+	Height float64
+	// DropWidth is the narrowest column that repeats, when each column is moved DropOffset along the imaginary axis.
+	//   DropOffset is from 0 up to Height. It is Height / 2 for a half-drop repeat, and 0 when the columns line up.
+	DropWidth  float64
+	DropOffset float64
+	// BrickHeight is the shortest row that repeats, when each row is moved BrickOffset along the real axis.
+	//   BrickOffset is from 0 up to Width. It is Width / 2 for a half-brick repeat, and 0 when the rows line up.
+	BrickHeight float64
+	BrickOffset float64
+}
+
+// RectangularRepeat finds the smallest rectangles of the lattice that repeat along the real and imaginary axes.
+//   Returns an error if no combination of the lattice vectors lies along one of the axes.
+func (lattice *Pair) RectangularRepeat() (*RectangularRepeat, error) {
+	tolerance := 1e-9 * math.Max(vectorLength(lattice.XLatticeVector), vectorLength(lattice.YLatticeVector))
+
+	// Combinations are tried from the fewest vectors to the most, so the simplest one wins a tie.
+	//   Fewer vectors add up fewer rounding errors.
+	repeat := &RectangularRepeat{}
+	for totalMultiple := 1; totalMultiple <= 2*maximumRepeatMultiple; totalMultiple++ {
+		for xMultiple := -totalMultiple; xMultiple <= totalMultiple; xMultiple++ {
+			yMultipleSize := totalMultiple - absoluteValue(xMultiple)
+			if absoluteValue(xMultiple) > maximumRepeatMultiple || yMultipleSize > maximumRepeatMultiple {
+				continue
+			}
+			for _, yMultiple := range []int{-yMultipleSize, yMultipleSize} {
+				vector := complex(float64(xMultiple), 0)*lattice.XLatticeVector + complex(float64(yMultiple), 0)*lattice.YLatticeVector
+				repeat.consider(vector, tolerance)
+				if yMultipleSize == 0 {
+					break
+				}
+			}
+		}
+	}
+
+	if repeat.Width == 0 || repeat.Height == 0 {
+		return nil, errors.New(`the lattice does not repeat along both the real and imaginary axes`)
+	}
+	repeat.DropOffset = wrapOffset(repeat.DropOffset, repeat.Height, tolerance)
+	repeat.BrickOffset = wrapOffset(repeat.BrickOffset, repeat.Width, tolerance)
+	return repeat, nil
+}
+
+// consider keeps the lattice vector if it is shorter than the repeats found so far.
+func (repeat *RectangularRepeat) consider(vector complex128, tolerance float64) {
+	alongRealAxis := math.Abs(imag(vector)) <= tolerance
+	alongImaginaryAxis := math.Abs(real(vector)) <= tolerance
+
+	if alongRealAxis && real(vector) > tolerance && (repeat.Width == 0 || real(vector) < repeat.Width-tolerance) {
+		repeat.Width = real(vector)
+	}
+	if alongImaginaryAxis && imag(vector) > tolerance && (repeat.Height == 0 || imag(vector) < repeat.Height-tolerance) {
+		repeat.Height = imag(vector)
+	}
+	if real(vector) > tolerance && (repeat.DropWidth == 0 || real(vector) < repeat.DropWidth-tolerance) {
+		repeat.DropWidth = real(vector)
+		repeat.DropOffset = imag(vector)
+	}
+	if imag(vector) > tolerance && (repeat.BrickHeight == 0 || imag(vector) < repeat.BrickHeight-tolerance) {
+		repeat.BrickHeight = imag(vector)
+		repeat.BrickOffset = real(vector)
+	}
+}
+
+// IsHalfDrop returns true if each column of the narrowest repeat is moved by half of the rectangle's height.
+func (repeat *RectangularRepeat) IsHalfDrop() bool {
+	return math.Abs(repeat.DropOffset-repeat.Height/2) <= 1e-9*repeat.Height
+}
+
+// IsHalfBrick returns true if each row of the shortest repeat is moved by half of the rectangle's width.
+func (repeat *RectangularRepeat) IsHalfBrick() bool {
+	return math.Abs(repeat.BrickOffset-repeat.Width/2) <= 1e-9*repeat.Width
+}
+
+func absoluteValue(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+func vectorLength(vector complex128) float64 {
+	return math.Hypot(real(vector), imag(vector))
+}
+
+// wrapOffset moves the offset into the range from 0 up to period. Offsets within tolerance of the period become 0.
+func wrapOffset(offset, period, tolerance float64) float64 {
+	offset = math.Mod(offset, period)
+	if offset < 0 {
+		offset += period
+	}
+	if offset <= tolerance || period-offset <= tolerance {
+		return 0
+	}
+	return offset
+}
@@ -0,0 +1,80 @@
+package latticevector_test
+
+import (
+	. "gopkg.in/check.v1"
+	"math"
+	"wallpaper/entities/formula/latticevector"
+	"wallpaper/entities/utility"
+)
+
+type RectangularRepeatSuite struct{}
+
+var _ = Suite(&RectangularRepeatSuite{})
+
+func (suite *RectangularRepeatSuite) TestSquareLatticeRepeatsOnItsOwnCell(checker *C) {
+	squareLattice := latticevector.Pair{
+		XLatticeVector: complex(1, 0),
+		YLatticeVector: complex(0, 1),
+	}
+	repeat, err := squareLattice.RectangularRepeat()
+	checker.Assert(err, IsNil)
+	checker.Assert(repeat.Width, utility.NumericallyCloseEnough{}, 1.0, 1e-6)
+	checker.Assert(repeat.Height, utility.NumericallyCloseEnough{}, 1.0, 1e-6)
+	checker.Assert(repeat.DropWidth, utility.NumericallyCloseEnough{}, 1.0, 1e-6)
+	checker.Assert(repeat.DropOffset, Equals, 0.0)
+	checker.Assert(repeat.BrickOffset, Equals, 0.0)
+	checker.Assert(repeat.IsHalfDrop(), Equals, false)
+}
+
+func (suite *RectangularRepeatSuite) TestHexagonalLatticeUsesASuperCell(checker *C) {
+	hexagonalLattice := latticevector.Pair{
+		XLatticeVector: complex(1, 0),
+		YLatticeVector: complex(-0.5, math.Sqrt(3.0)/2.0),
+	}
+	repeat, err := hexagonalLattice.RectangularRepeat()
+	checker.Assert(err, IsNil)
+	checker.Assert(repeat.Width, utility.NumericallyCloseEnough{}, 1.0, 1e-6)
+	checker.Assert(repeat.Height, utility.NumericallyCloseEnough{}, math.Sqrt(3.0), 1e-6)
+	checker.Assert(repeat.DropWidth, utility.NumericallyCloseEnough{}, 0.5, 1e-6)
+	checker.Assert(repeat.DropOffset, utility.NumericallyCloseEnough{}, math.Sqrt(3.0)/2.0, 1e-6)
+	checker.Assert(repeat.IsHalfDrop(), Equals, true)
+	checker.Assert(repeat.BrickHeight, utility.NumericallyCloseEnough{}, math.Sqrt(3.0)/2.0, 1e-6)
+	checker.Assert(repeat.BrickOffset, utility.NumericallyCloseEnough{}, 0.5, 1e-6)
+	checker.Assert(repeat.IsHalfBrick(), Equals, true)
+}
+
+func (suite *RectangularRepeatSuite) TestRhombicLatticeIsAHalfDrop(checker *C) {
+	rhombicLattice := latticevector.Pair{
+		XLatticeVector: complex(0.5, 0.75),
+		YLatticeVector: complex(0.5, -0.75),
+	}
+	repeat, err := rhombicLattice.RectangularRepeat()
+	checker.Assert(err, IsNil)
+	checker.Assert(repeat.Width, utility.NumericallyCloseEnough{}, 1.0, 1e-6)
+	checker.Assert(repeat.Height, utility.NumericallyCloseEnough{}, 1.5, 1e-6)
+	checker.Assert(repeat.DropWidth, utility.NumericallyCloseEnough{}, 0.5, 1e-6)
+	checker.Assert(repeat.IsHalfDrop(), Equals, true)
+}
+
+func (suite *RectangularRepeatSuite) TestSlantedLatticeCanDropByLessThanHalf(checker *C) {
+	genericLattice := latticevector.Pair{
+		XLatticeVector: complex(1, 0),
+		YLatticeVector: complex(0.25, 1),
+	}
+	repeat, err := genericLattice.RectangularRepeat()
+	checker.Assert(err, IsNil)
+	checker.Assert(repeat.Width, utility.NumericallyCloseEnough{}, 1.0, 1e-6)
+	checker.Assert(repeat.Height, utility.NumericallyCloseEnough{}, 4.0, 1e-6)
+	checker.Assert(repeat.DropWidth, utility.NumericallyCloseEnough{}, 0.25, 1e-6)
+	checker.Assert(repeat.DropOffset, utility.NumericallyCloseEnough{}, 1.0, 1e-6)
+	checker.Assert(repeat.IsHalfDrop(), Equals, false)
+}
+
+func (suite *RectangularRepeatSuite) TestLatticeMustLineUpWithTheAxes(checker *C) {
+	genericLattice := latticevector.Pair{
+		XLatticeVector: complex(1, 0),
+		YLatticeVector: complex(math.Sqrt(2.0), 1),
+	}
+	_, err := genericLattice.RectangularRepeat()
+	checker.Assert(err, ErrorMatches, "the lattice does not repeat along both the real and imaginary axes")
+}
@@ -1,10 +1,10 @@
 package rosette
 
 import (
+	"errors"
 	"encoding/json"
 	"gopkg.in/yaml.v2"
 	"math/cmplx"
-	"wallpaper/entities/formula/coefficient"
 	"wallpaper/entities/formula/exponential"
 	"wallpaper/entities/formula/result"
 	"wallpaper/entities/utility"
@@ -18,39 +18,39 @@ type Formula struct {
 }
 
 // Calculate applies the Rosette formula to the complex number z.
+//   To calculate many points, Compile the formula once and use the CompiledFormula instead.
 func (r Formula) Calculate(z complex128) *result.CalculationResultForFormula {
-	result := &result.CalculationResultForFormula{
-		Total: complex(0,0),
-		ContributionByTerm: []complex128{},
-	}
-
-	for _, term := range r.Terms {
-		termResult := r.calculateTerm(term, z)
-		result.Total += termResult
-		result.ContributionByTerm = append(result.ContributionByTerm, termResult)
-	}
+	return r.Compile().Calculate(z)
+}
 
-	return result
+// CompiledFormula calculates a Formula using an EvaluationPlan built once,
+//   instead of expanding the coefficient relationships for every point.
+type CompiledFormula struct {
+	plan *exponential.EvaluationPlan
 }
 
-func (r *Formula) calculateTerm(term *exponential.RosetteFriezeTerm, z complex128) complex128 {
-	sum := complex(0.0,0.0)
+// Compile expands the formula's terms into a CompiledFormula.
+//   Changes to the formula's terms after compiling are not seen by the CompiledFormula.
+func (r Formula) Compile() *CompiledFormula {
+	return &CompiledFormula{plan: exponential.NewEvaluationPlan(r.Terms)}
+}
 
-	coefficientRelationships := []coefficient.Relationship{coefficient.PlusNPlusM}
-	coefficientRelationships = append(coefficientRelationships, term.CoefficientRelationships...)
-	coefficientSets := coefficient.Pairing{
-		PowerN:     term.PowerN,
-		PowerM:     term.PowerM,
-	}.GenerateCoefficientSets(coefficientRelationships)
+// Calculate applies the compiled formula to the complex number z.
+func (compiledFormula *CompiledFormula) Calculate(z complex128) *result.CalculationResultForFormula {
+	return compiledFormula.plan.Calculate(z, CalculateExponentTerm)
+}
 
-	for _, relationshipSet := range coefficientSets {
-		multiplier := term.Multiplier
-		if relationshipSet.NegateMultiplier == true {
-			multiplier *= -1
+// Validate returns an error if the formula cannot be calculated.
+func (r Formula) Validate() error {
+	if len(r.Terms) == 0 {
+		return errors.New(`formula must have at least one term`)
+	}
+	for _, term := range r.Terms {
+		if termErr := term.Validate(); termErr != nil {
+			return termErr
 		}
-		sum += CalculateExponentTerm(z, relationshipSet.PowerN, relationshipSet.PowerM, multiplier, term.IgnoreComplexConjugate)
 	}
-	return sum
+	return nil
 }
 
 // Symmetry notes the kinds of symmetries the rosette formula contains.
@@ -164,3 +164,26 @@ func NewRosetteFormulaFromMarshalObject(marshalObject MarshaledFormula) *Formula
 	}
 	return &Formula{Terms: terms}
 }
+
+// MarshalObject converts the formula into an object that can be marshaled.
+func (formula *Formula) MarshalObject() *MarshaledFormula {
+	termMarshals := []*exponential.TermMarshalable{}
+	for _, term := range formula.Terms {
+		termMarshals = append(termMarshals, term.MarshalObject())
+	}
+	return &MarshaledFormula{Terms: termMarshals}
+}
+
+// Morph blends two rosette formulas. A ratio of 0 returns the from formula and 1 returns the to formula.
+//   Terms with the same powers have their multipliers interpolated, and the other terms fade in or out.
+func Morph(from, to *Formula, ratio float64) *Formula {
+	return &Formula{Terms: exponential.BlendTerms(from.Terms, to.Terms, ratio)}
+}
+
+// AlignForMorph returns copies of both formulas with the same terms in the same order.
+//   Terms found in only one formula are added to the other with a multiplier of 0.
+//   Results of the copies can be blended point by point, and their contributions by term line up.
+func AlignForMorph(from, to *Formula) (*Formula, *Formula) {
+	alignedFrom, alignedTo := exponential.AlignTerms(from.Terms, to.Terms)
+	return &Formula{Terms: alignedFrom}, &Formula{Terms: alignedTo}
+}
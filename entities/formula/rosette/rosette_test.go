@@ -189,3 +189,72 @@ func (suite *RosetteFormulaTest) TestRosetteFormulaFromJSON(checker *C) {
 	checker.Assert(rosetteFormula.Terms[0].IgnoreComplexConjugate, Equals, false)
 	checker.Assert(rosetteFormula.Terms[1].CoefficientRelationships[0], Equals, coefficient.Relationship(coefficient.MinusMMinusNNegateMultiplierIfOddPowerSum))
 }
+
+func (suite *RosetteFormulaTest) TestFormulaWithoutTermsIsInvalid(checker *C) {
+	rosetteFormula := rosette.Formula{Terms: []*exponential.RosetteFriezeTerm{}}
+	checker.Assert(rosetteFormula.Validate(), ErrorMatches, "formula must have at least one term")
+}
+
+func (suite *RosetteFormulaTest) TestFormulaWithUnknownRelationshipIsInvalid(checker *C) {
+	rosetteFormula := rosette.Formula{
+		Terms: []*exponential.RosetteFriezeTerm{
+			{
+				Multiplier:               complex(1, 0),
+				PowerN:                   6,
+				PowerM:                   0,
+				CoefficientRelationships: []coefficient.Relationship{"+Q+N"},
+			},
+		},
+	}
+	checker.Assert(rosetteFormula.Validate(), NotNil)
+}
+
+func (suite *RosetteFormulaTest) TestCompiledFormulaMatchesFormula(checker *C) {
+	rosetteFormula := rosette.Formula{
+		Terms: []*exponential.RosetteFriezeTerm{
+			{
+				Multiplier: complex(1, 2),
+				PowerN:     4,
+				PowerM:     -1,
+				CoefficientRelationships: []coefficient.Relationship{
+					coefficient.PlusMPlusN,
+					coefficient.MinusNMinusM,
+				},
+			},
+			{
+				Multiplier:             complex(-0.5, 0),
+				PowerN:                 2,
+				PowerM:                 0,
+				IgnoreComplexConjugate: true,
+			},
+		},
+	}
+	compiledFormula := rosetteFormula.Compile()
+
+	for _, z := range []complex128{complex(0.3, -0.2), complex(-1.5, 2), complex(2, 0)} {
+		checker.Assert(compiledFormula.Calculate(z), DeepEquals, rosetteFormula.Calculate(z))
+	}
+	checker.Assert(compiledFormula.Calculate(complex(1, 1)).ContributionByTerm, HasLen, 2)
+}
+
+func (suite *RosetteFormulaTest) TestMorphMatchesBlendingTheResults(checker *C) {
+	from := &rosette.Formula{Terms: []*exponential.RosetteFriezeTerm{
+		{Multiplier: complex(1, 0), PowerN: 2, PowerM: 0},
+		{Multiplier: complex(0, 1), PowerN: 1, PowerM: -1},
+	}}
+	to := &rosette.Formula{Terms: []*exponential.RosetteFriezeTerm{
+		{Multiplier: complex(-1, 2), PowerN: 2, PowerM: 0},
+		{Multiplier: complex(3, 0), PowerN: 4, PowerM: 0},
+	}}
+	z := complex(0.3, -0.7)
+
+	blendedTotal := rosette.Morph(from, to, 0.25).Calculate(z).Total
+	expectedTotal := from.Calculate(z).Total*0.75 + to.Calculate(z).Total*0.25
+	checker.Assert(real(blendedTotal), utility.NumericallyCloseEnough{}, real(expectedTotal), 1e-6)
+	checker.Assert(imag(blendedTotal), utility.NumericallyCloseEnough{}, imag(expectedTotal), 1e-6)
+
+	alignedFrom, alignedTo := rosette.AlignForMorph(from, to)
+	checker.Assert(alignedFrom.Terms, HasLen, 3)
+	checker.Assert(alignedTo.Terms, HasLen, 3)
+	checker.Assert(alignedFrom.Calculate(z).Total, Equals, from.Calculate(z).Total)
+}
@@ -0,0 +1,93 @@
+package symmetryelement
+
+import (
+	"fmt"
+	"math"
+	"wallpaper/entities/formula/frieze"
+	"wallpaper/entities/formula/rosette"
+)
+
+// friezePeriodElements lists the symmetry elements of one frieze period.
+//   Positions along the real axis are fractions of the period. Every element crosses or lies on the real axis.
+type friezePeriodElements struct {
+	verticalMirrors  []float64
+	rotationCenters  []float64
+	horizontalMirror bool
+	horizontalGlide  bool
+}
+
+// friezePeriodElementsFor returns the elements of every frieze symmetry that was found.
+func friezePeriodElementsFor(symmetry *frieze.Symmetry) *friezePeriodElements {
+	elements := &friezePeriodElements{}
+	if symmetry.P211 || symmetry.P2mm || symmetry.P2mg {
+		elements.rotationCenters = []float64{0, 0.5}
+	}
+	if symmetry.P1m1 || symmetry.P2mm {
+		elements.verticalMirrors = append(elements.verticalMirrors, 0, 0.5)
+	}
+	if symmetry.P2mg {
+		elements.verticalMirrors = append(elements.verticalMirrors, 0.25, 0.75)
+	}
+	elements.horizontalMirror = symmetry.P11m || symmetry.P2mm
+	elements.horizontalGlide = symmetry.P11g || symmetry.P2mg
+	return elements
+}
+
+// FriezeElements returns the symmetry elements of a frieze pattern with the given symmetries.
+//   Elements are listed for every period that touches the rectangle from regionMin to regionMax.
+//   Mirror lines across the frieze run from the bottom to the top of the rectangle,
+//   and lines along the real axis run from its left to its right side.
+//   Returns an error if more than MaximumRepeats periods touch the rectangle.
+func FriezeElements(symmetry *frieze.Symmetry, regionMin, regionMax complex128) (*Set, error) {
+	firstPeriod := math.Floor(real(regionMin) / frieze.Period)
+	lastPeriod := math.Floor(real(regionMax) / frieze.Period)
+	if lastPeriod-firstPeriod+1 > MaximumRepeats {
+		return nil, fmt.Errorf(`the region holds %.0f frieze periods, more than %d`, lastPeriod-firstPeriod+1, MaximumRepeats)
+	}
+
+	periodElements := friezePeriodElementsFor(symmetry)
+	crossesRealAxis := imag(regionMin) <= 0 && imag(regionMax) >= 0
+	elements := &Set{}
+	for period := firstPeriod; period <= lastPeriod; period++ {
+		for _, position := range periodElements.verticalMirrors {
+			x := (period + position) * frieze.Period
+			elements.Mirrors = append(elements.Mirrors, Line{
+				Start: complex(x, imag(regionMin)),
+				End:   complex(x, imag(regionMax)),
+			})
+		}
+		if !crossesRealAxis {
+			continue
+		}
+		for _, position := range periodElements.rotationCenters {
+			elements.RotationCenters = append(elements.RotationCenters, RotationCenter{
+				Center: complex((period+position)*frieze.Period, 0),
+				Order:  2,
+			})
+		}
+	}
+
+	if crossesRealAxis {
+		realAxis := Line{Start: complex(real(regionMin), 0), End: complex(real(regionMax), 0)}
+		if periodElements.horizontalMirror {
+			elements.Mirrors = append(elements.Mirrors, realAxis)
+		}
+		if periodElements.horizontalGlide {
+			realAxis.Glide = complex(frieze.Period/2, 0)
+			elements.Glides = append(elements.Glides, realAxis)
+		}
+	}
+	return elements, nil
+}
+
+// RosetteElements returns the rotation center of a rosette pattern, if it lies in the rectangle from regionMin to regionMax.
+//   Rosettes only report how many ways they can be rotated, so mirror lines are not found.
+//   Rosettes without at least 2-fold rotational symmetry have no elements.
+func RosetteElements(symmetry *rosette.Symmetry, regionMin, regionMax complex128) *Set {
+	elements := &Set{}
+	originIsInRegion := real(regionMin) <= 0 && real(regionMax) >= 0 && imag(regionMin) <= 0 && imag(regionMax) >= 0
+	if symmetry.Multifold >= 2 && originIsInRegion {
+		elements.RotationCenters = append(elements.RotationCenters, RotationCenter{Center: 0, Order: symmetry.Multifold})
+	}
+	return elements
+}
@@ -0,0 +1,68 @@
+package symmetryelement
+
+import (
+	"math"
+	"wallpaper/entities/formula/latticevector"
+	"wallpaper/entities/formula/wallpaper"
+)
+
+// latticeCellFundamentalDomains lists one fundamental domain inside the lattice cell, for each symmetry.
+//   Each domain is a convex polygon in lattice coordinates, using the same cell as latticeCellElements.
+//   Copying the domain with every symmetry of the group fills the plane without overlaps.
+//   Domains are bounded by mirror lines when the group has them.
+var latticeCellFundamentalDomains = map[wallpaper.Symmetry][]complex128{
+	wallpaper.P1:   {complex(0, 0), complex(1, 0), complex(1, 1), complex(0, 1)},
+	wallpaper.P2:   {complex(0, 0), complex(1, 0), complex(1, 0.5), complex(0, 0.5)},
+	wallpaper.Pm:   {complex(0, 0), complex(1, 0), complex(1, 0.5), complex(0, 0.5)},
+	wallpaper.Pg:   {complex(0, 0), complex(1, 0), complex(1, 0.5), complex(0, 0.5)},
+	wallpaper.Pmm:  {complex(0, 0), complex(0.5, 0), complex(0.5, 0.5), complex(0, 0.5)},
+	wallpaper.Pmg:  {complex(0.25, 0), complex(0.75, 0), complex(0.75, 0.5), complex(0.25, 0.5)},
+	wallpaper.Pgg:  {complex(0, 0), complex(0.5, 0), complex(0.5, 0.5), complex(0, 0.5)},
+	wallpaper.Cm:   {complex(0, 0), complex(1, 0), complex(1, 1)},
+	wallpaper.Cmm:  {complex(0, 0), complex(1, 0), complex(0.5, 0.5)},
+	wallpaper.P4:   {complex(0, 0), complex(0.5, 0), complex(0.5, 0.5), complex(0, 0.5)},
+	wallpaper.P4m:  {complex(0, 0), complex(0.5, 0), complex(0.5, 0.5)},
+	wallpaper.P4g:  {complex(0, 0), complex(0.5, 0), complex(0, 0.5)},
+	wallpaper.P3:   {complex(0, 0), complex(2.0/3.0, 1.0/3.0), complex(1, 1), complex(1.0/3.0, 2.0/3.0)},
+	wallpaper.P31m: {complex(0, 0), complex(1, 0), complex(2.0/3.0, 1.0/3.0)},
+	wallpaper.P3m1: {complex(0, 0), complex(2.0/3.0, 1.0/3.0), complex(1.0/3.0, 2.0/3.0)},
+	wallpaper.P6:   {complex(0, 0), complex(2.0/3.0, 1.0/3.0), complex(1.0/3.0, 2.0/3.0)},
+	wallpaper.P6m:  {complex(0, 0), complex(0.5, 0), complex(2.0/3.0, 1.0/3.0)},
+}
+
+// FundamentalDomain returns the corners of one fundamental domain of the symmetry,
+//   inside the lattice cell that holds the point near.
+//   A fundamental domain is the smallest region that the symmetry group copies to fill the whole pattern.
+func FundamentalDomain(symmetry wallpaper.Symmetry, lattice *latticevector.Pair, near complex128) []complex128 {
+	latticeNear := lattice.ConvertToLatticeCoordinates(near)
+	cellCorner := complex(math.Floor(real(latticeNear)), math.Floor(imag(latticeNear)))
+
+	corners := []complex128{}
+	for _, corner := range latticeCellFundamentalDomains[symmetry] {
+		corners = append(corners, lattice.ConvertToCartesianCoordinates(corner+cellCorner))
+	}
+	return corners
+}
+
+// MostSymmetric returns the symmetry with the smallest fundamental domain, so its group has the most symmetries.
+//   Returns P1 if there are no symmetries.
+func MostSymmetric(symmetries []wallpaper.Symmetry) wallpaper.Symmetry {
+	mostSymmetric := wallpaper.P1
+	for _, symmetry := range symmetries {
+		if cellFraction(symmetry) < cellFraction(mostSymmetric) {
+			mostSymmetric = symmetry
+		}
+	}
+	return mostSymmetric
+}
+
+// cellFraction returns how much of the lattice cell the symmetry's fundamental domain covers.
+func cellFraction(symmetry wallpaper.Symmetry) float64 {
+	corners := latticeCellFundamentalDomains[symmetry]
+	twiceTheArea := 0.0
+	for index, corner := range corners {
+		nextCorner := corners[(index+1)%len(corners)]
+		twiceTheArea += real(corner)*imag(nextCorner) - real(nextCorner)*imag(corner)
+	}
+	return math.Abs(twiceTheArea) / 2
+}
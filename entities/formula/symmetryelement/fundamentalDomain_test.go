@@ -0,0 +1,111 @@
+package symmetryelement_test
+
+import (
+	. "gopkg.in/check.v1"
+	"math"
+	"wallpaper/entities/formula/latticevector"
+	"wallpaper/entities/formula/symmetryelement"
+	"wallpaper/entities/formula/wallpaper"
+)
+
+type FundamentalDomainSuite struct{}
+
+var _ = Suite(&FundamentalDomainSuite{})
+
+// pointGroupOrders is the number of copies of a fundamental domain in each lattice cell.
+var pointGroupOrders = map[wallpaper.Symmetry]int{
+	wallpaper.P1: 1, wallpaper.P2: 2, wallpaper.Pm: 2, wallpaper.Pg: 2, wallpaper.Cm: 2,
+	wallpaper.Pmm: 4, wallpaper.Pmg: 4, wallpaper.Pgg: 4, wallpaper.Cmm: 4, wallpaper.P4: 4,
+	wallpaper.P4m: 8, wallpaper.P4g: 8, wallpaper.P3: 3,
+	wallpaper.P31m: 6, wallpaper.P3m1: 6, wallpaper.P6: 6, wallpaper.P6m: 12,
+}
+
+func polygonArea(corners []complex128) float64 {
+	twiceTheArea := 0.0
+	for index, corner := range corners {
+		nextCorner := corners[(index+1)%len(corners)]
+		twiceTheArea += real(corner)*imag(nextCorner) - real(nextCorner)*imag(corner)
+	}
+	return math.Abs(twiceTheArea) / 2
+}
+
+// sideOfLine is positive on one side of the line through start and end, negative on the other and 0 on it.
+func sideOfLine(point, start, end complex128) float64 {
+	direction := end - start
+	offset := point - start
+	return real(direction)*imag(offset) - imag(direction)*real(offset)
+}
+
+// isStrictlyInside returns true if the point is inside the convex polygon and not on its edges.
+func isStrictlyInside(point complex128, corners []complex128) bool {
+	orientation := sideOfLine(corners[2], corners[0], corners[1])
+	for index, corner := range corners {
+		if sideOfLine(point, corner, corners[(index+1)%len(corners)])*orientation <= 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func (suite *FundamentalDomainSuite) TestCopiesOfTheDomainFillTheCell(checker *C) {
+	unitSquareLattice := &latticevector.Pair{XLatticeVector: complex(1, 0), YLatticeVector: complex(0, 1)}
+	for symmetry, order := range pointGroupOrders {
+		domain := symmetryelement.FundamentalDomain(symmetry, unitSquareLattice, complex(0.1, 0.1))
+		checker.Assert(polygonArea(domain)*float64(order), Equals, 1.0, Commentf("%s", symmetry))
+	}
+}
+
+func (suite *FundamentalDomainSuite) TestSymmetryElementsDoNotCrossTheDomain(checker *C) {
+	latticeTypeBySymmetry := map[wallpaper.Symmetry]wallpaper.LatticeType{
+		wallpaper.P2: wallpaper.Generic, wallpaper.Pm: wallpaper.Rectangular, wallpaper.Pg: wallpaper.Rectangular,
+		wallpaper.Pmm: wallpaper.Rectangular, wallpaper.Pmg: wallpaper.Rectangular, wallpaper.Pgg: wallpaper.Rectangular,
+		wallpaper.Cm: wallpaper.Rhombic, wallpaper.Cmm: wallpaper.Rhombic,
+		wallpaper.P4: wallpaper.Square, wallpaper.P4m: wallpaper.Square, wallpaper.P4g: wallpaper.Square,
+		wallpaper.P3: wallpaper.Hexagonal, wallpaper.P31m: wallpaper.Hexagonal, wallpaper.P3m1: wallpaper.Hexagonal,
+		wallpaper.P6: wallpaper.Hexagonal, wallpaper.P6m: wallpaper.Hexagonal,
+	}
+
+	for symmetry, latticeType := range latticeTypeBySymmetry {
+		latticePattern := newLatticePattern(checker, latticeType, symmetry)
+		domain := symmetryelement.FundamentalDomain(symmetry, latticePattern.Lattice, complex(0.01, 0.01))
+		elements, err := symmetryelement.LatticeElements([]wallpaper.Symmetry{symmetry}, latticePattern.Lattice, complex(-2, -2), complex(2, 2))
+		checker.Assert(err, IsNil)
+
+		for _, mirror := range elements.Mirrors {
+			cornersOnEachSide := map[bool]bool{}
+			for _, corner := range domain {
+				if side := sideOfLine(corner, mirror.Start, mirror.End); math.Abs(side) > 1e-9 {
+					cornersOnEachSide[side > 0] = true
+				}
+			}
+			checker.Assert(cornersOnEachSide, HasLen, 1, Commentf("%s mirror %s", symmetry, lineDescription(mirror)))
+		}
+		for _, rotationCenter := range elements.RotationCenters {
+			checker.Assert(isStrictlyInside(rotationCenter.Center, domain), Equals, false, Commentf("%s center %v", symmetry, rotationCenter))
+		}
+	}
+}
+
+func (suite *FundamentalDomainSuite) TestDomainIsInTheCellHoldingTheNearbyPoint(checker *C) {
+	rectangularLattice := &latticevector.Pair{XLatticeVector: complex(2, 0), YLatticeVector: complex(0, 1)}
+	domain := symmetryelement.FundamentalDomain(wallpaper.Pmm, rectangularLattice, complex(-3, 4.5))
+	checker.Assert(domain, DeepEquals, []complex128{complex(-4, 4), complex(-3, 4), complex(-3, 4.5), complex(-4, 4.5)})
+}
+
+func (suite *FundamentalDomainSuite) TestMostSymmetricHasTheSmallestDomain(checker *C) {
+	checker.Assert(symmetryelement.MostSymmetric([]wallpaper.Symmetry{wallpaper.P1, wallpaper.P3, wallpaper.P31m, wallpaper.P6m, wallpaper.P6}), Equals, wallpaper.P6m)
+	checker.Assert(symmetryelement.MostSymmetric([]wallpaper.Symmetry{wallpaper.P1, wallpaper.Cm}), Equals, wallpaper.Cm)
+	checker.Assert(symmetryelement.MostSymmetric(nil), Equals, wallpaper.P1)
+}
+
+func (suite *FundamentalDomainSuite) TestLatticeGridHasTwoEdgesPerCell(checker *C) {
+	unitSquareLattice := &latticevector.Pair{XLatticeVector: complex(1, 0), YLatticeVector: complex(0, 1)}
+	edges, err := symmetryelement.LatticeGrid(unitSquareLattice, complex(0.5, 0.5), complex(1.5, 2.5))
+	checker.Assert(err, IsNil)
+	checker.Assert(edges, HasLen, 2*3*2)
+	checker.Assert(edges[0], Equals, symmetryelement.Line{Start: 0, End: complex(1, 0)})
+	checker.Assert(edges[1], Equals, symmetryelement.Line{Start: 0, End: complex(0, 1)})
+
+	_, err = symmetryelement.LatticeGrid(unitSquareLattice, complex(-100, -100), complex(100, 100))
+	checker.Assert(err, ErrorMatches, "the region holds 40401 lattice cells, more than 10000")
+}
@@ -0,0 +1,269 @@
+package symmetryelement
+
+import (
+	"fmt"
+	"math"
+	"wallpaper/entities/formula/latticevector"
+	"wallpaper/entities/formula/wallpaper"
+)
+
+// latticeCellElements lists the symmetry elements of one lattice cell, for each symmetry.
+//   Points are in lattice coordinates, so the cell runs from 0 to 1 along each lattice vector.
+//   Lines and centers on the cell's far edges belong to the neighboring cells, so copies of the cell never overlap.
+//   Each symmetry uses the lattice type that can find it.
+var latticeCellElements = map[wallpaper.Symmetry]*Set{
+	wallpaper.P2: {
+		RotationCenters: halfLatticeRotationCenters(2, 2, 2, 2),
+	},
+	wallpaper.Pm: {
+		Mirrors: []Line{cellLine(0, 0, 1, 0), cellLine(0, 0.5, 1, 0.5)},
+	},
+	wallpaper.Pg: {
+		Glides: []Line{glideLine(0, 0, 1, 0), glideLine(0, 0.5, 1, 0.5)},
+	},
+	wallpaper.Pmm: {
+		Mirrors: []Line{
+			cellLine(0, 0, 1, 0), cellLine(0, 0.5, 1, 0.5),
+			cellLine(0, 0, 0, 1), cellLine(0.5, 0, 0.5, 1),
+		},
+		RotationCenters: halfLatticeRotationCenters(2, 2, 2, 2),
+	},
+	wallpaper.Pmg: {
+		Mirrors:         []Line{cellLine(0.25, 0, 0.25, 1), cellLine(0.75, 0, 0.75, 1)},
+		Glides:          []Line{glideLine(0, 0, 1, 0), glideLine(0, 0.5, 1, 0.5)},
+		RotationCenters: halfLatticeRotationCenters(2, 2, 2, 2),
+	},
+	wallpaper.Pgg: {
+		Glides: []Line{
+			glideLine(0, 0.25, 1, 0.25), glideLine(0, 0.75, 1, 0.75),
+			glideLine(0.25, 0, 0.25, 1), glideLine(0.75, 0, 0.75, 1),
+		},
+		RotationCenters: halfLatticeRotationCenters(2, 2, 2, 2),
+	},
+	wallpaper.Cm: {
+		Mirrors: []Line{cellLine(0, 0, 1, 1)},
+		Glides:  []Line{glideLine(0.5, 0, 1, 0.5), glideLine(0, 0.5, 0.5, 1)},
+	},
+	wallpaper.Cmm: {
+		Mirrors: []Line{cellLine(0, 0, 1, 1), cellLine(1, 0, 0, 1)},
+		Glides: []Line{
+			glideLine(0.5, 0, 1, 0.5), glideLine(0, 0.5, 0.5, 1),
+			glideLine(0.5, 0, 0, 0.5), glideLine(1, 0.5, 0.5, 1),
+		},
+		RotationCenters: halfLatticeRotationCenters(2, 2, 2, 2),
+	},
+	wallpaper.P4: {
+		RotationCenters: halfLatticeRotationCenters(4, 2, 2, 4),
+	},
+	wallpaper.P4m: {
+		Mirrors: []Line{
+			cellLine(0, 0, 1, 0), cellLine(0, 0.5, 1, 0.5),
+			cellLine(0, 0, 0, 1), cellLine(0.5, 0, 0.5, 1),
+			cellLine(0, 0, 1, 1), cellLine(1, 0, 0, 1),
+		},
+		Glides: []Line{
+			glideLine(0.5, 0, 1, 0.5), glideLine(0, 0.5, 0.5, 1),
+			glideLine(0.5, 0, 0, 0.5), glideLine(1, 0.5, 0.5, 1),
+		},
+		RotationCenters: halfLatticeRotationCenters(4, 2, 2, 4),
+	},
+	wallpaper.P4g: {
+		Mirrors: []Line{
+			cellLine(0.5, 0, 1, 0.5), cellLine(0, 0.5, 0.5, 1),
+			cellLine(0.5, 0, 0, 0.5), cellLine(1, 0.5, 0.5, 1),
+		},
+		Glides: []Line{
+			glideLine(0, 0.25, 1, 0.25), glideLine(0, 0.75, 1, 0.75),
+			glideLine(0.25, 0, 0.25, 1), glideLine(0.75, 0, 0.75, 1),
+			glideLine(0, 0, 1, 1), glideLine(1, 0, 0, 1),
+		},
+		RotationCenters: halfLatticeRotationCenters(4, 2, 2, 4),
+	},
+	wallpaper.P3: {
+		RotationCenters: hexagonalRotationCenters(3),
+	},
+	wallpaper.P31m: p31mCellElements,
+	wallpaper.P3m1: p3m1CellElements,
+	wallpaper.P6: {
+		RotationCenters: sixfoldRotationCenters(),
+	},
+	wallpaper.P6m: combineCellElements(
+		p31mCellElements,
+		p3m1CellElements,
+		&Set{RotationCenters: sixfoldRotationCenters()},
+	),
+}
+
+// p31mCellElements has mirrors along both lattice vectors and the short diagonal.
+//   Every other 3-fold rotation center is off the mirrors.
+var p31mCellElements = &Set{
+	Mirrors: []Line{cellLine(0, 0, 1, 0), cellLine(0, 0, 0, 1), cellLine(0, 0, 1, 1)},
+	Glides: []Line{
+		glideLine(0, 0.5, 1, 0.5), glideLine(0.5, 0, 0.5, 1),
+		glideLine(0.5, 0, 1, 0.5), glideLine(0, 0.5, 0.5, 1),
+	},
+	RotationCenters: hexagonalRotationCenters(3),
+}
+
+// p3m1CellElements has mirrors along the long diagonal and the lines that meet it at 60 degrees.
+//   Every 3-fold rotation center is on the mirrors.
+var p3m1CellElements = &Set{
+	Mirrors: []Line{
+		cellLine(1, 0, 0, 1),
+		cellLine(0, 0, 1, 0.5), cellLine(0, 0.5, 1, 1),
+		cellLine(0, 0, 0.5, 1), cellLine(0.5, 0, 1, 1),
+	},
+	Glides: []Line{
+		glideLine(0.5, 0, 0, 0.5), glideLine(1, 0.5, 0.5, 1),
+		glideLine(0.5, 0, 1, 0.25), glideLine(0, 0.25, 1, 0.75), glideLine(0, 0.75, 0.5, 1),
+		glideLine(0, 0.5, 0.25, 1), glideLine(0.25, 0, 0.75, 1), glideLine(0.75, 0, 1, 0.5),
+	},
+	RotationCenters: hexagonalRotationCenters(3),
+}
+
+// cellLine returns a line in lattice coordinates that does not glide.
+func cellLine(startX, startY, endX, endY float64) Line {
+	return Line{Start: complex(startX, startY), End: complex(endX, endY)}
+}
+
+// glideLine returns a line in lattice coordinates that glides by half of the shortest lattice vector along it.
+func glideLine(startX, startY, endX, endY float64) Line {
+	line := cellLine(startX, startY, endX, endY)
+	line.Glide = halfOfShortestLatticeVectorAlong(line.End - line.Start)
+	return line
+}
+
+// halfOfShortestLatticeVectorAlong finds the shortest vector with whole number lattice coordinates
+//   that points the same way as direction, and returns half of it.
+//   Cell lines only use halves and quarters of the cell, so a whole number multiple is always found.
+func halfOfShortestLatticeVectorAlong(direction complex128) complex128 {
+	for multiple := 1.0; multiple <= 4; multiple++ {
+		scaledDirection := direction * complex(multiple, 0)
+		if isWholeNumber(real(scaledDirection)) && isWholeNumber(imag(scaledDirection)) {
+			return scaledDirection / 2
+		}
+	}
+	return direction * 2
+}
+
+func isWholeNumber(value float64) bool {
+	return math.Abs(value-math.Round(value)) <= closeEnough
+}
+
+// halfLatticeRotationCenters returns rotation centers at the cell's corner, the middle of each lattice vector,
+//   and the middle of the cell. Centers with an order of 0 are left out.
+func halfLatticeRotationCenters(cornerOrder, xMiddleOrder, yMiddleOrder, cellMiddleOrder int) []RotationCenter {
+	rotationCenters := []RotationCenter{}
+	for _, rotationCenter := range []RotationCenter{
+		{Center: complex(0, 0), Order: cornerOrder},
+		{Center: complex(0.5, 0), Order: xMiddleOrder},
+		{Center: complex(0, 0.5), Order: yMiddleOrder},
+		{Center: complex(0.5, 0.5), Order: cellMiddleOrder},
+	} {
+		if rotationCenter.Order > 0 {
+			rotationCenters = append(rotationCenters, rotationCenter)
+		}
+	}
+	return rotationCenters
+}
+
+// hexagonalRotationCenters returns a rotation center at the cell's corner with the given order,
+//   and 3-fold rotation centers in the middle of the two triangles that make up the cell.
+func hexagonalRotationCenters(cornerOrder int) []RotationCenter {
+	return []RotationCenter{
+		{Center: complex(0, 0), Order: cornerOrder},
+		{Center: complex(1.0/3.0, 2.0/3.0), Order: 3},
+		{Center: complex(2.0/3.0, 1.0/3.0), Order: 3},
+	}
+}
+
+// sixfoldRotationCenters returns the rotation centers of p6 and p6m.
+//   2-fold centers lie halfway between the 6-fold centers.
+func sixfoldRotationCenters() []RotationCenter {
+	return append(hexagonalRotationCenters(6), halfLatticeRotationCenters(0, 2, 2, 2)...)
+}
+
+func combineCellElements(cellElements ...*Set) *Set {
+	combined := &Set{}
+	for _, elements := range cellElements {
+		combined.merge(elements)
+	}
+	return combined
+}
+
+// LatticeElements returns the symmetry elements of a lattice pattern with all of the given symmetries.
+//   Elements are listed for every lattice cell that touches the rectangle from regionMin to regionMax,
+//   so some of them lie outside of the rectangle.
+//   Returns an error if more than MaximumRepeats cells touch the rectangle.
+func LatticeElements(symmetries []wallpaper.Symmetry, lattice *latticevector.Pair, regionMin, regionMax complex128) (*Set, error) {
+	cellElements := &Set{}
+	for _, symmetry := range symmetries {
+		cellElements.merge(latticeCellElements[symmetry])
+	}
+
+	minCell, maxCell, err := latticeCellRange(lattice, regionMin, regionMax)
+	if err != nil {
+		return nil, err
+	}
+
+	elements := &Set{}
+	for cellX := real(minCell); cellX <= real(maxCell); cellX++ {
+		for cellY := imag(minCell); cellY <= imag(maxCell); cellY++ {
+			cellCorner := complex(cellX, cellY)
+			elements.appendMoved(
+				cellElements,
+				func(latticePoint complex128) complex128 {
+					return lattice.ConvertToCartesianCoordinates(latticePoint + cellCorner)
+				},
+				lattice.ConvertToCartesianCoordinates,
+			)
+		}
+	}
+	return elements, nil
+}
+
+// LatticeGrid returns the edges of every lattice cell that touches the rectangle from regionMin to regionMax.
+//   Each cell adds the edges along both lattice vectors from its corner, so no edge is listed twice.
+//   Returns an error if more than MaximumRepeats cells touch the rectangle.
+func LatticeGrid(lattice *latticevector.Pair, regionMin, regionMax complex128) ([]Line, error) {
+	minCell, maxCell, err := latticeCellRange(lattice, regionMin, regionMax)
+	if err != nil {
+		return nil, err
+	}
+
+	edges := []Line{}
+	for cellX := real(minCell); cellX <= real(maxCell); cellX++ {
+		for cellY := imag(minCell); cellY <= imag(maxCell); cellY++ {
+			cellCorner := lattice.ConvertToCartesianCoordinates(complex(cellX, cellY))
+			edges = append(edges,
+				Line{Start: cellCorner, End: cellCorner + lattice.XLatticeVector},
+				Line{Start: cellCorner, End: cellCorner + lattice.YLatticeVector},
+			)
+		}
+	}
+	return edges, nil
+}
+
+// latticeCellRange returns the lattice coordinates of the first and last cells that touch the rectangle
+//   from regionMin to regionMax. Returns an error if there are more than MaximumRepeats cells.
+func latticeCellRange(lattice *latticevector.Pair, regionMin, regionMax complex128) (complex128, complex128, error) {
+	minCellX, minCellY := math.Inf(1), math.Inf(1)
+	maxCellX, maxCellY := math.Inf(-1), math.Inf(-1)
+	for _, corner := range []complex128{
+		regionMin,
+		complex(real(regionMax), imag(regionMin)),
+		complex(real(regionMin), imag(regionMax)),
+		regionMax,
+	} {
+		latticeCorner := lattice.ConvertToLatticeCoordinates(corner)
+		minCellX = math.Min(minCellX, math.Floor(real(latticeCorner)))
+		minCellY = math.Min(minCellY, math.Floor(imag(latticeCorner)))
+		maxCellX = math.Max(maxCellX, math.Floor(real(latticeCorner)))
+		maxCellY = math.Max(maxCellY, math.Floor(imag(latticeCorner)))
+	}
+	cellCount := (maxCellX - minCellX + 1) * (maxCellY - minCellY + 1)
+	if cellCount > MaximumRepeats {
+		return 0, 0, fmt.Errorf(`the region holds %.0f lattice cells, more than %d`, cellCount, MaximumRepeats)
+	}
+	return complex(minCellX, minCellY), complex(maxCellX, maxCellY), nil
+}
@@ -0,0 +1,102 @@
+package symmetryelement
+
+import (
+	"math/cmplx"
+)
+
+// MaximumRepeats limits how many lattice cells or frieze periods are searched for symmetry elements.
+//   Regions holding more repeats than this are too crowded to draw.
+const MaximumRepeats = 10000
+
+// closeEnough is how near two points in a repeating unit can be before they are treated as the same point.
+const closeEnough = 1e-9
+
+// Line is part of a mirror line or glide axis.
+type Line struct {
+	Start complex128
+	End   complex128
+	// Glide is how far a glide reflection moves the pattern along the line after reflecting it across the line.
+	//   It is 0 for mirror lines.
+	Glide complex128
+}
+
+// RotationCenter is a point the pattern can be turned around without changing it.
+type RotationCenter struct {
+	Center complex128
+	// Order is the number of turns that add up to a full circle, like 4 for a 4-fold rotation center.
+	Order int
+}
+
+// Set lists symmetry elements: mirror lines, glide axes and rotation centers.
+type Set struct {
+	Mirrors         []Line
+	Glides          []Line
+	RotationCenters []RotationCenter
+}
+
+// merge adds the other set's elements to this set, skipping elements this set already has.
+//   A rotation center found in both sets keeps the higher order.
+func (set *Set) merge(other *Set) {
+	if other == nil {
+		return
+	}
+	for _, mirror := range other.Mirrors {
+		if !containsLine(set.Mirrors, mirror) {
+			set.Mirrors = append(set.Mirrors, mirror)
+		}
+	}
+	for _, glide := range other.Glides {
+		if !containsLine(set.Glides, glide) {
+			set.Glides = append(set.Glides, glide)
+		}
+	}
+	for _, rotationCenter := range other.RotationCenters {
+		set.addRotationCenter(rotationCenter)
+	}
+}
+
+func (set *Set) addRotationCenter(rotationCenter RotationCenter) {
+	for index, existingCenter := range set.RotationCenters {
+		if cmplx.Abs(existingCenter.Center-rotationCenter.Center) > closeEnough {
+			continue
+		}
+		if rotationCenter.Order > existingCenter.Order {
+			set.RotationCenters[index].Order = rotationCenter.Order
+		}
+		return
+	}
+	set.RotationCenters = append(set.RotationCenters, rotationCenter)
+}
+
+// appendMoved adds a copy of every element in the other set.
+//   moveToPoint moves each point of the copy, and moveVector changes the direction and length of each glide.
+func (set *Set) appendMoved(other *Set, moveToPoint, moveVector func(complex128) complex128) {
+	for _, mirror := range other.Mirrors {
+		set.Mirrors = append(set.Mirrors, Line{Start: moveToPoint(mirror.Start), End: moveToPoint(mirror.End)})
+	}
+	for _, glide := range other.Glides {
+		set.Glides = append(set.Glides, Line{
+			Start: moveToPoint(glide.Start),
+			End:   moveToPoint(glide.End),
+			Glide: moveVector(glide.Glide),
+		})
+	}
+	for _, rotationCenter := range other.RotationCenters {
+		set.RotationCenters = append(set.RotationCenters, RotationCenter{
+			Center: moveToPoint(rotationCenter.Center),
+			Order:  rotationCenter.Order,
+		})
+	}
+}
+
+// containsLine returns true if one of the lines joins the same two points, in either direction.
+func containsLine(lines []Line, lineToFind Line) bool {
+	for _, line := range lines {
+		sameDirection := cmplx.Abs(line.Start-lineToFind.Start) <= closeEnough && cmplx.Abs(line.End-lineToFind.End) <= closeEnough
+		oppositeDirection := cmplx.Abs(line.Start-lineToFind.End) <= closeEnough && cmplx.Abs(line.End-lineToFind.Start) <= closeEnough
+		if sameDirection || oppositeDirection {
+			return true
+		}
+	}
+	return false
+}
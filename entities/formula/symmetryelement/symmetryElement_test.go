@@ -0,0 +1,266 @@
+package symmetryelement_test
+
+import (
+	"fmt"
+	. "gopkg.in/check.v1"
+	"math"
+	"math/cmplx"
+	"testing"
+	"wallpaper/entities/formula"
+	"wallpaper/entities/formula/frieze"
+	"wallpaper/entities/formula/latticevector"
+	"wallpaper/entities/formula/result"
+	"wallpaper/entities/formula/rosette"
+	"wallpaper/entities/formula/symmetryelement"
+	"wallpaper/entities/formula/wallpaper"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type SymmetryElementSuite struct{}
+
+var _ = Suite(&SymmetryElementSuite{})
+
+type patternFormula interface {
+	Calculate(z complex128) *result.CalculationResultForFormula
+}
+
+// samplePoints are scattered so no two of them are related by a symmetry of the patterns under test.
+var samplePoints = []complex128{
+	complex(0.13, 0.07),
+	complex(-0.71, 0.45),
+	complex(0.38, -0.92),
+	complex(1.27, 0.61),
+}
+
+func reflectAcross(z complex128, line symmetryelement.Line) complex128 {
+	direction := (line.End - line.Start) / complex(cmplx.Abs(line.End-line.Start), 0)
+	return line.Start + direction*cmplx.Conj((z-line.Start)/direction)
+}
+
+func rotateAround(z complex128, rotationCenter symmetryelement.RotationCenter) complex128 {
+	return rotationCenter.Center + (z-rotationCenter.Center)*cmplx.Rect(1, 2*math.Pi/float64(rotationCenter.Order))
+}
+
+// checkElementsAreSymmetries makes sure every element maps the pattern onto itself.
+func checkElementsAreSymmetries(checker *C, pattern patternFormula, elements *symmetryelement.Set, description string) {
+	checkMove := func(move func(complex128) complex128, elementDescription string) {
+		for _, z := range samplePoints {
+			difference := cmplx.Abs(pattern.Calculate(move(z)).Total - pattern.Calculate(z).Total)
+			checker.Assert(difference < 1e-6, Equals, true, Commentf("%s: %s moves %v", description, elementDescription, z))
+		}
+	}
+
+	for _, mirror := range elements.Mirrors {
+		checker.Assert(mirror.Glide, Equals, complex(0, 0))
+		checkMove(func(z complex128) complex128 { return reflectAcross(z, mirror) }, "mirror "+lineDescription(mirror))
+	}
+	for _, glide := range elements.Glides {
+		checkMove(func(z complex128) complex128 { return reflectAcross(z, glide) + glide.Glide }, "glide "+lineDescription(glide))
+	}
+	for _, rotationCenter := range elements.RotationCenters {
+		checkMove(func(z complex128) complex128 { return rotateAround(z, rotationCenter) }, "rotation center")
+	}
+}
+
+func lineDescription(line symmetryelement.Line) string {
+	return fmt.Sprintf("from %v to %v", line.Start, line.End)
+}
+
+func newLatticePattern(checker *C, latticeType wallpaper.LatticeType, symmetry wallpaper.Symmetry) *wallpaper.Formula {
+	latticePattern := &wallpaper.Formula{
+		LatticeType: latticeType,
+		LatticeSize: &wallpaper.Dimensions{Width: 0.3, Height: 0.8},
+		Lattice:     &latticevector.Pair{},
+		Multiplier:  complex(1, 0),
+		WavePackets: []*wallpaper.WavePacket{
+			{
+				Terms:      []*formula.EisensteinFormulaTerm{{PowerN: 1, PowerM: 2}},
+				Multiplier: complex(1, 0),
+			},
+			{
+				Terms:      []*formula.EisensteinFormulaTerm{{PowerN: -3, PowerM: 1}},
+				Multiplier: complex(0.5, -0.25),
+			},
+		},
+		DesiredSymmetry: symmetry,
+	}
+	checker.Assert(latticePattern.Setup(), IsNil)
+	checker.Assert(latticePattern.HasSymmetry(symmetry), Equals, true, Commentf("%s", symmetry))
+	return latticePattern
+}
+
+func (suite *SymmetryElementSuite) TestLatticeElementsAreSymmetriesOfThePattern(checker *C) {
+	latticeTypeBySymmetry := map[wallpaper.Symmetry]wallpaper.LatticeType{
+		wallpaper.P1:   wallpaper.Generic,
+		wallpaper.P2:   wallpaper.Generic,
+		wallpaper.Pm:   wallpaper.Rectangular,
+		wallpaper.Pg:   wallpaper.Rectangular,
+		wallpaper.Pmm:  wallpaper.Rectangular,
+		wallpaper.Pmg:  wallpaper.Rectangular,
+		wallpaper.Pgg:  wallpaper.Rectangular,
+		wallpaper.Cm:   wallpaper.Rhombic,
+		wallpaper.Cmm:  wallpaper.Rhombic,
+		wallpaper.P4:   wallpaper.Square,
+		wallpaper.P4m:  wallpaper.Square,
+		wallpaper.P4g:  wallpaper.Square,
+		wallpaper.P3:   wallpaper.Hexagonal,
+		wallpaper.P31m: wallpaper.Hexagonal,
+		wallpaper.P3m1: wallpaper.Hexagonal,
+		wallpaper.P6:   wallpaper.Hexagonal,
+		wallpaper.P6m:  wallpaper.Hexagonal,
+	}
+
+	for symmetry, latticeType := range latticeTypeBySymmetry {
+		latticePattern := newLatticePattern(checker, latticeType, symmetry)
+		elements, err := symmetryelement.LatticeElements(
+			[]wallpaper.Symmetry{symmetry},
+			latticePattern.Lattice,
+			complex(-1, -1),
+			complex(1, 1),
+		)
+		checker.Assert(err, IsNil)
+		checkElementsAreSymmetries(checker, latticePattern, elements, string(symmetry))
+	}
+}
+
+func (suite *SymmetryElementSuite) TestP4mCellHasEveryElement(checker *C) {
+	latticePattern := newLatticePattern(checker, wallpaper.Square, wallpaper.P4m)
+	elements, err := symmetryelement.LatticeElements(
+		[]wallpaper.Symmetry{wallpaper.P4m},
+		latticePattern.Lattice,
+		complex(0.1, 0.1),
+		complex(0.9, 0.9),
+	)
+	checker.Assert(err, IsNil)
+	checker.Assert(elements.Mirrors, HasLen, 6)
+	checker.Assert(elements.Glides, HasLen, 4)
+
+	ordersByCenter := map[complex128]int{}
+	for _, rotationCenter := range elements.RotationCenters {
+		ordersByCenter[rotationCenter.Center] = rotationCenter.Order
+	}
+	checker.Assert(ordersByCenter, DeepEquals, map[complex128]int{
+		complex(0, 0):     4,
+		complex(0.5, 0):   2,
+		complex(0, 0.5):   2,
+		complex(0.5, 0.5): 4,
+	})
+}
+
+func (suite *SymmetryElementSuite) TestSymmetriesAreCombinedWithoutRepeats(checker *C) {
+	latticePattern := newLatticePattern(checker, wallpaper.Hexagonal, wallpaper.P6m)
+	combinedElements, err := symmetryelement.LatticeElements(
+		[]wallpaper.Symmetry{wallpaper.P1, wallpaper.P31m, wallpaper.P3m1, wallpaper.P6, wallpaper.P6m, wallpaper.P3},
+		latticePattern.Lattice,
+		complex(0.1, 0.1),
+		complex(0.2, 0.2),
+	)
+	checker.Assert(err, IsNil)
+	p6mElements, err := symmetryelement.LatticeElements(
+		[]wallpaper.Symmetry{wallpaper.P6m},
+		latticePattern.Lattice,
+		complex(0.1, 0.1),
+		complex(0.2, 0.2),
+	)
+	checker.Assert(err, IsNil)
+	checker.Assert(combinedElements, DeepEquals, p6mElements)
+	checker.Assert(combinedElements.RotationCenters[0].Order, Equals, 6)
+}
+
+func (suite *SymmetryElementSuite) TestLatticeElementsCoverTheRegion(checker *C) {
+	latticePattern := newLatticePattern(checker, wallpaper.Square, wallpaper.P4)
+	elements, err := symmetryelement.LatticeElements(
+		[]wallpaper.Symmetry{wallpaper.P4},
+		latticePattern.Lattice,
+		complex(-0.5, -0.5),
+		complex(2.5, 0.5),
+	)
+	checker.Assert(err, IsNil)
+	checker.Assert(elements.RotationCenters, HasLen, 4*4*2)
+}
+
+func (suite *SymmetryElementSuite) TestTooManyLatticeCellsIsAnError(checker *C) {
+	latticePattern := newLatticePattern(checker, wallpaper.Square, wallpaper.P4)
+	_, err := symmetryelement.LatticeElements(
+		[]wallpaper.Symmetry{wallpaper.P4},
+		latticePattern.Lattice,
+		complex(-100, -100),
+		complex(100, 100),
+	)
+	checker.Assert(err, ErrorMatches, "the region holds 40401 lattice cells, more than 10000")
+}
+
+func (suite *SymmetryElementSuite) TestFriezeElementsAreSymmetriesOfThePattern(checker *C) {
+	relationshipsBySymmetry := map[string]string{
+		"p211": `["-N-M"]`,
+		"p1m1": `["+M+N"]`,
+		"p11m": `["-M-N"]`,
+		"p11g": `["-M-NF(N+M)"]`,
+		"p2mm": `["-N-M", "+M+N", "-M-N"]`,
+		"p2mg": `["-N-M", "+M+NF(N+M)", "-M-NF(N+M)"]`,
+	}
+
+	for symmetryName, relationships := range relationshipsBySymmetry {
+		friezeFormula, err := frieze.NewFriezeFormulaFromJSON([]byte(`{
+			"terms": [
+				{
+					"multiplier": {"real": 1, "imaginary": 0},
+					"power_n": 1,
+					"power_m": 2,
+					"coefficient_relationships": ` + relationships + `
+				},
+				{
+					"multiplier": {"real": 0.5, "imaginary": -0.25},
+					"power_n": -3,
+					"power_m": 0,
+					"coefficient_relationships": ` + relationships + `
+				}
+			]
+		}`))
+		checker.Assert(err, IsNil)
+
+		elements, err := symmetryelement.FriezeElements(friezeFormula.AnalyzeForSymmetry(), complex(-4, -1), complex(8, 1))
+		checker.Assert(err, IsNil)
+		checker.Assert(len(elements.Mirrors)+len(elements.Glides)+len(elements.RotationCenters) > 0, Equals, true, Commentf("%s", symmetryName))
+		checkElementsAreSymmetries(checker, friezeFormula, elements, symmetryName)
+	}
+}
+
+func (suite *SymmetryElementSuite) TestP2mgFriezeElements(checker *C) {
+	elements, err := symmetryelement.FriezeElements(&frieze.Symmetry{P111: true, P211: true, P2mg: true}, complex(0.1, -1), complex(6, 1))
+	checker.Assert(err, IsNil)
+	checker.Assert(elements.Mirrors, DeepEquals, []symmetryelement.Line{
+		{Start: complex(frieze.Period/4, -1), End: complex(frieze.Period/4, 1)},
+		{Start: complex(3*frieze.Period/4, -1), End: complex(3*frieze.Period/4, 1)},
+	})
+	checker.Assert(elements.Glides, DeepEquals, []symmetryelement.Line{
+		{Start: complex(0.1, 0), End: complex(6, 0), Glide: complex(frieze.Period/2, 0)},
+	})
+	checker.Assert(elements.RotationCenters, DeepEquals, []symmetryelement.RotationCenter{
+		{Center: complex(0, 0), Order: 2},
+		{Center: complex(frieze.Period/2, 0), Order: 2},
+	})
+}
+
+func (suite *SymmetryElementSuite) TestFriezeAwayFromTheRealAxisOnlyHasVerticalMirrors(checker *C) {
+	elements, err := symmetryelement.FriezeElements(&frieze.Symmetry{P111: true, P2mm: true}, complex(0, 1), complex(1, 2))
+	checker.Assert(err, IsNil)
+	checker.Assert(elements.Mirrors, DeepEquals, []symmetryelement.Line{
+		{Start: complex(0, 1), End: complex(0, 2)},
+		{Start: complex(frieze.Period/2, 1), End: complex(frieze.Period/2, 2)},
+	})
+	checker.Assert(elements.Glides, HasLen, 0)
+	checker.Assert(elements.RotationCenters, HasLen, 0)
+}
+
+func (suite *SymmetryElementSuite) TestRosetteHasOneRotationCenter(checker *C) {
+	elements := symmetryelement.RosetteElements(&rosette.Symmetry{Multifold: 5}, complex(-1, -1), complex(1, 1))
+	checker.Assert(elements.RotationCenters, DeepEquals, []symmetryelement.RotationCenter{{Center: 0, Order: 5}})
+
+	offCenterElements := symmetryelement.RosetteElements(&rosette.Symmetry{Multifold: 5}, complex(1, 1), complex(2, 2))
+	checker.Assert(offCenterElements.RotationCenters, HasLen, 0)
+
+	asymmetricElements := symmetryelement.RosetteElements(&rosette.Symmetry{Multifold: 1}, complex(-1, -1), complex(1, 1))
+	checker.Assert(asymmetricElements.RotationCenters, HasLen, 0)
+}
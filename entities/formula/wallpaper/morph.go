@@ -0,0 +1,132 @@
+package wallpaper
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	eisensteinFormula "wallpaper/entities/formula"
+)
+
+// wavePacketPresence notes which of two aligned formulas a wave packet came from.
+type wavePacketPresence int
+
+const (
+	inBothFormulas wavePacketPresence = iota
+	onlyInFromFormula
+	onlyInToFormula
+)
+
+// wavePacketMatchKey identifies wave packets that can be blended into each other.
+//   Packets match when their terms have the same powers, in any order.
+func wavePacketMatchKey(wavePacket *WavePacket) string {
+	powers := []string{}
+	for _, term := range wavePacket.Terms {
+		powers = append(powers, fmt.Sprintf("%d %d", term.PowerN, term.PowerM))
+	}
+	sort.Strings(powers)
+	return strings.Join(powers, ",")
+}
+
+// AlignForMorph returns copies of two formulas with matching wave packets in the same order.
+//   Both formulas must be Setup with the same lattice, so the wave packets created for their symmetries are compared too.
+//   Wave packets match when their terms have the same powers.
+//   A wave packet found in only one formula is added to the other with a multiplier of 0.
+//   Each formula's Multiplier is moved into its wave packets, so the copies have a Multiplier of 1.
+//   The copies are already Setup; do not Setup them again.
+func AlignForMorph(from, to *Formula) (*Formula, *Formula, error) {
+	alignedFrom, alignedTo, _, err := alignWavePackets(from, to)
+	return alignedFrom, alignedTo, err
+}
+
+// Morph blends two formulas that are Setup with the same lattice.
+//   A ratio of 0 returns a formula that calculates like from, and 1 one that calculates like to.
+//   Wave packets with the same powers have their multipliers interpolated, and the others fade in or out.
+//   The blended formula is already Setup; do not Setup it again.
+func Morph(from, to *Formula, ratio float64) (*Formula, error) {
+	alignedFrom, alignedTo, presence, err := alignWavePackets(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	blendedWavePackets := []*WavePacket{}
+	for index, fromWavePacket := range alignedFrom.WavePackets {
+		if (presence[index] == onlyInToFormula && ratio == 0) || (presence[index] == onlyInFromFormula && ratio == 1) {
+			continue
+		}
+		toMultiplier := alignedTo.WavePackets[index].Multiplier
+		blendedWavePackets = append(blendedWavePackets, &WavePacket{
+			Terms:      fromWavePacket.Terms,
+			Multiplier: fromWavePacket.Multiplier + (toMultiplier-fromWavePacket.Multiplier)*complex(ratio, 0),
+		})
+	}
+	alignedFrom.WavePackets = blendedWavePackets
+	return alignedFrom, nil
+}
+
+// alignWavePackets lines up the wave packets like AlignForMorph, and notes where each aligned packet came from.
+func alignWavePackets(from, to *Formula) (*Formula, *Formula, []wavePacketPresence, error) {
+	if from.Lattice == nil || to.Lattice == nil {
+		return nil, nil, nil, errors.New(`lattice patterns must be set up before they morph`)
+	}
+	if *from.Lattice != *to.Lattice {
+		return nil, nil, nil, errors.New(`lattice patterns must use the same lattice to morph`)
+	}
+
+	unmatchedToIndicesByKey := map[string][]int{}
+	for toIndex, toWavePacket := range to.WavePackets {
+		key := wavePacketMatchKey(toWavePacket)
+		unmatchedToIndicesByKey[key] = append(unmatchedToIndicesByKey[key], toIndex)
+	}
+
+	alignedFromPackets := []*WavePacket{}
+	alignedToPackets := []*WavePacket{}
+	presence := []wavePacketPresence{}
+	toWavePacketIsMatched := make([]bool, len(to.WavePackets))
+	for _, fromWavePacket := range from.WavePackets {
+		alignedFromPackets = append(alignedFromPackets, copyWavePacket(fromWavePacket, from.Multiplier))
+
+		key := wavePacketMatchKey(fromWavePacket)
+		if toIndices := unmatchedToIndicesByKey[key]; len(toIndices) > 0 {
+			unmatchedToIndicesByKey[key] = toIndices[1:]
+			toWavePacketIsMatched[toIndices[0]] = true
+			alignedToPackets = append(alignedToPackets, copyWavePacket(to.WavePackets[toIndices[0]], to.Multiplier))
+			presence = append(presence, inBothFormulas)
+			continue
+		}
+
+		alignedToPackets = append(alignedToPackets, copyWavePacket(fromWavePacket, 0))
+		presence = append(presence, onlyInFromFormula)
+	}
+
+	for toIndex, toWavePacket := range to.WavePackets {
+		if toWavePacketIsMatched[toIndex] {
+			continue
+		}
+		alignedToPackets = append(alignedToPackets, copyWavePacket(toWavePacket, to.Multiplier))
+		alignedFromPackets = append(alignedFromPackets, copyWavePacket(toWavePacket, 0))
+		presence = append(presence, onlyInToFormula)
+	}
+
+	alignedFrom := from.Copy()
+	alignedFrom.Multiplier = 1
+	alignedFrom.WavePackets = alignedFromPackets
+
+	alignedTo := to.Copy()
+	alignedTo.Multiplier = 1
+	alignedTo.WavePackets = alignedToPackets
+	return alignedFrom, alignedTo, presence, nil
+}
+
+// copyWavePacket copies the wave packet and its terms, scaling its multiplier.
+func copyWavePacket(wavePacket *WavePacket, scale complex128) *WavePacket {
+	copiedTerms := []*eisensteinFormula.EisensteinFormulaTerm{}
+	for _, term := range wavePacket.Terms {
+		copiedTerm := *term
+		copiedTerms = append(copiedTerms, &copiedTerm)
+	}
+	return &WavePacket{
+		Terms:      copiedTerms,
+		Multiplier: wavePacket.Multiplier * scale,
+	}
+}
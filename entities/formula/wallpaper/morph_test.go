@@ -0,0 +1,88 @@
+package wallpaper_test
+
+import (
+	. "gopkg.in/check.v1"
+	"wallpaper/entities/formula"
+	"wallpaper/entities/formula/wallpaper"
+	"wallpaper/entities/utility"
+)
+
+type MorphSuite struct {
+	p4mPattern *wallpaper.Formula
+	p4gPattern *wallpaper.Formula
+}
+
+var _ = Suite(&MorphSuite{})
+
+func newSquarePattern(checker *C, desiredSymmetry wallpaper.Symmetry, multiplier complex128) *wallpaper.Formula {
+	pattern := &wallpaper.Formula{
+		LatticeType: wallpaper.Square,
+		LatticeSize: &wallpaper.Dimensions{},
+		Multiplier:  multiplier,
+		WavePackets: []*wallpaper.WavePacket{
+			{
+				Terms:      []*formula.EisensteinFormulaTerm{{PowerN: 1, PowerM: 2}},
+				Multiplier: complex(1, 0.5),
+			},
+		},
+		DesiredSymmetry: desiredSymmetry,
+	}
+	checker.Assert(pattern.Setup(), IsNil)
+	return pattern
+}
+
+func (suite *MorphSuite) SetUpTest(checker *C) {
+	suite.p4mPattern = newSquarePattern(checker, wallpaper.P4m, complex(1, 0))
+	suite.p4gPattern = newSquarePattern(checker, wallpaper.P4g, complex(2, 0))
+}
+
+func (suite *MorphSuite) TestMorphEndsCalculateLikeTheFormulas(checker *C) {
+	z := complex(0.2, 0.7)
+	for ratio, expectedPattern := range map[float64]*wallpaper.Formula{0: suite.p4mPattern, 1: suite.p4gPattern} {
+		blendedPattern, err := wallpaper.Morph(suite.p4mPattern, suite.p4gPattern, ratio)
+		checker.Assert(err, IsNil)
+
+		blendedTotal := blendedPattern.Calculate(z).Total
+		expectedTotal := expectedPattern.Calculate(z).Total
+		checker.Assert(real(blendedTotal), utility.NumericallyCloseEnough{}, real(expectedTotal), 1e-6)
+		checker.Assert(imag(blendedTotal), utility.NumericallyCloseEnough{}, imag(expectedTotal), 1e-6)
+	}
+}
+
+func (suite *MorphSuite) TestMorphHalfwayKeepsTheSharedSymmetry(checker *C) {
+	blendedPattern, err := wallpaper.Morph(suite.p4mPattern, suite.p4gPattern, 0.5)
+	checker.Assert(err, IsNil)
+
+	checker.Assert(blendedPattern.HasSymmetry(wallpaper.P4), Equals, true)
+	checker.Assert(blendedPattern.HasSymmetry(wallpaper.P4m), Equals, false)
+	checker.Assert(blendedPattern.HasSymmetry(wallpaper.P4g), Equals, false)
+}
+
+func (suite *MorphSuite) TestAlignForMorphMatchesWavePackets(checker *C) {
+	alignedFrom, alignedTo, err := wallpaper.AlignForMorph(suite.p4mPattern, suite.p4gPattern)
+	checker.Assert(err, IsNil)
+	checker.Assert(alignedFrom.WavePackets, HasLen, len(suite.p4mPattern.WavePackets))
+	checker.Assert(alignedTo.WavePackets, HasLen, len(alignedFrom.WavePackets))
+	checker.Assert(alignedTo.Multiplier, Equals, complex(1, 0))
+	checker.Assert(alignedTo.WavePackets[0].Multiplier, Equals, complex(2, 1))
+	checker.Assert(suite.p4gPattern.WavePackets[0].Multiplier, Equals, complex(1, 0.5))
+}
+
+func (suite *MorphSuite) TestMorphNeedsTheSameLattice(checker *C) {
+	rectangularPattern := &wallpaper.Formula{
+		LatticeType: wallpaper.Rectangular,
+		LatticeSize: &wallpaper.Dimensions{Width: 1, Height: 2},
+		Multiplier:  complex(1, 0),
+		WavePackets: []*wallpaper.WavePacket{
+			{
+				Terms:      []*formula.EisensteinFormulaTerm{{PowerN: 1, PowerM: 2}},
+				Multiplier: complex(1, 0),
+			},
+		},
+		DesiredSymmetry: wallpaper.P1,
+	}
+	checker.Assert(rectangularPattern.Setup(), IsNil)
+
+	_, err := wallpaper.Morph(suite.p4mPattern, rectangularPattern, 0.5)
+	checker.Assert(err, ErrorMatches, "lattice patterns must use the same lattice to morph")
+}
@@ -0,0 +1,66 @@
+package wallpaper
+
+import (
+	"wallpaper/entities/formula/result"
+)
+
+// RowReanchorInterval is how many points CalculateRow advances before recalculating every term exactly.
+const RowReanchorInterval = 64
+
+// CalculateRow applies the formula to count evenly spaced points: start, start + step, start + 2*step...
+//   The formula must be Setup first.
+//   Lattice coordinates change linearly along the row, so instead of calling cmplx.Exp
+//   for every term at every point, each term is advanced by multiplying it with a constant step.
+//   Every RowReanchorInterval points the terms are recalculated exactly, so rounding errors cannot build up.
+//   Results match Calculate to within floating point rounding.
+func (formula *Formula) CalculateRow(start, step complex128, count int) []*result.CalculationResultForFormula {
+	stepInLatticeCoordinates := formula.Lattice.ConvertToLatticeCoordinates(step)
+
+	currentTermValues := [][]complex128{}
+	termStepMultipliers := [][]complex128{}
+	for _, wavePacket := range formula.WavePackets {
+		stepMultipliers := []complex128{}
+		for _, term := range wavePacket.Terms {
+			stepMultipliers = append(stepMultipliers, term.Calculate(stepInLatticeCoordinates))
+		}
+		termStepMultipliers = append(termStepMultipliers, stepMultipliers)
+		currentTermValues = append(currentTermValues, make([]complex128, len(wavePacket.Terms)))
+	}
+
+	rowResults := make([]*result.CalculationResultForFormula, count)
+	for pointIndex := 0; pointIndex < count; pointIndex++ {
+		if pointIndex%RowReanchorInterval == 0 {
+			point := start + complex(float64(pointIndex), 0)*step
+			formula.anchorTermValues(point, currentTermValues)
+		}
+
+		pointResult := &result.CalculationResultForFormula{
+			Total:              complex(0, 0),
+			ContributionByTerm: make([]complex128, 0, len(formula.WavePackets)),
+		}
+		for packetIndex, wavePacket := range formula.WavePackets {
+			packetTotal := complex(0, 0)
+			for termIndex, termValue := range currentTermValues[packetIndex] {
+				packetTotal += termValue
+				currentTermValues[packetIndex][termIndex] = termValue * termStepMultipliers[packetIndex][termIndex]
+			}
+			packetTotal *= wavePacket.Multiplier
+
+			pointResult.Total += packetTotal / complex(float64(len(wavePacket.Terms)), 0)
+			pointResult.ContributionByTerm = append(pointResult.ContributionByTerm, packetTotal)
+		}
+		pointResult.Total *= formula.Multiplier
+		rowResults[pointIndex] = pointResult
+	}
+	return rowResults
+}
+
+// anchorTermValues calculates every term exactly at point.
+func (formula *Formula) anchorTermValues(point complex128, termValues [][]complex128) {
+	pointInLatticeCoordinates := formula.Lattice.ConvertToLatticeCoordinates(point)
+	for packetIndex, wavePacket := range formula.WavePackets {
+		for termIndex, term := range wavePacket.Terms {
+			termValues[packetIndex][termIndex] = term.Calculate(pointInLatticeCoordinates)
+		}
+	}
+}
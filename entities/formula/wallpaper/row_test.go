@@ -0,0 +1,66 @@
+package wallpaper_test
+
+import (
+	. "gopkg.in/check.v1"
+	"math/cmplx"
+	"wallpaper/entities/formula"
+	"wallpaper/entities/formula/wallpaper"
+)
+
+type CalculateRowSuite struct {
+	hexagonalFormula *wallpaper.Formula
+}
+
+var _ = Suite(&CalculateRowSuite{})
+
+func (suite *CalculateRowSuite) SetUpTest(checker *C) {
+	suite.hexagonalFormula = &wallpaper.Formula{
+		LatticeType: wallpaper.Hexagonal,
+		LatticeSize: &wallpaper.Dimensions{},
+		Multiplier:  complex(1, 1e-2),
+		WavePackets: []*wallpaper.WavePacket{
+			{
+				Terms:      []*formula.EisensteinFormulaTerm{{PowerN: 1, PowerM: -2}},
+				Multiplier: complex(0.5, 1),
+			},
+			{
+				Terms:      []*formula.EisensteinFormulaTerm{{PowerN: 7, PowerM: -13}},
+				Multiplier: complex(-0.25, 0.5),
+			},
+		},
+		DesiredSymmetry: wallpaper.P6m,
+	}
+	checker.Assert(suite.hexagonalFormula.Setup(), IsNil)
+}
+
+func (suite *CalculateRowSuite) TestRowMatchesExactCalculation(checker *C) {
+	start := complex(-3.1, 1.7)
+	step := complex(6.0/1000.0, 0)
+	rowResults := suite.hexagonalFormula.CalculateRow(start, step, 1000)
+
+	checker.Assert(rowResults, HasLen, 1000)
+	for pointIndex, rowResult := range rowResults {
+		exactResult := suite.hexagonalFormula.Calculate(start + complex(float64(pointIndex), 0)*step)
+		checker.Assert(cmplx.Abs(rowResult.Total-exactResult.Total) < 1e-9, Equals, true)
+		checker.Assert(rowResult.ContributionByTerm, HasLen, len(exactResult.ContributionByTerm))
+		for termIndex, contribution := range rowResult.ContributionByTerm {
+			checker.Assert(cmplx.Abs(contribution-exactResult.ContributionByTerm[termIndex]) < 1e-9, Equals, true)
+		}
+	}
+}
+
+func (suite *CalculateRowSuite) TestRowCanBeDiagonal(checker *C) {
+	start := complex(0.2, -0.4)
+	step := complex(1e-3, 2e-3)
+	rowResults := suite.hexagonalFormula.CalculateRow(start, step, 300)
+
+	for pointIndex, rowResult := range rowResults {
+		exactResult := suite.hexagonalFormula.Calculate(start + complex(float64(pointIndex), 0)*step)
+		checker.Assert(cmplx.Abs(rowResult.Total-exactResult.Total) < 1e-9, Equals, true)
+	}
+}
+
+func (suite *CalculateRowSuite) TestFirstPointIsExact(checker *C) {
+	rowResults := suite.hexagonalFormula.CalculateRow(complex(0.5, 0.5), complex(0.1, 0), 1)
+	checker.Assert(rowResults[0], DeepEquals, suite.hexagonalFormula.Calculate(complex(0.5, 0.5)))
+}
@@ -242,4 +242,12 @@ const (
 	Pgg  Symmetry = "pgg"
 	Pmm  Symmetry = "pmm"
 	Pmg  Symmetry = "pmg"
-)
\ No newline at end of file
+)
+// IsKnown returns true if the symmetry is one of the 17 wallpaper groups.
+func (symmetry Symmetry) IsKnown() bool {
+	switch symmetry {
+	case P1, P2, P3, P3m1, P31m, P6, P6m, P4, P4m, P4g, Cm, Cmm, Pm, Pg, Pgg, Pmm, Pmg:
+		return true
+	}
+	return false
+}
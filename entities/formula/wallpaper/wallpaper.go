@@ -2,6 +2,8 @@ package wallpaper
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"gopkg.in/yaml.v2"
 	eisensteinFormula "wallpaper/entities/formula"
 	"wallpaper/entities/formula/coefficient"
@@ -109,6 +111,58 @@ func NewFormulaFromMarshalObject(marshaledFormula FormulaMarshal) *Formula {
 	}
 }
 
+// MarshalObject converts the formula into an object that can be marshaled.
+//   Wave packets added by Setup are included, so marshal formulas before they are Setup.
+func (formula *Formula) MarshalObject() *FormulaMarshal {
+	wavePacketMarshals := []*Marshal{}
+	for _, wavePacket := range formula.WavePackets {
+		wavePacketMarshals = append(wavePacketMarshals, wavePacket.MarshalObject())
+	}
+
+	var latticeSize *DimensionsMarshal
+	if formula.LatticeSize != nil {
+		latticeSize = &DimensionsMarshal{Width: formula.LatticeSize.Width, Height: formula.LatticeSize.Height}
+	}
+
+	return &FormulaMarshal{
+		LatticeType:     string(formula.LatticeType),
+		LatticeSize:     latticeSize,
+		Multiplier:      utility.ComplexNumberForMarshal{Real: real(formula.Multiplier), Imaginary: imag(formula.Multiplier)},
+		WavePackets:     wavePacketMarshals,
+		DesiredSymmetry: string(formula.DesiredSymmetry),
+	}
+}
+
+// Copy returns a deep copy of the formula, so the copy can be Setup without changing the original.
+func (formula *Formula) Copy() *Formula {
+	copiedFormula := *formula
+
+	if formula.LatticeSize != nil {
+		copiedLatticeSize := *formula.LatticeSize
+		copiedFormula.LatticeSize = &copiedLatticeSize
+	}
+
+	if formula.Lattice != nil {
+		copiedLattice := *formula.Lattice
+		copiedFormula.Lattice = &copiedLattice
+	}
+
+	copiedFormula.WavePackets = []*WavePacket{}
+	for _, wavePacket := range formula.WavePackets {
+		copiedTerms := []*eisensteinFormula.EisensteinFormulaTerm{}
+		for _, term := range wavePacket.Terms {
+			copiedTerm := *term
+			copiedTerms = append(copiedTerms, &copiedTerm)
+		}
+		copiedFormula.WavePackets = append(copiedFormula.WavePackets, &WavePacket{
+			Terms:      copiedTerms,
+			Multiplier: wavePacket.Multiplier,
+		})
+	}
+
+	return &copiedFormula
+}
+
 // Setup creates lattice vectors and locked in Eisenstein pairs based on the Lattice Type.
 //  modifies the given Formula.
 //  returns any errors (returns nil if there are no errors)
@@ -125,6 +179,37 @@ func (formula *Formula) Setup() error {
 	return nil
 }
 
+// Validate returns an error if the formula cannot be set up and calculated.
+//  The formula is not modified.
+func (formula *Formula) Validate() error {
+	if !formula.DesiredSymmetry.IsKnown() {
+		return fmt.Errorf(`unknown desired symmetry: %s`, formula.DesiredSymmetry)
+	}
+
+	if len(formula.WavePackets) == 0 {
+		return errors.New(`formula must have at least one wave packet`)
+	}
+	for _, wavePacket := range formula.WavePackets {
+		if len(wavePacket.Terms) == 0 {
+			return errors.New(`wave packets must have at least one term`)
+		}
+	}
+
+	formulaWithVectors := *formula
+	return formulaWithVectors.createVectors()
+}
+
+// RectangularRepeat returns how patterns made with the formula's lattice repeat along the real and imaginary axes.
+//  The formula is not modified, so it does not need to be Setup first.
+func (formula *Formula) RectangularRepeat() (*latticevector.RectangularRepeat, error) {
+	formulaWithVectors := *formula
+	vectorErr := formulaWithVectors.createVectors()
+	if vectorErr != nil {
+		return nil, vectorErr
+	}
+	return formulaWithVectors.Lattice.RectangularRepeat()
+}
+
 func (formula *Formula) createVectors() error {
 	type VectorCreator func(formula *Formula) error
 
@@ -136,7 +221,12 @@ func (formula *Formula) createVectors() error {
 		Rectangular: createVectorsForRectangularWallpaper,
 	}
 
-	customErr := vectorCreatorBasedOnLatticeType[formula.LatticeType](formula)
+	vectorCreator := vectorCreatorBasedOnLatticeType[formula.LatticeType]
+	if vectorCreator == nil {
+		return fmt.Errorf(`unknown lattice type: %s`, formula.LatticeType)
+	}
+
+	customErr := vectorCreator(formula)
 	if customErr != nil {
 		return customErr
 	}
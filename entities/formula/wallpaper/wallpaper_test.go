@@ -6,6 +6,7 @@ import (
 	"testing"
 	"wallpaper/entities/formula"
 	"wallpaper/entities/formula/wallpaper"
+	"wallpaper/entities/utility"
 )
 
 func Test(t *testing.T) { TestingT(t) }
@@ -229,5 +230,152 @@ func (suite *MakeNewFormulaBasedOnLatticeShape) TestSetupThrowsAnErrorIfVectorsA
 	checker.Assert(err, ErrorMatches, "vectors cannot be collinear: (.*,.*) and (.*,.*)")
 }
 
+func (suite *MakeNewFormulaBasedOnLatticeShape) TestSetupThrowsAnErrorForUnknownLatticeType(checker *C) {
+	newFormula := wallpaper.Formula{
+		LatticeType:     "pentagonal",
+		LatticeSize:     &wallpaper.Dimensions{},
+		Multiplier:      complex(1, 0),
+		WavePackets:     []*wallpaper.WavePacket{
+			{
+				Multiplier: complex(1, 0),
+				Terms: []*formula.EisensteinFormulaTerm{
+					{
+						PowerN: 1,
+						PowerM: -4,
+					},
+				},
+			},
+		},
+		DesiredSymmetry: wallpaper.P1,
+	}
+
+	err := newFormula.Setup()
+	checker.Assert(err, ErrorMatches, "unknown lattice type: pentagonal")
+}
+
+func (suite *MakeNewFormulaBasedOnLatticeShape) TestValidateDoesNotChangeTheFormula(checker *C) {
+	newFormula := wallpaper.Formula{
+		LatticeType:     wallpaper.Square,
+		LatticeSize:     &wallpaper.Dimensions{},
+		Multiplier:      complex(1, 0),
+		WavePackets:     []*wallpaper.WavePacket{
+			{
+				Multiplier: complex(1, 0),
+				Terms: []*formula.EisensteinFormulaTerm{
+					{
+						PowerN: 1,
+						PowerM: -4,
+					},
+				},
+			},
+		},
+		DesiredSymmetry: wallpaper.P4m,
+	}
+
+	err := newFormula.Validate()
+	checker.Assert(err, IsNil)
+	checker.Assert(newFormula.Lattice, IsNil)
+	checker.Assert(newFormula.WavePackets, HasLen, 1)
+	checker.Assert(newFormula.WavePackets[0].Terms, HasLen, 1)
+}
+
+func (suite *MakeNewFormulaBasedOnLatticeShape) TestValidateRejectsUnknownSymmetry(checker *C) {
+	newFormula := wallpaper.Formula{
+		LatticeType:     wallpaper.Square,
+		LatticeSize:     &wallpaper.Dimensions{},
+		Multiplier:      complex(1, 0),
+		WavePackets:     []*wallpaper.WavePacket{
+			{
+				Multiplier: complex(1, 0),
+				Terms: []*formula.EisensteinFormulaTerm{
+					{
+						PowerN: 1,
+						PowerM: -4,
+					},
+				},
+			},
+		},
+		DesiredSymmetry: "p5",
+	}
+
+	checker.Assert(newFormula.Validate(), ErrorMatches, "unknown desired symmetry: p5")
+}
+
+func (suite *MakeNewFormulaBasedOnLatticeShape) TestValidateRejectsEmptyWavePackets(checker *C) {
+	newFormula := wallpaper.Formula{
+		LatticeType:     wallpaper.Square,
+		LatticeSize:     &wallpaper.Dimensions{},
+		Multiplier:      complex(1, 0),
+		WavePackets:     []*wallpaper.WavePacket{
+			{
+				Multiplier: complex(1, 0),
+				Terms: []*formula.EisensteinFormulaTerm{},
+			},
+		},
+		DesiredSymmetry: wallpaper.P1,
+	}
+
+	checker.Assert(newFormula.Validate(), ErrorMatches, "wave packets must have at least one term")
+}
+
+func (suite *MakeNewFormulaBasedOnLatticeShape) TestCopyCanBeSetupWithoutChangingTheOriginal(checker *C) {
+	originalFormula := &wallpaper.Formula{
+		LatticeType:     wallpaper.Square,
+		LatticeSize:     &wallpaper.Dimensions{},
+		Multiplier:      complex(1, 0),
+		WavePackets:     []*wallpaper.WavePacket{
+			{
+				Multiplier: complex(1, 0),
+				Terms: []*formula.EisensteinFormulaTerm{
+					{
+						PowerN: 1,
+						PowerM: -4,
+					},
+				},
+			},
+		},
+		DesiredSymmetry: wallpaper.P4m,
+	}
+
+	copiedFormula := originalFormula.Copy()
+	err := copiedFormula.Setup()
+	checker.Assert(err, IsNil)
+
+	checker.Assert(copiedFormula.WavePackets, HasLen, 2)
+	checker.Assert(copiedFormula.WavePackets[0].Terms, HasLen, 4)
+
+	checker.Assert(originalFormula.Lattice, IsNil)
+	checker.Assert(originalFormula.WavePackets, HasLen, 1)
+	checker.Assert(originalFormula.WavePackets[0].Terms, HasLen, 1)
+}
+
+func (suite *MakeNewFormulaBasedOnLatticeShape) TestRectangularRepeatUsesTheLatticeVectors(checker *C) {
+	newFormula := wallpaper.Formula{
+		LatticeType:     wallpaper.Rhombic,
+		LatticeSize:     &wallpaper.Dimensions{Height: 0.25},
+		Multiplier:      complex(1, 0),
+		WavePackets:     []*wallpaper.WavePacket{
+			{
+				Multiplier: complex(1, 0),
+				Terms: []*formula.EisensteinFormulaTerm{
+					{
+						PowerN: 1,
+						PowerM: -2,
+					},
+				},
+			},
+		},
+		DesiredSymmetry: wallpaper.Cm,
+	}
+
+	repeat, err := newFormula.RectangularRepeat()
+	checker.Assert(err, IsNil)
+	checker.Assert(repeat.Width, utility.NumericallyCloseEnough{}, 1.0, 1e-6)
+	checker.Assert(repeat.Height, utility.NumericallyCloseEnough{}, 0.5, 1e-6)
+	checker.Assert(repeat.IsHalfDrop(), Equals, true)
+	checker.Assert(newFormula.Lattice, IsNil)
+	checker.Assert(newFormula.WavePackets[0].Terms, HasLen, 1)
+}
+
 // (Start making tests for Hex and Generic wallpapers)
-// (Like Symmetry checks)
\ No newline at end of file
+// (Like Symmetry checks)
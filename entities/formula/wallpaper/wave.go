@@ -77,6 +77,18 @@ func NewWaveFormulaFromMarshalObject(marshalObject Marshal) *WavePacket {
 	}
 }
 
+// MarshalObject converts the wave packet into an object that can be marshaled.
+func (waveFormula *WavePacket) MarshalObject() *Marshal {
+	termMarshals := []*formula.EisensteinFormulaTermMarshal{}
+	for _, term := range waveFormula.Terms {
+		termMarshals = append(termMarshals, term.MarshalObject())
+	}
+	return &Marshal{
+		Terms:      termMarshals,
+		Multiplier: utility.ComplexNumberForMarshal{Real: real(waveFormula.Multiplier), Imaginary: imag(waveFormula.Multiplier)},
+	}
+}
+
 // GetWavePacketRelationship returns a list of relationships that all of the wave packets conform to.
 func GetWavePacketRelationship(wavePacket1, wavePacket2 *WavePacket) []coefficient.Relationship {
 	if wavePacket1 == nil || wavePacket2 == nil {
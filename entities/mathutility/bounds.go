@@ -15,6 +15,12 @@ func ScaleValueBetweenTwoRanges(value, oldRangeMin, oldRangeMax, newRangeMin, ne
 		return newRangeMax
 	}
 
+	return ExtendValueBetweenTwoRanges(value, oldRangeMin, oldRangeMax, newRangeMin, newRangeMax)
+}
+
+// ExtendValueBetweenTwoRanges works like ScaleValueBetweenTwoRanges, but value may lie outside of min1 and max1.
+//   The linear scale continues past both ends instead of stopping at min2 or max2.
+func ExtendValueBetweenTwoRanges(value, oldRangeMin, oldRangeMax, newRangeMin, newRangeMax float64) float64 {
 	distanceAcrossOldRange := oldRangeMax - oldRangeMin
 	valueDistanceAcrossOldRange := value - oldRangeMin
 	ratioAcrossRange := valueDistanceAcrossOldRange / distanceAcrossOldRange
@@ -27,25 +33,45 @@ func ScaleValueBetweenTwoRanges(value, oldRangeMin, oldRangeMax, newRangeMin, ne
 //   The second number is the maximum (all numbers have a real & imaginary component less than or equal to it)
 //   If numbers is an empty slice, returns (0+0i, 0+0i)
 func GetBoundingBox(numbers []complex128) (complex128, complex128) {
-	minX, minY, maxX, maxY := 0.0, 0.0, 0.0, 0.0
+	box := BoundingBox{}
 	for _, number := range numbers {
-		if cmplx.IsInf(number) {
-			continue
-		}
-
-		if real(number) < minX {
-			minX = real(number)
-		}
-		if real(number) > maxX {
-			maxX = real(number)
-		}
-
-		if imag(number) < minY {
-			minY = imag(number)
-		}
-		if imag(number) > maxY {
-			maxY = imag(number)
-		}
-	}
-	return complex(minX, minY), complex(maxX, maxY)
-}
\ No newline at end of file
+		box.Add(number)
+	}
+	return box.Min, box.Max
+}
+
+// BoundingBox grows to contain numbers one at a time, so large sets never have to be held in memory.
+//   The zero value starts at (0+0i, 0+0i), matching GetBoundingBox.
+type BoundingBox struct {
+	Min complex128
+	Max complex128
+}
+
+// Add grows the box to contain number. Infinite numbers are ignored.
+func (box *BoundingBox) Add(number complex128) {
+	if cmplx.IsInf(number) {
+		return
+	}
+
+	minX, minY, maxX, maxY := real(box.Min), imag(box.Min), real(box.Max), imag(box.Max)
+	if real(number) < minX {
+		minX = real(number)
+	}
+	if real(number) > maxX {
+		maxX = real(number)
+	}
+
+	if imag(number) < minY {
+		minY = imag(number)
+	}
+	if imag(number) > maxY {
+		maxY = imag(number)
+	}
+	box.Min, box.Max = complex(minX, minY), complex(maxX, maxY)
+}
+
+// Merge grows the box to contain the other box.
+func (box *BoundingBox) Merge(other BoundingBox) {
+	box.Add(other.Min)
+	box.Add(other.Max)
+}
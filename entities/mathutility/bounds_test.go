@@ -5,6 +5,7 @@ import (
 	"math/cmplx"
 	"testing"
 	"wallpaper/entities/mathutility"
+	"wallpaper/entities/utility"
 )
 
 func Test(t *testing.T) { TestingT(t) }
@@ -62,6 +63,21 @@ func (suite *BoundsTestSuite) TestScaleNewRange(checker *C) {
 		200.0) - 100 < 0.01, Equals, true)
 }
 
+func (suite *BoundsTestSuite) TestExtendPastTheEndsOfTheRange(checker *C) {
+	checker.Assert(mathutility.ExtendValueBetweenTwoRanges(
+		-200,
+		-100.0,
+		100.0,
+		0,
+		200.0), utility.NumericallyCloseEnough{}, -100, 0.01)
+	checker.Assert(mathutility.ExtendValueBetweenTwoRanges(
+		150,
+		-100.0,
+		100.0,
+		0,
+		200.0), utility.NumericallyCloseEnough{}, 250, 0.01)
+}
+
 func (suite *BoundsTestSuite) TestBoundingBoxCalculation(checker *C) {
 	lotsOfComplexNumbers := []complex128{
 		complex(0, 0),
@@ -107,3 +123,32 @@ func (suite *BoundsTestSuite) TestBoundingBoxIgnoresInfinity(checker *C) {
 	checker.Assert(real(max)- 10 < 0.01, Equals, true)
 	checker.Assert(imag(max)- 10 < 0.01, Equals, true)
 }
+
+func (suite *BoundsTestSuite) TestBoundingBoxGrowsOneNumberAtATime(checker *C) {
+	box := mathutility.BoundingBox{}
+	box.Add(complex(3, -2))
+	box.Add(cmplx.Inf())
+	box.Add(complex(-1, 5))
+
+	checker.Assert(box.Min, Equals, complex(-1, -2))
+	checker.Assert(box.Max, Equals, complex(3, 5))
+}
+
+func (suite *BoundsTestSuite) TestMergedBoundingBoxesMatchTheWholeSet(checker *C) {
+	firstHalf := []complex128{complex(3, -2), complex(-7, 1)}
+	secondHalf := []complex128{complex(2, 9), complex(1, -4)}
+
+	firstBox := mathutility.BoundingBox{}
+	for _, number := range firstHalf {
+		firstBox.Add(number)
+	}
+	secondBox := mathutility.BoundingBox{}
+	for _, number := range secondHalf {
+		secondBox.Add(number)
+	}
+	firstBox.Merge(secondBox)
+
+	min, max := mathutility.GetBoundingBox(append(firstHalf, secondHalf...))
+	checker.Assert(firstBox.Min, Equals, min)
+	checker.Assert(firstBox.Max, Equals, max)
+}
@@ -0,0 +1,25 @@
+package mathutility
+
+import "sort"
+
+// Percentile returns the value at the given percentile (from 0 to 100) of values.
+//   Values between two entries are linearly interpolated.
+//   values is sorted in place. If values is an empty slice, returns 0.
+func Percentile(values []float64, percentile float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+
+	position := percentile / 100 * float64(len(values)-1)
+	lowerIndex := int(position)
+	if lowerIndex >= len(values)-1 {
+		return values[len(values)-1]
+	}
+	if lowerIndex < 0 {
+		return values[0]
+	}
+
+	ratioToNextValue := position - float64(lowerIndex)
+	return values[lowerIndex] + ratioToNextValue*(values[lowerIndex+1]-values[lowerIndex])
+}
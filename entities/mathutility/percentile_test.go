@@ -0,0 +1,28 @@
+package mathutility_test
+
+import (
+	. "gopkg.in/check.v1"
+	"wallpaper/entities/mathutility"
+	"wallpaper/entities/utility"
+)
+
+type PercentileSuite struct {
+}
+
+var _ = Suite(&PercentileSuite{})
+
+func (suite *PercentileSuite) TestEndsAreTheMinimumAndMaximum(checker *C) {
+	values := []float64{5, -3, 10, 2}
+	checker.Assert(mathutility.Percentile(values, 0), Equals, -3.0)
+	checker.Assert(mathutility.Percentile(values, 100), Equals, 10.0)
+}
+
+func (suite *PercentileSuite) TestInterpolatesBetweenValues(checker *C) {
+	values := []float64{0, 10, 20, 30, 40}
+	checker.Assert(mathutility.Percentile(values, 50), Equals, 20.0)
+	checker.Assert(mathutility.Percentile(values, 10), utility.NumericallyCloseEnough{}, 4, 1e-9)
+}
+
+func (suite *PercentileSuite) TestEmptyValuesAreZero(checker *C) {
+	checker.Assert(mathutility.Percentile([]float64{}, 50), Equals, 0.0)
+}
@@ -0,0 +1,106 @@
+package render
+
+import (
+	"errors"
+	"wallpaper/entities/command"
+	"wallpaper/entities/formula/frieze"
+	"wallpaper/entities/formula/latticevector"
+	"wallpaper/entities/formula/result"
+	"wallpaper/entities/formula/rosette"
+	"wallpaper/entities/formula/wallpaper"
+)
+
+// SymmetryAnalysis lists the symmetries found in the formula that will be rendered.
+//   Only the field for that formula's pattern type is filled in.
+type SymmetryAnalysis struct {
+	Frieze  *frieze.Symmetry
+	Rosette *rosette.Symmetry
+	Lattice []wallpaper.Symmetry
+	// LatticeVectors places the lattice symmetries in the sample space. It is set along with Lattice.
+	LatticeVectors *latticevector.Pair
+}
+
+// latticeSymmetriesToCheck is the order lattice symmetries are reported in.
+var latticeSymmetriesToCheck = []wallpaper.Symmetry{
+	wallpaper.P1,
+	wallpaper.P2,
+	wallpaper.P31m,
+	wallpaper.P3m1,
+	wallpaper.P6,
+	wallpaper.P6m,
+	wallpaper.P3,
+	wallpaper.P4,
+	wallpaper.P4m,
+	wallpaper.P4g,
+	wallpaper.Cm,
+	wallpaper.Cmm,
+	wallpaper.Pm,
+	wallpaper.Pg,
+	wallpaper.Pmm,
+	wallpaper.Pmg,
+	wallpaper.Pgg,
+}
+
+// formulaCalculator transforms a single point using a pattern formula.
+type formulaCalculator interface {
+	Calculate(z complex128) *result.CalculationResultForFormula
+}
+
+// rowCalculator can transform a whole row of evenly spaced points faster than one point at a time.
+type rowCalculator interface {
+	CalculateRow(start, step complex128, count int) []*result.CalculationResultForFormula
+}
+
+// AnalyzeSymmetry returns the symmetries of the formula the command would render.
+//   The command is not modified.
+func AnalyzeSymmetry(wallpaperCommand *command.CreateSymmetryPattern) (*SymmetryAnalysis, error) {
+	_, symmetryAnalysis, err := prepareFormula(wallpaperCommand)
+	return symmetryAnalysis, err
+}
+
+// prepareFormula picks the formula to render and analyzes it.
+//   Friezes are used first, then rosettes, then lattices.
+//   Rosettes and friezes are compiled so their terms are expanded once per render.
+//   Lattice patterns are copied before Setup so the command is not modified.
+func prepareFormula(wallpaperCommand *command.CreateSymmetryPattern) (formulaCalculator, *SymmetryAnalysis, error) {
+	if wallpaperCommand.Morph != nil {
+		return prepareMorph(wallpaperCommand)
+	}
+
+	if wallpaperCommand.FriezeFormula != nil {
+		return wallpaperCommand.FriezeFormula.Compile(), &SymmetryAnalysis{
+			Frieze: wallpaperCommand.FriezeFormula.AnalyzeForSymmetry(),
+		}, nil
+	}
+
+	if wallpaperCommand.RosetteFormula != nil {
+		return wallpaperCommand.RosetteFormula.Compile(), &SymmetryAnalysis{
+			Rosette: wallpaperCommand.RosetteFormula.AnalyzeForSymmetry(),
+		}, nil
+	}
+
+	if wallpaperCommand.LatticePattern != nil {
+		latticePattern := wallpaperCommand.LatticePattern.Copy()
+		if setupErr := latticePattern.Setup(); setupErr != nil {
+			return nil, nil, setupErr
+		}
+
+		return latticePattern, &SymmetryAnalysis{
+			Lattice:        findLatticeSymmetries(latticePattern),
+			LatticeVectors: latticePattern.Lattice,
+		}, nil
+	}
+
+	return nil, nil, errors.New("no formula found")
+}
+
+// findLatticeSymmetries lists the symmetries the Setup lattice pattern has, in the order they are reported.
+func findLatticeSymmetries(latticePattern *wallpaper.Formula) []wallpaper.Symmetry {
+	symmetriesFound := []wallpaper.Symmetry{}
+	for _, symmetry := range latticeSymmetriesToCheck {
+		if latticePattern.HasSymmetry(symmetry) {
+			symmetriesFound = append(symmetriesFound, symmetry)
+		}
+	}
+	return symmetriesFound
+}
@@ -0,0 +1,71 @@
+package render_test
+
+import (
+	. "gopkg.in/check.v1"
+	"wallpaper/entities/command"
+	"wallpaper/entities/formula/wallpaper"
+	"wallpaper/entities/render"
+)
+
+type AnalysisSuite struct{}
+
+var _ = Suite(&AnalysisSuite{})
+
+func (suite *AnalysisSuite) TestLatticeSymmetriesAreListed(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+lattice_pattern:
+  lattice_type: rhombic
+  lattice_size:
+    width: 0
+    height: 0.65
+  multiplier:
+    real: 1
+    imaginary: 0
+  wave_packets:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      terms:
+        -
+          power_n: 1
+          power_m: -2
+  desired_symmetry: cmm
+`))
+	checker.Assert(err, IsNil)
+
+	symmetryAnalysis, err := render.AnalyzeSymmetry(wallpaperCommand)
+	checker.Assert(err, IsNil)
+	checker.Assert(symmetryAnalysis.Lattice, DeepEquals, []wallpaper.Symmetry{wallpaper.P1, wallpaper.Cm, wallpaper.Cmm})
+	checker.Assert(symmetryAnalysis.Frieze, IsNil)
+	checker.Assert(symmetryAnalysis.Rosette, IsNil)
+}
+
+func (suite *AnalysisSuite) TestFriezeIsAnalyzedBeforeRosette(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+frieze_formula:
+  terms:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      power_n: 1
+      power_m: 0
+      coefficient_relationships:
+        - -N-M
+rosette_formula:
+  terms:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      power_n: 1
+      power_m: 0
+`))
+	checker.Assert(err, IsNil)
+
+	symmetryAnalysis, err := render.AnalyzeSymmetry(wallpaperCommand)
+	checker.Assert(err, IsNil)
+	checker.Assert(symmetryAnalysis.Frieze.P211, Equals, true)
+	checker.Assert(symmetryAnalysis.Rosette, IsNil)
+}
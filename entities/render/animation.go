@@ -0,0 +1,72 @@
+package render
+
+import (
+	"errors"
+	"image"
+	"wallpaper/entities/command"
+)
+
+// AnimationFrame is one rendered frame of an animation.
+type AnimationFrame struct {
+	Index int
+	// Command is the command with the animation's tracks applied, which renders this frame on its own.
+	Command *command.CreateSymmetryPattern
+	Image   image.Image
+	Report  *Report
+}
+
+// RenderAnimation renders every frame of the command's animation.
+//   Frames are spread across GOMAXPROCS workers, so several frames are rendered at once.
+//   handleFrame is called once per frame as soon as it is finished, possibly from several workers at once,
+//   and frames may finish out of order.
+//   Once a frame fails, no more frames are started and the first error is returned.
+//   colorSource is shared by every frame; a generated source is drawn once for the whole animation.
+func RenderAnimation(
+	wallpaperCommand *command.CreateSymmetryPattern,
+	colorSource image.Image,
+	handleFrame func(frame *AnimationFrame) error,
+) error {
+	if wallpaperCommand.Animation == nil {
+		return errors.New("the command has no animation")
+	}
+
+	frameCommands := []*command.CreateSymmetryPattern{}
+	for frameIndex := 0; frameIndex < wallpaperCommand.Animation.Frames; frameIndex++ {
+		frameCommand, err := wallpaperCommand.AnimationFrame(frameIndex)
+		if err != nil {
+			return err
+		}
+		frameCommands = append(frameCommands, frameCommand)
+	}
+
+	if colorSource == nil && wallpaperCommand.UsesSourceImage() && wallpaperCommand.SampleSourceGenerator != nil {
+		generatedSource, err := wallpaperCommand.SampleSourceGenerator.Generate()
+		if err != nil {
+			return err
+		}
+		colorSource = generatedSource
+	}
+
+	renderErr := &firstError{}
+
+	processIndicesInParallel(len(frameCommands), func(frameIndex int) {
+		if renderErr.get() != nil {
+			return
+		}
+		frameImage, report, err := Render(frameCommands[frameIndex], colorSource)
+		if err != nil {
+			renderErr.record(err)
+			return
+		}
+		err = handleFrame(&AnimationFrame{
+			Index:   frameIndex,
+			Command: frameCommands[frameIndex],
+			Image:   frameImage,
+			Report:  report,
+		})
+		if err != nil {
+			renderErr.record(err)
+		}
+	})
+	return renderErr.get()
+}
@@ -0,0 +1,79 @@
+package render_test
+
+import (
+	"errors"
+	. "gopkg.in/check.v1"
+	"image"
+	"sync"
+	"wallpaper/entities/command"
+	"wallpaper/entities/render"
+)
+
+type AnimationSuite struct {
+	colorSource image.Image
+}
+
+var _ = Suite(&AnimationSuite{})
+
+func (suite *AnimationSuite) SetUpTest(checker *C) {
+	renderSuite := &RenderSuite{}
+	renderSuite.SetUpTest(checker)
+	suite.colorSource = renderSuite.colorSource
+}
+
+func newAnimatedRosetteCommand(checker *C, frames int) *command.CreateSymmetryPattern {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.Animation = &command.Animation{
+		Frames: frames,
+		Tracks: []*command.AnimationTrack{
+			{
+				Field: "rosette_formula.terms[0].multiplier",
+				Keyframes: []command.Keyframe{
+					{Frame: 0, Value: map[string]interface{}{"real": 1.0, "imaginary": 0.0}},
+					{Frame: frames - 1, Value: map[string]interface{}{"real": -1.0, "imaginary": 0.0}},
+				},
+			},
+		},
+	}
+	return wallpaperCommand
+}
+
+func (suite *AnimationSuite) TestEveryFrameMatchesItsOwnRender(checker *C) {
+	wallpaperCommand := newAnimatedRosetteCommand(checker, 6)
+
+	var framesLock sync.Mutex
+	frames := map[int]*render.AnimationFrame{}
+	err := render.RenderAnimation(wallpaperCommand, suite.colorSource, func(frame *render.AnimationFrame) error {
+		framesLock.Lock()
+		defer framesLock.Unlock()
+		frames[frame.Index] = frame
+		return nil
+	})
+	checker.Assert(err, IsNil)
+	checker.Assert(frames, HasLen, 6)
+
+	for frameIndex, frame := range frames {
+		expectedCommand, err := wallpaperCommand.AnimationFrame(frameIndex)
+		checker.Assert(err, IsNil)
+		expectedImage, _, err := render.Render(expectedCommand, suite.colorSource)
+		checker.Assert(err, IsNil)
+		checker.Assert(frame.Image, DeepEquals, expectedImage)
+	}
+
+	checker.Assert(frames[0].Image.At(0, 0), Not(DeepEquals), frames[5].Image.At(0, 0))
+}
+
+func (suite *AnimationSuite) TestFrameErrorsStopTheAnimation(checker *C) {
+	wallpaperCommand := newAnimatedRosetteCommand(checker, 3)
+	err := render.RenderAnimation(wallpaperCommand, suite.colorSource, func(frame *render.AnimationFrame) error {
+		return errors.New("disk is full")
+	})
+	checker.Assert(err, ErrorMatches, "disk is full")
+}
+
+func (suite *AnimationSuite) TestRenderAnimationNeedsAnAnimation(checker *C) {
+	err := render.RenderAnimation(newRosetteCommand(checker), suite.colorSource, func(frame *render.AnimationFrame) error {
+		return nil
+	})
+	checker.Assert(err, ErrorMatches, "the command has no animation")
+}
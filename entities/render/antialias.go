@@ -0,0 +1,120 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"wallpaper/entities/command"
+)
+
+// subpixelOffset is how far a sample is from the pixel's own sample point, in pixels.
+type subpixelOffset struct {
+	x float64
+	y float64
+}
+
+// pixelNumbering places the output image inside a bigger image, whose pixels are numbered row by row.
+//   Each pixel's number seeds its jitter, so pieces of one big image, like tiles, jitter exactly like the big image does.
+type pixelNumbering struct {
+	// origin is where the output image's top left pixel is in the bigger image.
+	origin image.Point
+	// width is the width of the bigger image.
+	width int
+}
+
+// pixelSampler chooses the sample points inside each output pixel.
+type pixelSampler struct {
+	// fixedOffsets are used for every pixel. It is nil when each pixel picks its own offsets.
+	fixedOffsets  []subpixelOffset
+	jitterSamples int
+	numbering     pixelNumbering
+}
+
+// newPixelSampler uses the command's antialias options.
+//   Without antialiasing, every pixel has a single sample with no offset.
+func newPixelSampler(antialias *command.AntialiasOptions, numbering pixelNumbering) *pixelSampler {
+	if antialias == nil {
+		return &pixelSampler{fixedOffsets: []subpixelOffset{{x: 0, y: 0}}}
+	}
+
+	if antialias.Mode == command.AntialiasJitter {
+		return &pixelSampler{jitterSamples: antialias.Samples, numbering: numbering}
+	}
+
+	gridSize := antialias.GridSize()
+	offsets := []subpixelOffset{}
+	for row := 0; row < gridSize; row++ {
+		for column := 0; column < gridSize; column++ {
+			offsets = append(offsets, subpixelOffset{
+				x: (float64(column)+0.5)/float64(gridSize) - 0.5,
+				y: (float64(row)+0.5)/float64(gridSize) - 0.5,
+			})
+		}
+	}
+	return &pixelSampler{fixedOffsets: offsets}
+}
+
+// samplesPerPixel returns how many samples each pixel takes.
+func (sampler *pixelSampler) samplesPerPixel() int {
+	if sampler.fixedOffsets != nil {
+		return len(sampler.fixedOffsets)
+	}
+	return sampler.jitterSamples
+}
+
+// offsetsForPixel returns the sample offsets for the given pixel.
+//   Offsets are centered on the pixel's own sample point, so antialiased renders line up with regular ones.
+func (sampler *pixelSampler) offsetsForPixel(pixelX, pixelY int) []subpixelOffset {
+	if sampler.fixedOffsets != nil {
+		return sampler.fixedOffsets
+	}
+
+	numberingX := uint64(sampler.numbering.origin.X + pixelX)
+	numberingY := uint64(sampler.numbering.origin.Y + pixelY)
+	pixelIndex := numberingY*uint64(sampler.numbering.width) + numberingX
+	offsets := make([]subpixelOffset, sampler.jitterSamples)
+	for sampleIndex := range offsets {
+		randomSeed := pixelIndex*uint64(sampler.jitterSamples)*2 + uint64(sampleIndex)*2
+		offsets[sampleIndex] = subpixelOffset{
+			x: randomFraction(randomSeed) - 0.5,
+			y: randomFraction(randomSeed+1) - 0.5,
+		}
+	}
+	return offsets
+}
+
+// randomFraction turns the seed into a number from 0 up to (but not including) 1.
+//   The same seed always gives the same number, so jittered renders do not depend on which worker renders a pixel.
+//   It uses the SplitMix64 mixing function.
+func randomFraction(seed uint64) float64 {
+	mixed := seed + 0x9e3779b97f4a7c15
+	mixed = (mixed ^ (mixed >> 30)) * 0xbf58476d1ce4e5b9
+	mixed = (mixed ^ (mixed >> 27)) * 0x94d049bb133111eb
+	mixed ^= mixed >> 31
+	return float64(mixed>>11) / float64(1<<53)
+}
+
+// averageColors mixes the colors evenly.
+//   Colors are averaged with their alpha premultiplied, so transparent samples do not darken the result.
+func averageColors(colors []color.NRGBA64) color.NRGBA64 {
+	if len(colors) == 1 {
+		return colors[0]
+	}
+
+	var totalR, totalG, totalB, totalA uint64
+	for _, sampleColor := range colors {
+		r, g, b, a := sampleColor.RGBA()
+		totalR += uint64(r)
+		totalG += uint64(g)
+		totalB += uint64(b)
+		totalA += uint64(a)
+	}
+
+	numberOfColors := uint64(len(colors))
+	averageColor := color.RGBA64{
+		R: uint16((totalR + numberOfColors/2) / numberOfColors),
+		G: uint16((totalG + numberOfColors/2) / numberOfColors),
+		B: uint16((totalB + numberOfColors/2) / numberOfColors),
+		A: uint16((totalA + numberOfColors/2) / numberOfColors),
+	}
+	return color.NRGBA64Model.Convert(averageColor).(color.NRGBA64)
+}
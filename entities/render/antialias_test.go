@@ -0,0 +1,76 @@
+package render_test
+
+import (
+	. "gopkg.in/check.v1"
+	"image"
+	"image/color"
+	"runtime"
+	"wallpaper/entities/command"
+	"wallpaper/entities/render"
+)
+
+type AntialiasSuite struct {
+	colorSource image.Image
+}
+
+var _ = Suite(&AntialiasSuite{})
+
+func (suite *AntialiasSuite) SetUpTest(checker *C) {
+	colorSource := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	colorSource.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	colorSource.Set(1, 0, color.NRGBA{G: 255, A: 255})
+	colorSource.Set(0, 1, color.NRGBA{B: 255, A: 255})
+	colorSource.Set(1, 1, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	suite.colorSource = colorSource
+}
+
+func (suite *AntialiasSuite) TestSingleGridSampleMatchesRegularRender(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	regularImage, regularReport, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+
+	wallpaperCommand.Antialias = &command.AntialiasOptions{Mode: command.AntialiasGrid, Samples: 1}
+	antialiasedImage, antialiasedReport, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+
+	checker.Assert(antialiasedImage.(*image.NRGBA).Pix, DeepEquals, regularImage.(*image.NRGBA).Pix)
+	checker.Assert(antialiasedReport, DeepEquals, regularReport)
+}
+
+func (suite *AntialiasSuite) TestGridAveragesTransparentSamples(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.ColorValueSpace = command.ComplexNumberCorners{MinX: -1, MinY: -1, MaxX: 1, MaxY: 1}
+	wallpaperCommand.Antialias = &command.AntialiasOptions{Mode: command.AntialiasGrid, Samples: 4}
+
+	outputImage, _, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(outputImage.At(0, 0), Equals, color.Color(color.NRGBA{R: 255, A: 64}))
+}
+
+func (suite *AntialiasSuite) TestJitterIsRepeatable(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.OutputImageSize = command.WidthHeightDimensions{Width: 23, Height: 41}
+	wallpaperCommand.Antialias = &command.AntialiasOptions{Mode: command.AntialiasJitter, Samples: 5}
+
+	previousMaxProcs := runtime.GOMAXPROCS(1)
+	serialImage, serialReport, err := render.Render(wallpaperCommand, suite.colorSource)
+	runtime.GOMAXPROCS(8)
+	parallelImage, parallelReport, parallelErr := render.Render(wallpaperCommand, suite.colorSource)
+	runtime.GOMAXPROCS(previousMaxProcs)
+
+	checker.Assert(err, IsNil)
+	checker.Assert(parallelErr, IsNil)
+	checker.Assert(parallelImage.(*image.NRGBA).Pix, DeepEquals, serialImage.(*image.NRGBA).Pix)
+	checker.Assert(parallelReport, DeepEquals, serialReport)
+}
+
+func (suite *AntialiasSuite) TestSixteenBitOutputAveragesWithoutRoundingToEightBits(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.ColorValueSpace = command.ComplexNumberCorners{MinX: -1, MinY: -1, MaxX: 1, MaxY: 1}
+	wallpaperCommand.Antialias = &command.AntialiasOptions{Mode: command.AntialiasGrid, Samples: 4}
+	wallpaperCommand.OutputBitDepth = 16
+
+	outputImage, _, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(outputImage.At(0, 0), Equals, color.Color(color.NRGBA64{R: 0xffff, A: 0x4000}))
+}
@@ -0,0 +1,86 @@
+package render
+
+import (
+	"runtime"
+	"sync"
+)
+
+// rowBandHeight is the number of output rows each worker renders at a time.
+const rowBandHeight = 16
+
+// rowBand is a horizontal strip of output rows, from minY up to (but not including) maxY.
+type rowBand struct {
+	minY int
+	maxY int
+}
+
+// splitIntoRowBands cuts the rows from 0 to height into bands of at most bandHeight rows.
+func splitIntoRowBands(height, bandHeight int) []rowBand {
+	bands := []rowBand{}
+	for minY := 0; minY < height; minY += bandHeight {
+		maxY := minY + bandHeight
+		if maxY > height {
+			maxY = height
+		}
+		bands = append(bands, rowBand{minY: minY, maxY: maxY})
+	}
+	return bands
+}
+
+// processRowBandsInParallel calls process once for every band, using one worker per GOMAXPROCS.
+//   process receives the band's index so it can store results without locking.
+//   Bands must not share any output, or the result will depend on scheduling.
+func processRowBandsInParallel(bands []rowBand, process func(bandIndex int, band rowBand)) {
+	processIndicesInParallel(len(bands), func(bandIndex int) {
+		process(bandIndex, bands[bandIndex])
+	})
+}
+
+// processIndicesInParallel calls process once for every index from 0 up to (but not including) count,
+//   using one worker per GOMAXPROCS.
+func processIndicesInParallel(count int, process func(index int)) {
+	numberOfWorkers := runtime.GOMAXPROCS(0)
+	if numberOfWorkers > count {
+		numberOfWorkers = count
+	}
+
+	indices := make(chan int)
+	var workersFinished sync.WaitGroup
+	for worker := 0; worker < numberOfWorkers; worker++ {
+		workersFinished.Add(1)
+		go func() {
+			defer workersFinished.Done()
+			for index := range indices {
+				process(index)
+			}
+		}()
+	}
+
+	for index := 0; index < count; index++ {
+		indices <- index
+	}
+	close(indices)
+	workersFinished.Wait()
+}
+
+// firstError keeps the first error reported by any worker, so the others can stop early.
+type firstError struct {
+	lock sync.Mutex
+	err  error
+}
+
+// record keeps the error if no other error was recorded before it.
+func (first *firstError) record(err error) {
+	first.lock.Lock()
+	defer first.lock.Unlock()
+	if first.err == nil {
+		first.err = err
+	}
+}
+
+// get returns the first recorded error, or nil if there was none.
+func (first *firstError) get() error {
+	first.lock.Lock()
+	defer first.lock.Unlock()
+	return first.err
+}
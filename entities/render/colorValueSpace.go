@@ -0,0 +1,95 @@
+package render
+
+import (
+	"errors"
+	"math"
+	"wallpaper/entities/command"
+	"wallpaper/entities/mathutility"
+)
+
+// maximumColorValueSpaceSamplesPerSide limits how many pixels are transformed to choose an automatic color value space.
+//   Larger images are sampled on an evenly spaced grid instead of at every pixel.
+const maximumColorValueSpaceSamplesPerSide = 256
+
+// chooseColorValueSpace uses the transformed values of an evenly spaced grid of output pixels,
+//   and picks corners that contain the given percentiles of the real and imaginary parts.
+//   Infinite and undefined values are ignored.
+//   If the chosen range has no width (or height), it is widened by 1 on each side.
+func (renderer *renderer) chooseColorValueSpace(percentiles *command.PercentileRange) (command.ComplexNumberCorners, error) {
+	realParts := []float64{}
+	imaginaryParts := []float64{}
+	for _, transformedCoordinate := range renderer.colorValueSamples() {
+		if math.IsNaN(real(transformedCoordinate)) || math.IsInf(real(transformedCoordinate), 0) ||
+			math.IsNaN(imag(transformedCoordinate)) || math.IsInf(imag(transformedCoordinate), 0) {
+			continue
+		}
+		realParts = append(realParts, real(transformedCoordinate))
+		imaginaryParts = append(imaginaryParts, imag(transformedCoordinate))
+	}
+	if len(realParts) == 0 {
+		return command.ComplexNumberCorners{}, errors.New("every transformed value is infinite or undefined, so the color value space cannot be chosen automatically")
+	}
+
+	minX, maxX := percentileRange(realParts, percentiles)
+	minY, maxY := percentileRange(imaginaryParts, percentiles)
+	return command.ComplexNumberCorners{
+		MinX: minX,
+		MinY: minY,
+		MaxX: maxX,
+		MaxY: maxY,
+	}, nil
+}
+
+// colorValueSamples returns the transformed values of the grid used to choose an automatic color value space.
+//   They are read from the transform cache when the renderer has one.
+func (renderer *renderer) colorValueSamples() []complex128 {
+	if renderer.transformCache != nil {
+		return renderer.transformCache.ColorValueSamples
+	}
+	return renderer.calculateColorValueSamples()
+}
+
+// calculateColorValueSamples splits the output image into a grid of equal blocks,
+//   and transforms the pixel in the middle of each block.
+func (renderer *renderer) calculateColorValueSamples() []complex128 {
+	destinationBounds := renderer.destinationBounds
+	sampleColumns := minimumInt(destinationBounds.Dx(), maximumColorValueSpaceSamplesPerSide)
+	sampleRows := minimumInt(destinationBounds.Dy(), maximumColorValueSpaceSamplesPerSide)
+
+	bands := splitIntoRowBands(sampleRows, rowBandHeight)
+	samplesByBand := make([][]complex128, len(bands))
+	processRowBandsInParallel(bands, func(bandIndex int, band rowBand) {
+		for sampleRow := band.minY; sampleRow < band.maxY; sampleRow++ {
+			destinationY := destinationBounds.Min.Y + (2*sampleRow+1)*destinationBounds.Dy()/(2*sampleRows)
+			for sampleColumn := 0; sampleColumn < sampleColumns; sampleColumn++ {
+				destinationX := destinationBounds.Min.X + (2*sampleColumn+1)*destinationBounds.Dx()/(2*sampleColumns)
+				samplesByBand[bandIndex] = append(samplesByBand[bandIndex], renderer.calculator.Calculate(
+					renderer.sampleSpace.scale(float64(destinationX), float64(destinationY)),
+				).Total)
+			}
+		}
+	})
+
+	samples := []complex128{}
+	for _, bandSamples := range samplesByBand {
+		samples = append(samples, bandSamples...)
+	}
+	return samples
+}
+
+// percentileRange returns the values at the low and high percentiles.
+func percentileRange(values []float64, percentiles *command.PercentileRange) (float64, float64) {
+	low := mathutility.Percentile(values, percentiles.Low)
+	high := mathutility.Percentile(values, percentiles.High)
+	if low == high {
+		return low - 1, high + 1
+	}
+	return low, high
+}
+
+func minimumInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,139 @@
+package render
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math/cmplx"
+	"wallpaper/entities/colorsource"
+	"wallpaper/entities/command"
+	"wallpaper/entities/mathutility"
+)
+
+// transformedValueColorer turns transformed values into colors.
+//   It is used by several bands at once, so it must not change while rendering.
+type transformedValueColorer interface {
+	ColorFor(transformedCoordinate complex128) color.NRGBA64
+}
+
+// setUpColoring picks how the renderer colors transformed values, based on the command's coloring mode.
+//   It returns the color value space used with the source image.
+func (renderer *renderer) setUpColoring(wallpaperCommand *command.CreateSymmetryPattern, colorSource image.Image) (command.ComplexNumberCorners, error) {
+	if wallpaperCommand.Coloring == command.ColorByDomain {
+		renderer.colorer = &colorsource.DomainColoring{}
+		if wallpaperCommand.DomainColoring != nil {
+			renderer.colorer = wallpaperCommand.DomainColoring
+		}
+		return wallpaperCommand.ColorValueSpace, nil
+	}
+
+	if wallpaperCommand.Coloring == command.ColorByPalette {
+		if wallpaperCommand.Palette == nil {
+			return command.ComplexNumberCorners{}, errors.New("palette coloring needs a palette")
+		}
+		compiledPalette, err := wallpaperCommand.Palette.Compile()
+		if err != nil {
+			return command.ComplexNumberCorners{}, err
+		}
+		renderer.colorer = compiledPalette
+		return wallpaperCommand.ColorValueSpace, nil
+	}
+
+	outOfRange, err := newOutOfRangePolicy(wallpaperCommand.OutOfRange)
+	if err != nil {
+		return command.ComplexNumberCorners{}, err
+	}
+
+	colorValueSpace := wallpaperCommand.ColorValueSpace
+	if wallpaperCommand.AutomaticColorValueSpace != nil {
+		colorValueSpace, err = renderer.chooseColorValueSpace(wallpaperCommand.AutomaticColorValueSpace)
+		if err != nil {
+			return command.ComplexNumberCorners{}, err
+		}
+	}
+
+	renderer.colorer = &sourceImageColorer{
+		colorSource:        colorsource.NewSampler(colorSource, wallpaperCommand.SourceSampling, outOfRange.edge),
+		outOfRange:         outOfRange,
+		colorValueBoundMin: complex(colorValueSpace.MinX, colorValueSpace.MinY),
+		colorValueBoundMax: complex(colorValueSpace.MaxX, colorValueSpace.MaxY),
+	}
+	return colorValueSpace, nil
+}
+
+// sourceImageColorer finds transformed values in the color value space, and uses that spot of the source image.
+type sourceImageColorer struct {
+	colorSource        *colorsource.Sampler
+	outOfRange         *outOfRangePolicy
+	colorValueBoundMin complex128
+	colorValueBoundMax complex128
+}
+
+// ColorFor picks the source image color the transformed coordinate lines up with.
+//   Coordinates outside of the color value space are colored using the out of range policy.
+//   Coordinates that are infinite or undefined cannot line up with the source image,
+//   so they are transparent (or the background color).
+func (colorer *sourceImageColorer) ColorFor(transformedCoordinate complex128) color.NRGBA64 {
+	if cmplx.IsNaN(transformedCoordinate) || cmplx.IsInf(transformedCoordinate) {
+		return colorer.outOfRange.background
+	}
+
+	outsideColorValueSpace := real(transformedCoordinate) < real(colorer.colorValueBoundMin) ||
+		imag(transformedCoordinate) < imag(colorer.colorValueBoundMin) ||
+		real(transformedCoordinate) > real(colorer.colorValueBoundMax) ||
+		imag(transformedCoordinate) > imag(colorer.colorValueBoundMax)
+	if outsideColorValueSpace && colorer.outOfRange.edge == colorsource.EdgeNone {
+		return colorer.outOfRange.background
+	}
+
+	sourceImageBounds := colorer.colorSource.Bounds()
+	sourceImageX := mathutility.ExtendValueBetweenTwoRanges(
+		float64(real(transformedCoordinate)),
+		real(colorer.colorValueBoundMin),
+		real(colorer.colorValueBoundMax),
+		float64(sourceImageBounds.Min.X),
+		float64(sourceImageBounds.Max.X),
+	)
+	sourceImageY := mathutility.ExtendValueBetweenTwoRanges(
+		float64(imag(transformedCoordinate)),
+		imag(colorer.colorValueBoundMin),
+		imag(colorer.colorValueBoundMax),
+		float64(sourceImageBounds.Min.Y),
+		float64(sourceImageBounds.Max.Y),
+	)
+	return colorer.colorSource.At(sourceImageX, sourceImageY)
+}
+
+// outOfRangePolicy is how the renderer colors values outside of the color value space.
+type outOfRangePolicy struct {
+	// edge is EdgeNone when out of range values use the background color.
+	edge       colorsource.EdgeMode
+	background color.NRGBA64
+}
+
+// newOutOfRangePolicy reads the command's options. Without options, out of range values are transparent.
+func newOutOfRangePolicy(options *command.OutOfRangeOptions) (*outOfRangePolicy, error) {
+	policy := &outOfRangePolicy{
+		edge:       colorsource.EdgeNone,
+		background: color.NRGBA64{R: 0, G: 0, B: 0, A: 0},
+	}
+	if options == nil {
+		return policy, nil
+	}
+
+	switch options.Mode {
+	case command.OutOfRangeClamp:
+		policy.edge = colorsource.EdgeClamp
+	case command.OutOfRangeWrap:
+		policy.edge = colorsource.EdgeWrap
+	case command.OutOfRangeMirror:
+		policy.edge = colorsource.EdgeMirror
+	case command.OutOfRangeBackground:
+		backgroundColor, err := colorsource.ParseHexColor(options.Color)
+		if err != nil {
+			return nil, err
+		}
+		policy.background = colorsource.SixteenBitColor(backgroundColor)
+	}
+	return policy, nil
+}
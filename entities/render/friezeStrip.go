@@ -0,0 +1,55 @@
+package render
+
+import (
+	"errors"
+	"math"
+	"wallpaper/entities/command"
+	"wallpaper/entities/formula/frieze"
+)
+
+// FriezeStrip is the strip of a frieze pattern rendered by the frieze_period output mode.
+type FriezeStrip struct {
+	Periods int
+	// SampleSpace is the strip that was rendered. It is exactly Periods times frieze.Period wide,
+	//   so the column after the last one would match the first.
+	SampleSpace command.ComplexNumberCorners
+	// OutputSize keeps the command's output height. The width is chosen so pixels stay square.
+	OutputSize command.WidthHeightDimensions
+}
+
+// NewFriezeStrip finds the strip the command renders in the frieze_period output mode.
+//   The strip starts at the sample space's minx, and keeps its miny and maxy.
+func NewFriezeStrip(wallpaperCommand *command.CreateSymmetryPattern) (*FriezeStrip, error) {
+	if wallpaperCommand.FriezeFormula == nil {
+		return nil, errors.New("frieze_period output needs a frieze_formula")
+	}
+	sampleSpace := wallpaperCommand.SampleSpace
+	stripHeight := math.Abs(sampleSpace.MaxY - sampleSpace.MinY)
+	if stripHeight == 0 {
+		return nil, errors.New("frieze_period output needs a sample_space with a nonzero height")
+	}
+
+	periods := wallpaperCommand.FriezePeriods()
+	stripWidth := float64(periods) * frieze.Period
+	outputHeight := wallpaperCommand.OutputImageSize.Height
+	outputWidth := int(math.Round(float64(outputHeight) * stripWidth / stripHeight))
+	if outputWidth < 1 {
+		outputWidth = 1
+	}
+	return &FriezeStrip{
+		Periods: periods,
+		SampleSpace: command.ComplexNumberCorners{
+			MinX: sampleSpace.MinX,
+			MinY: sampleSpace.MinY,
+			MaxX: sampleSpace.MinX + stripWidth,
+			MaxY: sampleSpace.MaxY,
+		},
+		OutputSize: command.WidthHeightDimensions{Width: outputWidth, Height: outputHeight},
+	}, nil
+}
+
+// PixelsPerPeriod returns how many output pixels one period of the frieze covers.
+//   It is not a whole number unless the output width divides evenly, but the whole strip always loops.
+func (strip *FriezeStrip) PixelsPerPeriod() float64 {
+	return float64(strip.OutputSize.Width) / float64(strip.Periods)
+}
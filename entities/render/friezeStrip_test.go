@@ -0,0 +1,125 @@
+package render_test
+
+import (
+	. "gopkg.in/check.v1"
+	"image"
+	"math"
+	"wallpaper/entities/command"
+	"wallpaper/entities/formula/frieze"
+	"wallpaper/entities/render"
+	"wallpaper/entities/utility"
+)
+
+type FriezeStripSuite struct {
+	colorSource image.Image
+}
+
+var _ = Suite(&FriezeStripSuite{})
+
+func (suite *FriezeStripSuite) SetUpTest(checker *C) {
+	renderSuite := &RenderSuite{}
+	renderSuite.SetUpTest(checker)
+	suite.colorSource = renderSuite.colorSource
+}
+
+func newFriezeStripCommand(checker *C) *command.CreateSymmetryPattern {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_size:
+  width: 10
+  height: 20
+output_mode: frieze_period
+frieze_period:
+  periods: 2
+sample_space:
+  minx: -1
+  miny: -1
+  maxx: 1
+  maxy: 1
+color_value_space:
+  minx: -2
+  miny: -2
+  maxx: 2
+  maxy: 2
+frieze_formula:
+  terms:
+    -
+      multiplier:
+        real: 1.0
+        imaginary: 0
+      power_n: 1
+      power_m: 0
+    -
+      multiplier:
+        real: 0.5
+        imaginary: 0.25
+      power_n: -2
+      power_m: 1
+`))
+	checker.Assert(err, IsNil)
+	return wallpaperCommand
+}
+
+func (suite *FriezeStripSuite) TestStripCoversWholePeriodsWithSquarePixels(checker *C) {
+	stripImage, report, err := render.Render(newFriezeStripCommand(checker), suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(report.FriezeStrip, NotNil)
+	checker.Assert(report.FriezeStrip.Periods, Equals, 2)
+	checker.Assert(report.FriezeStrip.SampleSpace, Equals, command.ComplexNumberCorners{
+		MinX: -1,
+		MinY: -1,
+		MaxX: -1 + 2*frieze.Period,
+		MaxY: 1,
+	})
+
+	expectedWidth := int(math.Round(20 * 2 * frieze.Period / 2))
+	checker.Assert(stripImage.Bounds(), Equals, image.Rect(0, 0, expectedWidth, 20))
+	checker.Assert(report.FriezeStrip.PixelsPerPeriod(), utility.NumericallyCloseEnough{}, float64(expectedWidth)/2, 1e-6)
+}
+
+func (suite *FriezeStripSuite) TestStripMatchesTheNextStripOver(checker *C) {
+	// Domain coloring changes smoothly, so rounding errors cannot flip a pixel to a different part of the source image.
+	wallpaperCommand := newFriezeStripCommand(checker)
+	wallpaperCommand.Coloring = command.ColorByDomain
+	stripImage, report, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+
+	stripSampleSpace := report.FriezeStrip.SampleSpace
+	stripWidth := stripSampleSpace.MaxX - stripSampleSpace.MinX
+	wallpaperCommand.OutputMode = command.OutputSampleSpace
+	wallpaperCommand.OutputImageSize = report.FriezeStrip.OutputSize
+	wallpaperCommand.SampleSpace = command.ComplexNumberCorners{
+		MinX: stripSampleSpace.MaxX,
+		MinY: stripSampleSpace.MinY,
+		MaxX: stripSampleSpace.MaxX + stripWidth,
+		MaxY: stripSampleSpace.MaxY,
+	}
+	nextImage, _, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+
+	checker.Assert(nextImage.Bounds(), Equals, stripImage.Bounds())
+	for y := 0; y < stripImage.Bounds().Dy(); y++ {
+		for x := 0; x < stripImage.Bounds().Dx(); x++ {
+			stripRed, stripGreen, stripBlue, _ := stripImage.At(x, y).RGBA()
+			nextRed, nextGreen, nextBlue, _ := nextImage.At(x, y).RGBA()
+			for _, difference := range []int{int(stripRed) - int(nextRed), int(stripGreen) - int(nextGreen), int(stripBlue) - int(nextBlue)} {
+				checker.Assert(difference <= 0x101 && difference >= -0x101, Equals, true, Commentf("pixel %d, %d", x, y))
+			}
+		}
+	}
+}
+
+func (suite *FriezeStripSuite) TestOnePeriodIsTheDefault(checker *C) {
+	wallpaperCommand := newFriezeStripCommand(checker)
+	wallpaperCommand.FriezePeriod = nil
+	_, report, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(report.FriezeStrip.Periods, Equals, 1)
+	checker.Assert(report.FriezeStrip.SampleSpace.MaxX, utility.NumericallyCloseEnough{}, -1+frieze.Period, 1e-6)
+}
+
+func (suite *FriezeStripSuite) TestStripNeedsAFriezeFormula(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.OutputMode = command.OutputFriezePeriod
+	_, _, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, ErrorMatches, "frieze_period output needs a frieze_formula")
+}
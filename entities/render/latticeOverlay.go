@@ -0,0 +1,109 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"wallpaper/entities/colorsource"
+	"wallpaper/entities/command"
+	"wallpaper/entities/formula/symmetryelement"
+	"wallpaper/entities/formula/wallpaper"
+)
+
+// LatticeOverlay holds the lattice cell edges and one fundamental domain, moved into the output image.
+//   Points are pixel coordinates, like the SymmetryOverlay's.
+type LatticeOverlay struct {
+	CellEdges []symmetryelement.Line
+	// Symmetry is the group whose fundamental domain is shaded.
+	Symmetry wallpaper.Symmetry
+	// FundamentalDomain lists the corners of the shaded domain. It is in the cell holding the middle of the image.
+	FundamentalDomain []complex128
+	OutputSize        command.WidthHeightDimensions
+	Color             color.NRGBA
+	DomainColor       color.NRGBA
+	LineWidth         float64
+}
+
+// newLatticeOverlay finds the lattice cells the output image shows, and the fundamental domain to shade.
+func newLatticeOverlay(
+	wallpaperCommand *command.CreateSymmetryPattern,
+	symmetryAnalysis *SymmetryAnalysis,
+	sampleSpace sampleSpaceMapper,
+) (*LatticeOverlay, error) {
+	if symmetryAnalysis.LatticeVectors == nil {
+		return nil, errors.New("only a lattice_pattern has a lattice to draw")
+	}
+	options := wallpaperCommand.LatticeOverlay
+	overlayColor, err := colorsource.ParseHexColor(options.OverlayColor())
+	if err != nil {
+		return nil, err
+	}
+	domainColor, err := colorsource.ParseHexColor(options.OverlayDomainColor())
+	if err != nil {
+		return nil, err
+	}
+	symmetry, err := latticeOverlaySymmetry(wallpaperCommand, symmetryAnalysis)
+	if err != nil {
+		return nil, err
+	}
+
+	overlay := &LatticeOverlay{
+		Symmetry:    symmetry,
+		OutputSize:  wallpaperCommand.OutputImageSize,
+		Color:       overlayColor,
+		DomainColor: domainColor,
+		LineWidth:   options.OverlayLineWidth(),
+	}
+
+	regionMin, regionMax := sampleSpaceRegion(sampleSpace, overlay.OutputSize, overlay.LineWidth)
+	sampleSpaceEdges, err := symmetryelement.LatticeGrid(symmetryAnalysis.LatticeVectors, regionMin, regionMax)
+	if err != nil {
+		return nil, err
+	}
+
+	mapper := newPixelMapper(sampleSpace)
+	for _, edge := range sampleSpaceEdges {
+		overlay.CellEdges = append(overlay.CellEdges, symmetryelement.Line{
+			Start: mapper.pixel(edge.Start),
+			End:   mapper.pixel(edge.End),
+		})
+	}
+
+	imageMiddle := sampleSpace.scale(float64(overlay.OutputSize.Width)/2, float64(overlay.OutputSize.Height)/2)
+	for _, corner := range symmetryelement.FundamentalDomain(symmetry, symmetryAnalysis.LatticeVectors, imageMiddle) {
+		overlay.FundamentalDomain = append(overlay.FundamentalDomain, mapper.pixel(corner))
+	}
+	return overlay, nil
+}
+
+// latticeOverlaySymmetry picks the symmetry group whose fundamental domain is shaded:
+//   the overlay's symmetry, then the lattice pattern's desired symmetry, then the most symmetric group found.
+//   Lattice patterns desire p1 when they do not ask for a symmetry, so p1 counts as no desired symmetry.
+//   Returns an error if the pattern does not have the picked symmetry.
+func latticeOverlaySymmetry(wallpaperCommand *command.CreateSymmetryPattern, symmetryAnalysis *SymmetryAnalysis) (wallpaper.Symmetry, error) {
+	symmetry := wallpaperCommand.LatticeOverlay.Symmetry
+	if symmetry == "" && wallpaperCommand.LatticePattern != nil && wallpaperCommand.LatticePattern.DesiredSymmetry != wallpaper.P1 {
+		symmetry = wallpaperCommand.LatticePattern.DesiredSymmetry
+	}
+	if symmetry == "" {
+		return symmetryelement.MostSymmetric(symmetryAnalysis.Lattice), nil
+	}
+
+	for _, foundSymmetry := range symmetryAnalysis.Lattice {
+		if foundSymmetry == symmetry {
+			return symmetry, nil
+		}
+	}
+	return "", fmt.Errorf("the pattern does not have %s symmetry", symmetry)
+}
+
+// drawOnto shades the fundamental domain, then paints the cell edges over it.
+func (overlay *LatticeOverlay) drawOnto(canvas *outputCanvas, destinationBounds image.Rectangle) {
+	paintConvexPolygon(canvas, destinationBounds, colorsource.SixteenBitColor(overlay.DomainColor), overlay.FundamentalDomain)
+
+	edgeColor := colorsource.SixteenBitColor(overlay.Color)
+	for _, edge := range overlay.CellEdges {
+		paintLine(canvas, destinationBounds, edgeColor, edge.Start, edge.End, overlay.LineWidth, 0, 0)
+	}
+}
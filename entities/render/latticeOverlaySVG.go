@@ -0,0 +1,32 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteLatticeOverlaySVG writes the overlay as an SVG image the same size as the output image,
+//   so it lines up when it is laid over the image.
+//   The fundamental domain and the cell edges are separate elements, so each can be hidden or restyled.
+func WriteLatticeOverlaySVG(writer io.Writer, overlay *LatticeOverlay) error {
+	svg := &strings.Builder{}
+	writeSVGStart(svg, overlay.OutputSize)
+	svg.WriteString("  <g transform=\"translate(0.5 0.5)\">\n")
+
+	domainColor, domainOpacity := svgColor(overlay.DomainColor)
+	fmt.Fprintf(svg, "    <polygon id=\"fundamental_domain\" points=\"%s\" fill=\"%s\" fill-opacity=\"%s\" stroke=\"none\"/>\n",
+		svgPoints(overlay.FundamentalDomain), domainColor, domainOpacity)
+
+	edgeColor, edgeOpacity := svgColor(overlay.Color)
+	fmt.Fprintf(svg, "    <g id=\"cells\" fill=\"none\" stroke=\"%s\" stroke-opacity=\"%s\" stroke-width=\"%s\">\n",
+		edgeColor, edgeOpacity, svgNumber(overlay.LineWidth))
+	for _, edge := range overlay.CellEdges {
+		writeSVGLine(svg, edge.Start, edge.End)
+	}
+	svg.WriteString("    </g>\n")
+
+	svg.WriteString("  </g>\n</svg>\n")
+	_, err := io.WriteString(writer, svg.String())
+	return err
+}
@@ -0,0 +1,141 @@
+package render_test
+
+import (
+	"bytes"
+	. "gopkg.in/check.v1"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"wallpaper/entities/command"
+	"wallpaper/entities/formula/wallpaper"
+	"wallpaper/entities/render"
+	"wallpaper/entities/utility"
+)
+
+type LatticeOverlaySuite struct{}
+
+var _ = Suite(&LatticeOverlaySuite{})
+
+// newLatticeOverlayCommand shows 2 by 2 square cells, each 20 pixels wide, with the lattice's origin in the middle.
+func newLatticeOverlayCommand(checker *C) *command.CreateSymmetryPattern {
+	wallpaperCommand := newSquareLatticeCommand(checker)
+	wallpaperCommand.SampleSpace = command.ComplexNumberCorners{MinX: -1, MinY: -1, MaxX: 1, MaxY: 1}
+	wallpaperCommand.SymmetryOverlay = nil
+	wallpaperCommand.LatticeOverlay = &command.LatticeOverlay{DomainColor: "#ff0000"}
+	return wallpaperCommand
+}
+
+func pixelPolygonArea(corners []complex128) float64 {
+	twiceTheArea := 0.0
+	for index, corner := range corners {
+		nextCorner := corners[(index+1)%len(corners)]
+		twiceTheArea += real(corner)*imag(nextCorner) - real(nextCorner)*imag(corner)
+	}
+	return math.Abs(twiceTheArea) / 2
+}
+
+func (suite *LatticeOverlaySuite) TestCellEdgesFollowTheLattice(checker *C) {
+	_, report, err := render.Render(newLatticeOverlayCommand(checker), nil)
+	checker.Assert(err, IsNil)
+	overlay := report.LatticeOverlay
+	checker.Assert(overlay, NotNil)
+	checker.Assert(overlay.CellEdges, Not(HasLen), 0)
+	for _, edge := range overlay.CellEdges {
+		checker.Assert(cmplx.Abs(edge.End-edge.Start), utility.NumericallyCloseEnough{}, 20.0, 1e-9)
+		checker.Assert(math.Remainder(real(edge.Start), 20), utility.NumericallyCloseEnough{}, 0.0, 1e-9)
+		checker.Assert(math.Remainder(imag(edge.Start), 20), utility.NumericallyCloseEnough{}, 0.0, 1e-9)
+	}
+}
+
+func (suite *LatticeOverlaySuite) TestDesiredSymmetryDomainIsShadedInTheMiddleCell(checker *C) {
+	_, report, err := render.Render(newLatticeOverlayCommand(checker), nil)
+	checker.Assert(err, IsNil)
+	overlay := report.LatticeOverlay
+	checker.Assert(overlay.Symmetry, Equals, wallpaper.P4m)
+	checker.Assert(overlay.FundamentalDomain, HasLen, 3)
+	checker.Assert(pixelPolygonArea(overlay.FundamentalDomain), utility.NumericallyCloseEnough{}, 20.0*20.0/8, 1e-9)
+	checker.Assert(cmplx.Abs(overlay.FundamentalDomain[0]-complex(20, 20)) < 1e-9, Equals, true)
+}
+
+func (suite *LatticeOverlaySuite) TestSymmetryCanBeChosen(checker *C) {
+	wallpaperCommand := newLatticeOverlayCommand(checker)
+	wallpaperCommand.LatticeOverlay.Symmetry = wallpaper.P4
+	_, report, err := render.Render(wallpaperCommand, nil)
+	checker.Assert(err, IsNil)
+	checker.Assert(report.LatticeOverlay.Symmetry, Equals, wallpaper.P4)
+	checker.Assert(pixelPolygonArea(report.LatticeOverlay.FundamentalDomain), utility.NumericallyCloseEnough{}, 20.0*20.0/4, 1e-9)
+
+	wallpaperCommand.LatticeOverlay.Symmetry = wallpaper.P6
+	_, _, err = render.Render(wallpaperCommand, nil)
+	checker.Assert(err, ErrorMatches, "lattice_overlay: the pattern does not have p6 symmetry")
+}
+
+func (suite *LatticeOverlaySuite) TestMostSymmetricGroupIsUsedWithoutADesiredSymmetry(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_size:
+  width: 40
+  height: 40
+sample_space:
+  minx: -1
+  miny: -1
+  maxx: 1
+  maxy: 1
+coloring: domain
+lattice_pattern:
+  lattice_type: hexagonal
+  multiplier:
+    real: 1
+    imaginary: 0
+  wave_packets:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      terms:
+        -
+          power_n: 1
+          power_m: -2
+        -
+          power_n: -2
+          power_m: 1
+        -
+          power_n: 1
+          power_m: 1
+lattice_overlay:
+  layer: svg
+`))
+	checker.Assert(err, IsNil)
+	checker.Assert(wallpaperCommand.LatticePattern.DesiredSymmetry, Equals, wallpaper.P1)
+
+	_, report, err := render.Render(wallpaperCommand, nil)
+	checker.Assert(err, IsNil)
+	checker.Assert(report.Symmetry.Lattice, DeepEquals, []wallpaper.Symmetry{wallpaper.P1, wallpaper.P3})
+	checker.Assert(report.LatticeOverlay.Symmetry, Equals, wallpaper.P3)
+}
+
+func (suite *LatticeOverlaySuite) TestImageLayerShadesTheDomainUnderTheEdges(checker *C) {
+	overlaidImage, report, err := render.Render(newLatticeOverlayCommand(checker), nil)
+	checker.Assert(err, IsNil)
+
+	red := color.Color(color.NRGBA{R: 255, A: 255})
+	white := color.Color(color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	domain := report.LatticeOverlay.FundamentalDomain
+	insideTheDomain := (domain[0] + domain[1] + domain[2]) / 3
+	checker.Assert(overlaidImage.At(int(math.Round(real(insideTheDomain))), int(math.Round(imag(insideTheDomain)))), Equals, red)
+	checker.Assert(overlaidImage.At(20, 5), Equals, white)
+	checker.Assert(overlaidImage.At(5, 20), Equals, white)
+	checker.Assert(overlaidImage.At(5, 5), Not(Equals), white)
+}
+
+func (suite *LatticeOverlaySuite) TestSVGShadesTheDomainUnderTheCells(checker *C) {
+	wallpaperCommand := newLatticeOverlayCommand(checker)
+	wallpaperCommand.LatticeOverlay.Layer = command.OverlaySVG
+	_, report, err := render.Render(wallpaperCommand, nil)
+	checker.Assert(err, IsNil)
+
+	svg := &bytes.Buffer{}
+	checker.Assert(render.WriteLatticeOverlaySVG(svg, report.LatticeOverlay), IsNil)
+	checker.Assert(svg.String(), Matches, `(?s)<svg xmlns="http://www.w3.org/2000/svg" width="40" height="40" viewBox="0 0 40 40">.*`)
+	checker.Assert(svg.String(), Matches, `(?s).*<polygon id="fundamental_domain" points="20,20 30,20 30,\d+" fill="#ff0000" fill-opacity="1" stroke="none"/>.*`)
+	checker.Assert(svg.String(), Matches, `(?s).*<g id="cells" fill="none" stroke="#ffffff" stroke-opacity="1" stroke-width="1">\s*<line .*`)
+}
@@ -0,0 +1,47 @@
+package render
+
+import (
+	"errors"
+	"math"
+	"wallpaper/entities/command"
+	"wallpaper/entities/formula/latticevector"
+)
+
+// LatticeTile is the repeat of a lattice pattern rendered by the tile output mode.
+type LatticeTile struct {
+	// Repeat describes how the rendered rectangle repeats, including drop and brick offsets.
+	Repeat *latticevector.RectangularRepeat
+	// SampleSpace is the rectangle that was rendered. It starts at the lattice's origin and is one straight repeat wide and tall.
+	SampleSpace command.ComplexNumberCorners
+	// OutputSize keeps the command's output width. The height is chosen so pixels stay square.
+	OutputSize command.WidthHeightDimensions
+}
+
+// NewLatticeTile finds the repeat the command renders in the tile output mode.
+func NewLatticeTile(wallpaperCommand *command.CreateSymmetryPattern) (*LatticeTile, error) {
+	if wallpaperCommand.LatticePattern == nil {
+		return nil, errors.New("tile output needs a lattice_pattern")
+	}
+	repeat, err := wallpaperCommand.LatticePattern.RectangularRepeat()
+	if err != nil {
+		return nil, err
+	}
+
+	outputWidth := wallpaperCommand.OutputImageSize.Width
+	outputHeight := int(math.Round(float64(outputWidth) * repeat.Height / repeat.Width))
+	if outputHeight < 1 {
+		outputHeight = 1
+	}
+	return &LatticeTile{
+		Repeat:      repeat,
+		SampleSpace: command.ComplexNumberCorners{MinX: 0, MinY: 0, MaxX: repeat.Width, MaxY: repeat.Height},
+		OutputSize:  command.WidthHeightDimensions{Width: outputWidth, Height: outputHeight},
+	}, nil
+}
+
+// PixelsPerUnit returns how many output pixels cover one unit of the sample space, across and down.
+//   They differ slightly when the output height was rounded. Multiply the repeat's offsets by them to find them in the output image.
+func (tile *LatticeTile) PixelsPerUnit() (across, down float64) {
+	return float64(tile.OutputSize.Width) / tile.Repeat.Width, float64(tile.OutputSize.Height) / tile.Repeat.Height
+}
+
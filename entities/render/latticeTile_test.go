@@ -0,0 +1,122 @@
+package render_test
+
+import (
+	. "gopkg.in/check.v1"
+	"image"
+	"math"
+	"wallpaper/entities/command"
+	"wallpaper/entities/render"
+	"wallpaper/entities/utility"
+)
+
+type LatticeTileSuite struct {
+	colorSource image.Image
+}
+
+var _ = Suite(&LatticeTileSuite{})
+
+func (suite *LatticeTileSuite) SetUpTest(checker *C) {
+	renderSuite := &RenderSuite{}
+	renderSuite.SetUpTest(checker)
+	suite.colorSource = renderSuite.colorSource
+}
+
+func newHexagonalTileCommand(checker *C) *command.CreateSymmetryPattern {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_size:
+  width: 40
+  height: 10
+output_mode: tile
+sample_space:
+  minx: -1
+  miny: -1
+  maxx: 1
+  maxy: 1
+color_value_space:
+  minx: -2
+  miny: -2
+  maxx: 2
+  maxy: 2
+lattice_pattern:
+  lattice_type: hexagonal
+  multiplier:
+    real: 1
+    imaginary: 0
+  wave_packets:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      terms:
+        -
+          power_n: 1
+          power_m: -2
+  desired_symmetry: p31m
+`))
+	checker.Assert(err, IsNil)
+	return wallpaperCommand
+}
+
+func (suite *LatticeTileSuite) TestTileRendersOneRepeatWithSquarePixels(checker *C) {
+	tileImage, report, err := render.Render(newHexagonalTileCommand(checker), suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(report.LatticeTile, NotNil)
+	checker.Assert(report.LatticeTile.Repeat.Width, utility.NumericallyCloseEnough{}, 1.0, 1e-6)
+	checker.Assert(report.LatticeTile.Repeat.Height, utility.NumericallyCloseEnough{}, math.Sqrt(3), 1e-6)
+	checker.Assert(report.LatticeTile.Repeat.IsHalfDrop(), Equals, true)
+	checker.Assert(tileImage.Bounds(), Equals, image.Rect(0, 0, 40, 69))
+
+	across, down := report.LatticeTile.PixelsPerUnit()
+	checker.Assert(across, utility.NumericallyCloseEnough{}, 40.0, 1e-6)
+	checker.Assert(down, utility.NumericallyCloseEnough{}, 69/math.Sqrt(3), 1e-6)
+}
+
+func (suite *LatticeTileSuite) TestTileMatchesTheNextRepeatOver(checker *C) {
+	// Domain coloring changes smoothly, so rounding errors cannot flip a pixel to a different part of the source image.
+	wallpaperCommand := newHexagonalTileCommand(checker)
+	wallpaperCommand.Coloring = command.ColorByDomain
+	tileImage, report, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+
+	repeat := report.LatticeTile.Repeat
+	wallpaperCommand.OutputMode = command.OutputSampleSpace
+	wallpaperCommand.OutputImageSize = report.LatticeTile.OutputSize
+	wallpaperCommand.SampleSpace = command.ComplexNumberCorners{
+		MinX: repeat.Width,
+		MinY: -repeat.Height,
+		MaxX: 2 * repeat.Width,
+		MaxY: 0,
+	}
+	nextImage, _, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(nextImage.Bounds(), Equals, tileImage.Bounds())
+	for y := 0; y < tileImage.Bounds().Dy(); y++ {
+		for x := 0; x < tileImage.Bounds().Dx(); x++ {
+			tileRed, tileGreen, tileBlue, _ := tileImage.At(x, y).RGBA()
+			nextRed, nextGreen, nextBlue, _ := nextImage.At(x, y).RGBA()
+			for _, difference := range []int{int(tileRed) - int(nextRed), int(tileGreen) - int(nextGreen), int(tileBlue) - int(nextBlue)} {
+				checker.Assert(difference <= 0x101 && difference >= -0x101, Equals, true, Commentf("pixel %d, %d", x, y))
+			}
+		}
+	}
+}
+
+func (suite *LatticeTileSuite) TestSampleSpaceOutputHasNoTile(checker *C) {
+	wallpaperCommand := newHexagonalTileCommand(checker)
+	wallpaperCommand.OutputMode = ""
+	_, report, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(report.LatticeTile, IsNil)
+}
+
+func (suite *LatticeTileSuite) TestTileNeedsALatticePattern(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.OutputMode = command.OutputLatticeTile
+	_, _, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, ErrorMatches, "tile output needs a lattice_pattern")
+}
+
+func (suite *LatticeTileSuite) TestTileOutputCannotBeCutIntoTiles(checker *C) {
+	_, err := render.NewTilePyramid(newHexagonalTileCommand(checker), 0, 0)
+	checker.Assert(err, ErrorMatches, "tiles can only be cut from the sample space.*")
+}
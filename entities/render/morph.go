@@ -0,0 +1,129 @@
+package render
+
+import (
+	"errors"
+	"wallpaper/entities/command"
+	"wallpaper/entities/formula/frieze"
+	"wallpaper/entities/formula/result"
+	"wallpaper/entities/formula/rosette"
+	"wallpaper/entities/formula/wallpaper"
+)
+
+// prepareMorph blends the command's formula into the morph's formula.
+//   A fixed amount renders a single blended formula.
+//   A gradient calculates both formulas at every point and blends the results,
+//   which matches blending the multipliers because every formula adds up its terms.
+//   Gradients report the symmetries of the halfway blend.
+func prepareMorph(wallpaperCommand *command.CreateSymmetryPattern) (formulaCalculator, *SymmetryAnalysis, error) {
+	morph := wallpaperCommand.Morph
+	amount := morph.Amount
+	if morph.Gradient != nil {
+		amount = 0.5
+	}
+
+	if wallpaperCommand.FriezeFormula != nil && morph.FriezeFormula != nil {
+		blendedFormula := frieze.Morph(wallpaperCommand.FriezeFormula, morph.FriezeFormula, amount)
+		symmetryAnalysis := &SymmetryAnalysis{Frieze: blendedFormula.AnalyzeForSymmetry()}
+		if morph.Gradient == nil {
+			return blendedFormula.Compile(), symmetryAnalysis, nil
+		}
+		alignedFrom, alignedTo := frieze.AlignForMorph(wallpaperCommand.FriezeFormula, morph.FriezeFormula)
+		return newMorphCalculator(alignedFrom.Compile(), alignedTo.Compile(), morph.Gradient), symmetryAnalysis, nil
+	}
+
+	if wallpaperCommand.RosetteFormula != nil && morph.RosetteFormula != nil {
+		blendedFormula := rosette.Morph(wallpaperCommand.RosetteFormula, morph.RosetteFormula, amount)
+		symmetryAnalysis := &SymmetryAnalysis{Rosette: blendedFormula.AnalyzeForSymmetry()}
+		if morph.Gradient == nil {
+			return blendedFormula.Compile(), symmetryAnalysis, nil
+		}
+		alignedFrom, alignedTo := rosette.AlignForMorph(wallpaperCommand.RosetteFormula, morph.RosetteFormula)
+		return newMorphCalculator(alignedFrom.Compile(), alignedTo.Compile(), morph.Gradient), symmetryAnalysis, nil
+	}
+
+	if wallpaperCommand.LatticePattern != nil && morph.LatticePattern != nil {
+		fromPattern := wallpaperCommand.LatticePattern.Copy()
+		if setupErr := fromPattern.Setup(); setupErr != nil {
+			return nil, nil, setupErr
+		}
+		toPattern := morph.LatticePattern.Copy()
+		if setupErr := toPattern.Setup(); setupErr != nil {
+			return nil, nil, setupErr
+		}
+
+		blendedPattern, err := wallpaper.Morph(fromPattern, toPattern, amount)
+		if err != nil {
+			return nil, nil, err
+		}
+		symmetryAnalysis := &SymmetryAnalysis{
+			Lattice:        findLatticeSymmetries(blendedPattern),
+			LatticeVectors: blendedPattern.Lattice,
+		}
+		if morph.Gradient == nil {
+			return blendedPattern, symmetryAnalysis, nil
+		}
+		alignedFrom, alignedTo, err := wallpaper.AlignForMorph(fromPattern, toPattern)
+		if err != nil {
+			return nil, nil, err
+		}
+		return newMorphCalculator(alignedFrom, alignedTo, morph.Gradient), symmetryAnalysis, nil
+	}
+
+	return nil, nil, errors.New("morph needs the same kind of formula as the command")
+}
+
+// morphCalculator blends the results of two aligned formulas, using the gradient's amount at each point.
+type morphCalculator struct {
+	from     formulaCalculator
+	to       formulaCalculator
+	gradient *command.MorphGradient
+}
+
+func newMorphCalculator(from, to formulaCalculator, gradient *command.MorphGradient) *morphCalculator {
+	return &morphCalculator{from: from, to: to, gradient: gradient}
+}
+
+// Calculate blends both formulas at z.
+func (calculator *morphCalculator) Calculate(z complex128) *result.CalculationResultForFormula {
+	return blendResults(calculator.from.Calculate(z), calculator.to.Calculate(z), calculator.gradient.AmountAt(z))
+}
+
+// CalculateRow blends both formulas at every point in the row, using their own CalculateRow if they have one.
+func (calculator *morphCalculator) CalculateRow(start, step complex128, count int) []*result.CalculationResultForFormula {
+	fromRow := calculateRowWith(calculator.from, start, step, count)
+	toRow := calculateRowWith(calculator.to, start, step, count)
+
+	blendedRow := make([]*result.CalculationResultForFormula, count)
+	for pointIndex := range blendedRow {
+		point := start + complex(float64(pointIndex), 0)*step
+		blendedRow[pointIndex] = blendResults(fromRow[pointIndex], toRow[pointIndex], calculator.gradient.AmountAt(point))
+	}
+	return blendedRow
+}
+
+// calculateRowWith calculates count evenly spaced points, a whole row at a time if the calculator can.
+func calculateRowWith(calculator formulaCalculator, start, step complex128, count int) []*result.CalculationResultForFormula {
+	if rowCalculator, ok := calculator.(rowCalculator); ok {
+		return rowCalculator.CalculateRow(start, step, count)
+	}
+
+	rowResults := make([]*result.CalculationResultForFormula, count)
+	for pointIndex := range rowResults {
+		rowResults[pointIndex] = calculator.Calculate(start + complex(float64(pointIndex), 0)*step)
+	}
+	return rowResults
+}
+
+// blendResults interpolates two results of aligned formulas, term by term.
+//   An amount of 0 returns the from result and 1 returns the to result.
+func blendResults(from, to *result.CalculationResultForFormula, amount float64) *result.CalculationResultForFormula {
+	ratio := complex(amount, 0)
+	blended := &result.CalculationResultForFormula{
+		Total:              from.Total + (to.Total-from.Total)*ratio,
+		ContributionByTerm: make([]complex128, len(from.ContributionByTerm)),
+	}
+	for termIndex, fromContribution := range from.ContributionByTerm {
+		blended.ContributionByTerm[termIndex] = fromContribution + (to.ContributionByTerm[termIndex]-fromContribution)*ratio
+	}
+	return blended
+}
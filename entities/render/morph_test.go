@@ -0,0 +1,75 @@
+package render_test
+
+import (
+	. "gopkg.in/check.v1"
+	"image"
+	"wallpaper/entities/command"
+	"wallpaper/entities/formula/exponential"
+	"wallpaper/entities/formula/rosette"
+	"wallpaper/entities/render"
+	"wallpaper/entities/utility"
+)
+
+type MorphSuite struct {
+	colorSource   image.Image
+	targetFormula *rosette.Formula
+}
+
+var _ = Suite(&MorphSuite{})
+
+func (suite *MorphSuite) SetUpTest(checker *C) {
+	renderSuite := &RenderSuite{}
+	renderSuite.SetUpTest(checker)
+	suite.colorSource = renderSuite.colorSource
+
+	suite.targetFormula = &rosette.Formula{Terms: []*exponential.RosetteFriezeTerm{
+		{Multiplier: complex(-1, 0), PowerN: 1, PowerM: 0, IgnoreComplexConjugate: true},
+		{Multiplier: complex(0, 0.5), PowerN: 2, PowerM: 0, IgnoreComplexConjugate: true},
+	}}
+}
+
+func (suite *MorphSuite) renderWithFormula(checker *C, formula *rosette.Formula) image.Image {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.RosetteFormula = formula
+	outputImage, _, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+	return outputImage
+}
+
+func (suite *MorphSuite) TestMorphAmountPicksTheBlend(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	originalImage := suite.renderWithFormula(checker, wallpaperCommand.RosetteFormula)
+	targetImage := suite.renderWithFormula(checker, suite.targetFormula)
+
+	wallpaperCommand.Morph = &command.Morph{RosetteFormula: suite.targetFormula, Amount: 0}
+	outputImage, _, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(outputImage, DeepEquals, originalImage)
+
+	wallpaperCommand.Morph.Amount = 1
+	outputImage, _, err = render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(outputImage, DeepEquals, targetImage)
+}
+
+func (suite *MorphSuite) TestGradientMorphsAcrossThePattern(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	originalImage := suite.renderWithFormula(checker, wallpaperCommand.RosetteFormula)
+	targetImage := suite.renderWithFormula(checker, suite.targetFormula)
+
+	wallpaperCommand.Morph = &command.Morph{
+		RosetteFormula: suite.targetFormula,
+		Gradient: &command.MorphGradient{
+			Start: utility.ComplexNumberForMarshal{Real: -0.5},
+			End:   utility.ComplexNumberForMarshal{Real: 0.5},
+		},
+	}
+	outputImage, report, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(report.ContributionBoundsByTerm, HasLen, 2)
+
+	for y := 0; y < 3; y++ {
+		checker.Assert(outputImage.At(0, y), Equals, originalImage.At(0, y))
+		checker.Assert(outputImage.At(3, y), Equals, targetImage.At(3, y))
+	}
+}
@@ -0,0 +1,67 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"wallpaper/entities/colorsource"
+)
+
+// outputCanvas is the output image, stored with 8 or 16 bits per channel.
+//   Colors are always given with 16 bits per channel, and 8 bit canvases keep the top 8 bits.
+type outputCanvas struct {
+	eightBitImage   *image.NRGBA
+	sixteenBitImage *image.NRGBA64
+}
+
+// newOutputCanvas makes a 16 bit canvas when bitDepth is 16. Otherwise it makes an 8 bit canvas.
+func newOutputCanvas(bitDepth int, bounds image.Rectangle) *outputCanvas {
+	if bitDepth == 16 {
+		return &outputCanvas{sixteenBitImage: image.NewNRGBA64(bounds)}
+	}
+	return &outputCanvas{eightBitImage: image.NewNRGBA(bounds)}
+}
+
+// set colors one pixel. Different goroutines can set different pixels at the same time.
+func (canvas *outputCanvas) set(x, y int, pixelColor color.NRGBA64) {
+	if canvas.sixteenBitImage != nil {
+		canvas.sixteenBitImage.SetNRGBA64(x, y, pixelColor)
+		return
+	}
+	canvas.eightBitImage.SetNRGBA(x, y, colorsource.EightBitColor(pixelColor))
+}
+
+// image returns the finished output image.
+func (canvas *outputCanvas) image() image.Image {
+	if canvas.sixteenBitImage != nil {
+		return canvas.sixteenBitImage
+	}
+	return canvas.eightBitImage
+}
+
+// blend paints the color over one pixel. Coverage is how much of the pixel the paint covers, from 0 to 1.
+//   The paint's own alpha makes it more transparent still.
+func (canvas *outputCanvas) blend(x, y int, paintColor color.NRGBA64, coverage float64) {
+	var existingColor color.NRGBA64
+	if canvas.sixteenBitImage != nil {
+		existingColor = canvas.sixteenBitImage.NRGBA64At(x, y)
+	} else {
+		existingColor = colorsource.SixteenBitColor(canvas.eightBitImage.NRGBAAt(x, y))
+	}
+
+	paintAlpha := coverage * float64(paintColor.A) / 0xffff
+	existingAlpha := float64(existingColor.A) / 0xffff * (1 - paintAlpha)
+	blendedAlpha := paintAlpha + existingAlpha
+	if blendedAlpha == 0 {
+		return
+	}
+	blendChannel := func(paintChannel, existingChannel uint16) uint16 {
+		return uint16(math.Round((float64(paintChannel)*paintAlpha + float64(existingChannel)*existingAlpha) / blendedAlpha))
+	}
+	canvas.set(x, y, color.NRGBA64{
+		R: blendChannel(paintColor.R, existingColor.R),
+		G: blendChannel(paintColor.G, existingColor.G),
+		B: blendChannel(paintColor.B, existingColor.B),
+		A: uint16(math.Round(blendedAlpha * 0xffff)),
+	})
+}
@@ -0,0 +1,40 @@
+package render
+
+import (
+	"wallpaper/entities/command"
+)
+
+// applyOutputMode returns a command that renders its sample space, in place of the command's output mode.
+//   The lattice tile or frieze strip that replaced the sample space is returned as well, when there is one.
+func applyOutputMode(wallpaperCommand *command.CreateSymmetryPattern) (*command.CreateSymmetryPattern, *LatticeTile, *FriezeStrip, error) {
+	switch wallpaperCommand.OutputMode {
+	case command.OutputLatticeTile:
+		latticeTile, err := NewLatticeTile(wallpaperCommand)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return regionCommand(wallpaperCommand, latticeTile.SampleSpace, latticeTile.OutputSize), latticeTile, nil, nil
+	case command.OutputFriezePeriod:
+		friezeStrip, err := NewFriezeStrip(wallpaperCommand)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return regionCommand(wallpaperCommand, friezeStrip.SampleSpace, friezeStrip.OutputSize), nil, friezeStrip, nil
+	}
+	return wallpaperCommand, nil, nil, nil
+}
+
+// regionCommand returns a copy of the command that renders the sample space at the output size.
+//   The copy shares the command's formulas and options.
+func regionCommand(
+	wallpaperCommand *command.CreateSymmetryPattern,
+	sampleSpace command.ComplexNumberCorners,
+	outputSize command.WidthHeightDimensions,
+) *command.CreateSymmetryPattern {
+	copiedCommand := *wallpaperCommand
+	copiedCommand.OutputMode = command.OutputSampleSpace
+	copiedCommand.SampleSpace = sampleSpace
+	copiedCommand.Viewport = nil
+	copiedCommand.OutputImageSize = outputSize
+	return &copiedCommand
+}
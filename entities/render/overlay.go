@@ -0,0 +1,158 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"wallpaper/entities/command"
+)
+
+// sampleSpaceRegion returns the smallest rectangle in the sample space that holds the whole output image,
+//   grown by margin pixels on each side.
+func sampleSpaceRegion(sampleSpace sampleSpaceMapper, outputSize command.WidthHeightDimensions, margin float64) (complex128, complex128) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, corner := range []complex128{
+		complex(-margin, -margin),
+		complex(float64(outputSize.Width)+margin, -margin),
+		complex(-margin, float64(outputSize.Height)+margin),
+		complex(float64(outputSize.Width)+margin, float64(outputSize.Height)+margin),
+	} {
+		samplePoint := sampleSpace.scale(real(corner), imag(corner))
+		minX, maxX = math.Min(minX, real(samplePoint)), math.Max(maxX, real(samplePoint))
+		minY, maxY = math.Min(minY, imag(samplePoint)), math.Max(maxY, imag(samplePoint))
+	}
+	return complex(minX, minY), complex(maxX, maxY)
+}
+
+// pixelMapper maps points in the sample space back onto the output image, undoing a sampleSpaceMapper.
+//   Sample space mappers only move, scale and rotate, so three pixels are enough to undo them.
+type pixelMapper struct {
+	// origin is the sample point of pixel 0, 0.
+	origin complex128
+	// across and down are how far one pixel to the right and one pixel down move in the sample space.
+	across complex128
+	down   complex128
+}
+
+func newPixelMapper(sampleSpace sampleSpaceMapper) pixelMapper {
+	origin := sampleSpace.scale(0, 0)
+	return pixelMapper{
+		origin: origin,
+		across: sampleSpace.scale(1, 0) - origin,
+		down:   sampleSpace.scale(0, 1) - origin,
+	}
+}
+
+// pixel returns the pixel coordinates of the sample space point.
+func (mapper pixelMapper) pixel(samplePoint complex128) complex128 {
+	return mapper.distance(samplePoint - mapper.origin)
+}
+
+// distance returns how far the sample space distance moves in pixels, across and down.
+func (mapper pixelMapper) distance(sampleSpaceDistance complex128) complex128 {
+	determinant := real(mapper.across)*imag(mapper.down) - imag(mapper.across)*real(mapper.down)
+	pixelsAcross := (real(sampleSpaceDistance)*imag(mapper.down) - imag(sampleSpaceDistance)*real(mapper.down)) / determinant
+	pixelsDown := (real(mapper.across)*imag(sampleSpaceDistance) - imag(mapper.across)*real(sampleSpaceDistance)) / determinant
+	return complex(pixelsAcross, pixelsDown)
+}
+
+// edgeCoverage turns the distance outside of a shape's edge into how much of a pixel the shape covers.
+func edgeCoverage(distanceOutside float64) float64 {
+	return math.Max(0, math.Min(1, 0.5-distanceOutside))
+}
+
+// paintLine paints the line from start to end with flat ends, so lines that continue each other do not overlap.
+//   Lines with a dashLength of 0 are solid.
+func paintLine(
+	canvas *outputCanvas,
+	destinationBounds image.Rectangle,
+	paintColor color.NRGBA64,
+	start, end complex128,
+	width, dashLength, dashGap float64,
+) {
+	length := cmplx.Abs(end - start)
+	if length == 0 {
+		return
+	}
+	direction := (end - start) / complex(length, 0)
+	halfWidth := width / 2
+
+	paintShape(canvas, destinationBounds, paintColor,
+		math.Min(real(start), real(end))-halfWidth, math.Min(imag(start), imag(end))-halfWidth,
+		math.Max(real(start), real(end))+halfWidth, math.Max(imag(start), imag(end))+halfWidth,
+		func(point complex128) float64 {
+			alongLine := (point - start) / direction
+			if real(alongLine) < 0 || real(alongLine) >= length {
+				return 0
+			}
+			if dashLength > 0 && math.Mod(real(alongLine), dashLength+dashGap) > dashLength {
+				return 0
+			}
+			return edgeCoverage(math.Abs(imag(alongLine)) - halfWidth)
+		},
+	)
+}
+
+// paintShape blends the paint into every pixel in the box that the shape covers.
+func paintShape(
+	canvas *outputCanvas,
+	destinationBounds image.Rectangle,
+	paintColor color.NRGBA64,
+	minX, minY, maxX, maxY float64,
+	coverageAt func(point complex128) float64,
+) {
+	box := image.Rect(
+		int(math.Floor(minX)), int(math.Floor(minY)),
+		int(math.Ceil(maxX))+1, int(math.Ceil(maxY))+1,
+	).Intersect(destinationBounds)
+	for y := box.Min.Y; y < box.Max.Y; y++ {
+		for x := box.Min.X; x < box.Max.X; x++ {
+			if coverage := coverageAt(complex(float64(x), float64(y))); coverage > 0 {
+				canvas.blend(x, y, paintColor, coverage)
+			}
+		}
+	}
+}
+
+// paintConvexPolygon fills the convex polygon with the paint. Corners may go around either way.
+func paintConvexPolygon(canvas *outputCanvas, destinationBounds image.Rectangle, paintColor color.NRGBA64, corners []complex128) {
+	if len(corners) < 3 {
+		return
+	}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, corner := range corners {
+		minX, maxX = math.Min(minX, real(corner)), math.Max(maxX, real(corner))
+		minY, maxY = math.Min(minY, imag(corner)), math.Max(maxY, imag(corner))
+	}
+
+	// Turning each edge's direction a quarter turn points it outward, once the winding is known.
+	outward := complex(0, -1)
+	if twiceTheSignedArea(corners) < 0 {
+		outward = complex(0, 1)
+	}
+	paintShape(canvas, destinationBounds, paintColor, minX, minY, maxX, maxY,
+		func(point complex128) float64 {
+			farthestOutside := math.Inf(-1)
+			for index, corner := range corners {
+				edge := corners[(index+1)%len(corners)] - corner
+				normal := edge * outward / complex(cmplx.Abs(edge), 0)
+				offset := point - corner
+				farthestOutside = math.Max(farthestOutside, real(offset)*real(normal)+imag(offset)*imag(normal))
+			}
+			return edgeCoverage(farthestOutside)
+		},
+	)
+}
+
+// twiceTheSignedArea is positive when the corners go from the x axis toward the y axis.
+func twiceTheSignedArea(corners []complex128) float64 {
+	total := 0.0
+	for index, corner := range corners {
+		nextCorner := corners[(index+1)%len(corners)]
+		total += real(corner)*imag(nextCorner) - real(nextCorner)*imag(corner)
+	}
+	return total
+}
@@ -0,0 +1,36 @@
+package render_test
+
+import (
+	. "gopkg.in/check.v1"
+	"wallpaper/entities/command"
+	"wallpaper/entities/render"
+)
+
+type OverlaySuite struct{}
+
+var _ = Suite(&OverlaySuite{})
+
+func (suite *OverlaySuite) TestSVGLayerLeavesTheImageAlone(checker *C) {
+	svgOverlays := map[string]func(wallpaperCommand *command.CreateSymmetryPattern){
+		"symmetry_overlay": func(wallpaperCommand *command.CreateSymmetryPattern) {
+			wallpaperCommand.SymmetryOverlay = &command.SymmetryOverlay{OverlayOptions: command.OverlayOptions{Layer: command.OverlaySVG}}
+		},
+		"lattice_overlay": func(wallpaperCommand *command.CreateSymmetryPattern) {
+			wallpaperCommand.LatticeOverlay = &command.LatticeOverlay{OverlayOptions: command.OverlayOptions{Layer: command.OverlaySVG}}
+		},
+	}
+
+	plainCommand := newSquareLatticeCommand(checker)
+	plainCommand.SymmetryOverlay = nil
+	plainImage, _, err := render.Render(plainCommand, nil)
+	checker.Assert(err, IsNil)
+
+	for name, addOverlay := range svgOverlays {
+		wallpaperCommand := newSquareLatticeCommand(checker)
+		wallpaperCommand.SymmetryOverlay = nil
+		addOverlay(wallpaperCommand)
+		overlaidImage, _, err := render.Render(wallpaperCommand, nil)
+		checker.Assert(err, IsNil, Commentf(name))
+		checker.Assert(overlaidImage, DeepEquals, plainImage, Commentf(name))
+	}
+}
@@ -0,0 +1,316 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"wallpaper/entities/command"
+	"wallpaper/entities/formula/result"
+	"wallpaper/entities/mathutility"
+)
+
+// Report describes what happened during a render.
+type Report struct {
+	Symmetry *SymmetryAnalysis
+	// TransformedBounds contains every finite transformed coordinate.
+	TransformedBounds mathutility.BoundingBox
+	// ContributionBoundsByTerm contains each term's contribution to the transformed coordinates.
+	ContributionBoundsByTerm []mathutility.BoundingBox
+	// ColorValueSpace is the color value space that was used.
+	//   Commands with an automatic color value space can use it to render the same image again.
+	ColorValueSpace command.ComplexNumberCorners
+	// LatticeTile describes the repeat that was rendered, when the command uses the tile output mode.
+	LatticeTile *LatticeTile
+	// FriezeStrip describes the strip that was rendered, when the command uses the frieze_period output mode.
+	FriezeStrip *FriezeStrip
+	// SymmetryOverlay lists the symmetry elements in the output image, when the command has a symmetry_overlay.
+	//   With the image layer they are already drawn on the output image.
+	SymmetryOverlay *SymmetryOverlay
+	// LatticeOverlay lists the lattice cell edges and the shaded fundamental domain, when the command has a lattice_overlay.
+	//   With the image layer they are already drawn on the output image.
+	LatticeOverlay *LatticeOverlay
+}
+
+// Render transforms the colorSource image using the command's formula.
+//   It returns the output image and a report about the transformed values.
+//   Rows are rendered in bands spread across GOMAXPROCS workers; the output does not depend on the number of workers.
+//   Each pixel is transformed and colored as soon as it is sampled, so memory use
+//   does not grow with the number of pixels beyond the output image itself.
+//   With antialiasing, each pixel is sampled several times and the colors are averaged.
+//   Colors are calculated with 16 bits per channel, and the output image keeps 8 or 16 of them.
+//   colorSource may be nil if the command does not color using a source image, or if it generates its own.
+//   An automatic color value space is chosen by transforming a grid of pixels before rendering.
+//   In the tile and frieze_period output modes, one seamless repeat is rendered instead of the sample space.
+//   Lattice and symmetry overlays on the image layer are drawn over the finished image, in that order.
+//   The command is not modified, and nothing is read from or written to disk.
+func Render(wallpaperCommand *command.CreateSymmetryPattern, colorSource image.Image) (image.Image, *Report, error) {
+	return RenderWithTransformCache(wallpaperCommand, colorSource, nil)
+}
+
+// RenderWithTransformCache renders like Render, and saves or reuses the transformed value of every sample.
+//   An empty cache is filled while rendering. A filled cache is colored without calculating the formula,
+//   and must have been filled for a command with the same TransformCacheKey.
+//   A nil cache renders like Render.
+func RenderWithTransformCache(wallpaperCommand *command.CreateSymmetryPattern, colorSource image.Image, transformCache *TransformCache) (image.Image, *Report, error) {
+	return renderPattern(wallpaperCommand, colorSource, transformCache, nil)
+}
+
+// renderPattern renders like RenderWithTransformCache.
+//   numbering places the output image inside a bigger one, to seed jitter. nil numbers the output image's own pixels.
+func renderPattern(
+	wallpaperCommand *command.CreateSymmetryPattern,
+	colorSource image.Image,
+	transformCache *TransformCache,
+	numbering *pixelNumbering,
+) (image.Image, *Report, error) {
+	transformCacheKey := ""
+	if transformCache != nil {
+		key, err := wallpaperCommand.TransformCacheKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		transformCacheKey = key
+	}
+
+	wallpaperCommand, latticeTile, friezeStrip, err := applyOutputMode(wallpaperCommand)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outputWidth := wallpaperCommand.OutputImageSize.Width
+	outputHeight := wallpaperCommand.OutputImageSize.Height
+	if outputWidth <= 0 || outputHeight <= 0 {
+		return nil, nil, fmt.Errorf("output size must be positive: %dx%d", outputWidth, outputHeight)
+	}
+	if colorSource == nil && wallpaperCommand.UsesSourceImage() {
+		if wallpaperCommand.SampleSourceGenerator == nil {
+			return nil, nil, errors.New("a color source image is required")
+		}
+		generatedSource, err := wallpaperCommand.SampleSourceGenerator.Generate()
+		if err != nil {
+			return nil, nil, err
+		}
+		colorSource = generatedSource
+	}
+
+	calculator, symmetryAnalysis, err := prepareFormula(wallpaperCommand)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if numbering == nil {
+		numbering = &pixelNumbering{width: outputWidth}
+	}
+	destinationBounds := image.Rect(0, 0, outputWidth, outputHeight)
+	patternRenderer := &renderer{
+		calculator:         calculator,
+		destinationBounds:  destinationBounds,
+		sampleSpace:        newSampleSpaceMapper(wallpaperCommand, destinationBounds),
+		pixelSampler:       newPixelSampler(wallpaperCommand.Antialias, *numbering),
+		outputCanvas:       newOutputCanvas(wallpaperCommand.OutputBitDepth, destinationBounds),
+	}
+
+	if transformCache != nil {
+		err = patternRenderer.useTransformCache(transformCache, transformCacheKey, wallpaperCommand.OutputImageSize)
+		if err != nil {
+			return nil, nil, fmt.Errorf("transform cache: %v", err)
+		}
+	}
+
+	var symmetryOverlay *SymmetryOverlay
+	if wallpaperCommand.SymmetryOverlay != nil {
+		symmetryOverlay, err = newSymmetryOverlay(wallpaperCommand, symmetryAnalysis, patternRenderer.sampleSpace)
+		if err != nil {
+			return nil, nil, fmt.Errorf("symmetry_overlay: %v", err)
+		}
+	}
+	var latticeOverlay *LatticeOverlay
+	if wallpaperCommand.LatticeOverlay != nil {
+		latticeOverlay, err = newLatticeOverlay(wallpaperCommand, symmetryAnalysis, patternRenderer.sampleSpace)
+		if err != nil {
+			return nil, nil, fmt.Errorf("lattice_overlay: %v", err)
+		}
+	}
+
+	colorValueSpace, err := patternRenderer.setUpColoring(wallpaperCommand, colorSource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bands := splitIntoRowBands(outputHeight, rowBandHeight)
+	statisticsByBand := make([]*renderStatistics, len(bands))
+	processRowBandsInParallel(bands, func(bandIndex int, band rowBand) {
+		statisticsByBand[bandIndex] = patternRenderer.renderBand(band)
+	})
+
+	totalStatistics := &renderStatistics{}
+	for _, bandStatistics := range statisticsByBand {
+		totalStatistics.merge(bandStatistics)
+	}
+	if transformCache != nil {
+		if patternRenderer.replaysTransformCache {
+			totalStatistics.contributionBoundsByTerm = transformCache.ContributionBoundsByTerm
+		} else {
+			transformCache.ContributionBoundsByTerm = totalStatistics.contributionBoundsByTerm
+		}
+	}
+
+	if latticeOverlay != nil && !wallpaperCommand.LatticeOverlay.WritesSVG() {
+		latticeOverlay.drawOnto(patternRenderer.outputCanvas, destinationBounds)
+	}
+	if symmetryOverlay != nil && !wallpaperCommand.SymmetryOverlay.WritesSVG() {
+		symmetryOverlay.drawOnto(patternRenderer.outputCanvas, destinationBounds)
+	}
+
+	return patternRenderer.outputCanvas.image(), &Report{
+		Symmetry:                 symmetryAnalysis,
+		TransformedBounds:        totalStatistics.transformedBounds,
+		ContributionBoundsByTerm: totalStatistics.contributionBoundsByTerm,
+		ColorValueSpace:          colorValueSpace,
+		LatticeTile:              latticeTile,
+		FriezeStrip:              friezeStrip,
+		SymmetryOverlay:          symmetryOverlay,
+		LatticeOverlay:           latticeOverlay,
+	}, nil
+}
+
+// renderStatistics gathers the ranges of the transformed values as they are calculated.
+type renderStatistics struct {
+	transformedBounds        mathutility.BoundingBox
+	contributionBoundsByTerm []mathutility.BoundingBox
+}
+
+func (statistics *renderStatistics) add(formulaResult *result.CalculationResultForFormula) {
+	statistics.transformedBounds.Add(formulaResult.Total)
+	for termIndex, contribution := range formulaResult.ContributionByTerm {
+		if termIndex >= len(statistics.contributionBoundsByTerm) {
+			statistics.contributionBoundsByTerm = append(statistics.contributionBoundsByTerm, mathutility.BoundingBox{})
+		}
+		statistics.contributionBoundsByTerm[termIndex].Add(contribution)
+	}
+}
+
+func (statistics *renderStatistics) merge(other *renderStatistics) {
+	statistics.transformedBounds.Merge(other.transformedBounds)
+	for termIndex, termBounds := range other.contributionBoundsByTerm {
+		if termIndex >= len(statistics.contributionBoundsByTerm) {
+			statistics.contributionBoundsByTerm = append(statistics.contributionBoundsByTerm, mathutility.BoundingBox{})
+		}
+		statistics.contributionBoundsByTerm[termIndex].Merge(termBounds)
+	}
+}
+
+// renderer holds everything needed to render any band of the output image.
+//   Bands write to separate rows of outputCanvas, so they can be rendered at the same time.
+type renderer struct {
+	calculator         formulaCalculator
+	destinationBounds  image.Rectangle
+	sampleSpace        sampleSpaceMapper
+	pixelSampler       *pixelSampler
+	colorer            transformedValueColorer
+	outputCanvas       *outputCanvas
+	// transformCache is filled with every calculated sample, or replayed instead of calculating them.
+	transformCache        *TransformCache
+	replaysTransformCache bool
+}
+
+// renderBand samples, transforms and colors every pixel in the band, one row at a time.
+func (renderer *renderer) renderBand(band rowBand) *renderStatistics {
+	statistics := &renderStatistics{}
+	destinationBounds := renderer.destinationBounds
+	sampleColors := []color.NRGBA64{}
+	for destinationY := band.minY; destinationY < band.maxY; destinationY++ {
+		samplesByPixel := renderer.rowSamples(destinationY)
+		for destinationX := destinationBounds.Min.X; destinationX < destinationBounds.Max.X; destinationX++ {
+			sampleColors = sampleColors[:0]
+			for _, formulaResult := range samplesByPixel[destinationX-destinationBounds.Min.X] {
+				statistics.add(formulaResult)
+				sampleColors = append(sampleColors, renderer.colorer.ColorFor(formulaResult.Total))
+			}
+			renderer.outputCanvas.set(destinationX, destinationY, averageColors(sampleColors))
+		}
+	}
+	return statistics
+}
+
+// useTransformCache replays a filled cache, or prepares an empty cache to be filled.
+//   Empty caches also save the grid used to choose an automatic color value space,
+//   so later renders can choose one without calculating.
+func (renderer *renderer) useTransformCache(transformCache *TransformCache, key string, outputSize command.WidthHeightDimensions) error {
+	renderer.transformCache = transformCache
+	samplesPerPixel := renderer.pixelSampler.samplesPerPixel()
+	if transformCache.IsFilled() {
+		renderer.replaysTransformCache = true
+		return transformCache.checkFits(key, outputSize, samplesPerPixel)
+	}
+
+	transformCache.Key = key
+	transformCache.OutputSize = outputSize
+	transformCache.SamplesPerPixel = samplesPerPixel
+	transformCache.Values = make([]complex128, outputSize.Width*outputSize.Height*samplesPerPixel)
+	transformCache.ColorValueSamples = renderer.calculateColorValueSamples()
+	return nil
+}
+
+// rowSamples returns the transformed samples of every pixel in the destination row, grouped by pixel.
+//   They are read from the transform cache when it is replayed, and saved to it when it is filled.
+func (renderer *renderer) rowSamples(destinationY int) [][]*result.CalculationResultForFormula {
+	row := destinationY - renderer.destinationBounds.Min.Y
+	if renderer.replaysTransformCache {
+		return renderer.transformCache.rowSamples(row)
+	}
+
+	samplesByPixel := renderer.calculateRowSamples(destinationY)
+	if renderer.transformCache != nil {
+		renderer.transformCache.saveRowSamples(row, samplesByPixel)
+	}
+	return samplesByPixel
+}
+
+// calculateRowSamples transforms every sample for every pixel in the destination row.
+//   The results are grouped by pixel.
+func (renderer *renderer) calculateRowSamples(destinationY int) [][]*result.CalculationResultForFormula {
+	destinationBounds := renderer.destinationBounds
+	samplesByPixel := make([][]*result.CalculationResultForFormula, destinationBounds.Dx())
+
+	if renderer.pixelSampler.fixedOffsets == nil {
+		for destinationX := destinationBounds.Min.X; destinationX < destinationBounds.Max.X; destinationX++ {
+			for _, offset := range renderer.pixelSampler.offsetsForPixel(destinationX, destinationY) {
+				samplePoint := renderer.sampleSpace.scale(float64(destinationX)+offset.x, float64(destinationY)+offset.y)
+				samplesByPixel[destinationX-destinationBounds.Min.X] = append(
+					samplesByPixel[destinationX-destinationBounds.Min.X],
+					renderer.calculator.Calculate(samplePoint),
+				)
+			}
+		}
+		return samplesByPixel
+	}
+
+	for _, offset := range renderer.pixelSampler.fixedOffsets {
+		for pixelIndex, formulaResult := range renderer.calculateRow(destinationY, offset) {
+			samplesByPixel[pixelIndex] = append(samplesByPixel[pixelIndex], formulaResult)
+		}
+	}
+	return samplesByPixel
+}
+
+// calculateRow transforms one sample for every pixel in the destination row. Every sample is moved by the same offset.
+//   Formulas that can calculate whole rows (like lattice patterns) step from one pixel to the next.
+func (renderer *renderer) calculateRow(destinationY int, offset subpixelOffset) []*result.CalculationResultForFormula {
+	destinationBounds := renderer.destinationBounds
+	rowWidth := destinationBounds.Dx()
+	sampleY := float64(destinationY) + offset.y
+
+	if calculator, ok := renderer.calculator.(rowCalculator); ok {
+		rowStart := renderer.sampleSpace.scale(float64(destinationBounds.Min.X)+offset.x, sampleY)
+		rowStep := renderer.sampleSpace.scale(float64(destinationBounds.Min.X+1)+offset.x, sampleY) - rowStart
+		return calculator.CalculateRow(rowStart, rowStep, rowWidth)
+	}
+
+	rowResults := make([]*result.CalculationResultForFormula, rowWidth)
+	for destinationX := destinationBounds.Min.X; destinationX < destinationBounds.Max.X; destinationX++ {
+		rowResults[destinationX-destinationBounds.Min.X] = renderer.calculator.Calculate(renderer.sampleSpace.scale(float64(destinationX)+offset.x, sampleY))
+	}
+	return rowResults
+}
@@ -0,0 +1,316 @@
+package render_test
+
+import (
+	. "gopkg.in/check.v1"
+	"image"
+	"image/color"
+	"runtime"
+	"testing"
+	"wallpaper/entities/colorsource"
+	"wallpaper/entities/command"
+	"wallpaper/entities/render"
+	"wallpaper/entities/utility"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type RenderSuite struct {
+	colorSource image.Image
+}
+
+var _ = Suite(&RenderSuite{})
+
+func (suite *RenderSuite) SetUpTest(checker *C) {
+	colorSource := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	colorSource.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	colorSource.Set(1, 0, color.NRGBA{G: 255, A: 255})
+	colorSource.Set(0, 1, color.NRGBA{B: 255, A: 255})
+	colorSource.Set(1, 1, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	suite.colorSource = colorSource
+}
+
+func newRosetteCommand(checker *C) *command.CreateSymmetryPattern {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_size:
+  width: 4
+  height: 3
+sample_space:
+  minx: -1
+  miny: -1
+  maxx: 1
+  maxy: 1
+color_value_space:
+  minx: -2
+  miny: -2
+  maxx: 2
+  maxy: 2
+rosette_formula:
+  terms:
+    -
+      multiplier:
+        real: 1.0
+        imaginary: 0
+      power_n: 1
+      power_m: 0
+      ignore_complex_conjugate: true
+`))
+	checker.Assert(err, IsNil)
+	return wallpaperCommand
+}
+
+func (suite *RenderSuite) TestOutputImageHasTheOutputSize(checker *C) {
+	outputImage, _, err := render.Render(newRosetteCommand(checker), suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(outputImage.Bounds(), Equals, image.Rect(0, 0, 4, 3))
+}
+
+func (suite *RenderSuite) TestIdentityFormulaSamplesTheMatchingCornerOfTheSource(checker *C) {
+	outputImage, _, err := render.Render(newRosetteCommand(checker), suite.colorSource)
+	checker.Assert(err, IsNil)
+
+	checker.Assert(outputImage.At(0, 0), Equals, color.Color(color.NRGBA{R: 255, A: 255}))
+	checker.Assert(outputImage.At(3, 2), Equals, color.Color(color.NRGBA{R: 255, G: 255, B: 255, A: 255}))
+}
+
+func (suite *RenderSuite) TestReportContainsTheTransformedBounds(checker *C) {
+	_, report, err := render.Render(newRosetteCommand(checker), suite.colorSource)
+	checker.Assert(err, IsNil)
+
+	checker.Assert(real(report.TransformedBounds.Min), utility.NumericallyCloseEnough{}, -1, 1e-6)
+	checker.Assert(imag(report.TransformedBounds.Min), utility.NumericallyCloseEnough{}, -1, 1e-6)
+	checker.Assert(real(report.TransformedBounds.Max), utility.NumericallyCloseEnough{}, 0.5, 1e-6)
+	checker.Assert(imag(report.TransformedBounds.Max), utility.NumericallyCloseEnough{}, 1.0/3.0, 1e-6)
+	checker.Assert(report.ContributionBoundsByTerm, HasLen, 1)
+	checker.Assert(report.ContributionBoundsByTerm[0], Equals, report.TransformedBounds)
+	checker.Assert(report.Symmetry.Rosette.Multifold, Equals, 1)
+}
+
+func (suite *RenderSuite) TestRenderDoesNotModifyTheLatticePattern(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_size:
+  width: 4
+  height: 4
+sample_space:
+  minx: -1
+  miny: -1
+  maxx: 1
+  maxy: 1
+color_value_space:
+  minx: -2
+  miny: -2
+  maxx: 2
+  maxy: 2
+lattice_pattern:
+  lattice_type: square
+  multiplier:
+    real: 1
+    imaginary: 0
+  wave_packets:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      terms:
+        -
+          power_n: 1
+          power_m: -2
+  desired_symmetry: p4m
+`))
+	checker.Assert(err, IsNil)
+
+	_, firstReport, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+	_, secondReport, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+
+	checker.Assert(wallpaperCommand.LatticePattern.WavePackets, HasLen, 1)
+	checker.Assert(wallpaperCommand.LatticePattern.WavePackets[0].Terms, HasLen, 1)
+	checker.Assert(secondReport.TransformedBounds, Equals, firstReport.TransformedBounds)
+}
+
+func (suite *RenderSuite) TestRenderNeedsAFormula(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.RosetteFormula = nil
+
+	_, _, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, ErrorMatches, "no formula found")
+}
+
+func (suite *RenderSuite) TestRenderNeedsAColorSource(checker *C) {
+	_, _, err := render.Render(newRosetteCommand(checker), nil)
+	checker.Assert(err, ErrorMatches, "a color source image is required")
+}
+
+func (suite *RenderSuite) TestParallelRenderMatchesSerialRender(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.OutputImageSize = command.WidthHeightDimensions{Width: 37, Height: 83}
+
+	previousMaxProcs := runtime.GOMAXPROCS(1)
+	serialImage, serialReport, err := render.Render(wallpaperCommand, suite.colorSource)
+	runtime.GOMAXPROCS(8)
+	parallelImage, parallelReport, parallelErr := render.Render(wallpaperCommand, suite.colorSource)
+	runtime.GOMAXPROCS(previousMaxProcs)
+
+	checker.Assert(err, IsNil)
+	checker.Assert(parallelErr, IsNil)
+	checker.Assert(parallelImage.(*image.NRGBA).Pix, DeepEquals, serialImage.(*image.NRGBA).Pix)
+	checker.Assert(parallelReport, DeepEquals, serialReport)
+}
+
+func (suite *RenderSuite) TestOutOfRangeColors(checker *C) {
+	outOfRangeColorByOptions := map[command.OutOfRangeOptions]color.NRGBA{
+		{Mode: command.OutOfRangeTransparent}:                  {R: 0, G: 0, B: 0, A: 0},
+		{Mode: command.OutOfRangeBackground, Color: "#102030"}: {R: 0x10, G: 0x20, B: 0x30, A: 0xff},
+		{Mode: command.OutOfRangeClamp}:                        {R: 255, A: 255},
+		{Mode: command.OutOfRangeWrap}:                         {R: 255, G: 255, B: 255, A: 255},
+		{Mode: command.OutOfRangeMirror}:                       {R: 255, A: 255},
+	}
+
+	for options, expectedColor := range outOfRangeColorByOptions {
+		wallpaperCommand := newRosetteCommand(checker)
+		wallpaperCommand.ColorValueSpace = command.ComplexNumberCorners{MinX: -0.6, MinY: -0.6, MaxX: 0.6, MaxY: 0.6}
+		outOfRangeOptions := options
+		wallpaperCommand.OutOfRange = &outOfRangeOptions
+
+		outputImage, _, err := render.Render(wallpaperCommand, suite.colorSource)
+		checker.Assert(err, IsNil)
+		checker.Assert(outputImage.At(0, 0), Equals, color.Color(expectedColor), Commentf("mode %s", options.Mode))
+	}
+}
+
+func (suite *RenderSuite) TestBackgroundColorMustBeValid(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.OutOfRange = &command.OutOfRangeOptions{Mode: command.OutOfRangeBackground, Color: "green"}
+
+	_, _, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, ErrorMatches, "colors must look like #rrggbb or #rrggbbaa: green")
+}
+
+func (suite *RenderSuite) TestAutomaticColorValueSpaceIsReported(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.AutomaticColorValueSpace = &command.PercentileRange{Low: 0, High: 100}
+
+	outputImage, report, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+
+	checker.Assert(report.ColorValueSpace.MinX, utility.NumericallyCloseEnough{}, -1, 1e-6)
+	checker.Assert(report.ColorValueSpace.MinY, utility.NumericallyCloseEnough{}, -1, 1e-6)
+	checker.Assert(report.ColorValueSpace.MaxX, utility.NumericallyCloseEnough{}, 0.5, 1e-6)
+	checker.Assert(report.ColorValueSpace.MaxY, utility.NumericallyCloseEnough{}, 1.0/3.0, 1e-6)
+	checker.Assert(outputImage.At(0, 0), Equals, color.Color(color.NRGBA{R: 255, A: 255}))
+}
+
+func (suite *RenderSuite) TestAutomaticColorValueSpaceIgnoresExtremes(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.OutputImageSize = command.WidthHeightDimensions{Width: 101, Height: 1}
+	wallpaperCommand.AutomaticColorValueSpace = &command.PercentileRange{Low: 10, High: 90}
+
+	_, report, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+
+	checker.Assert(report.ColorValueSpace.MinX, utility.NumericallyCloseEnough{}, -1+20.0/101.0, 1e-6)
+	checker.Assert(report.ColorValueSpace.MaxX, utility.NumericallyCloseEnough{}, -1+180.0/101.0, 1e-6)
+}
+
+func (suite *RenderSuite) TestAutomaticColorValueSpaceSamplesTheMiddleOfEachBlock(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.OutputImageSize = command.WidthHeightDimensions{Width: 512, Height: 1}
+	wallpaperCommand.AutomaticColorValueSpace = &command.PercentileRange{Low: 0, High: 100}
+
+	_, report, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+
+	checker.Assert(report.ColorValueSpace.MinX, utility.NumericallyCloseEnough{}, -1+2.0/512.0, 1e-6)
+	checker.Assert(report.ColorValueSpace.MaxX, utility.NumericallyCloseEnough{}, 1-2.0/512.0, 1e-6)
+}
+
+func (suite *RenderSuite) TestReportIncludesTheGivenColorValueSpace(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	_, report, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(report.ColorValueSpace, Equals, wallpaperCommand.ColorValueSpace)
+}
+
+func (suite *RenderSuite) TestDomainColoringNeedsNoColorSource(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.Coloring = command.ColorByDomain
+
+	outputImage, _, err := render.Render(wallpaperCommand, nil)
+	checker.Assert(err, IsNil)
+	checker.Assert(outputImage.At(2, 0), Equals, color.Color(colorsource.EightBitColor((&colorsource.DomainColoring{}).ColorFor(complex(0, -1)))))
+}
+
+func (suite *RenderSuite) TestDomainColoringUsesItsOptions(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.Coloring = command.ColorByDomain
+	wallpaperCommand.DomainColoring = &colorsource.DomainColoring{PhaseContours: 6}
+
+	outputImage, _, err := render.Render(wallpaperCommand, nil)
+	checker.Assert(err, IsNil)
+	checker.Assert(outputImage.At(0, 0), Equals, color.Color(colorsource.EightBitColor(wallpaperCommand.DomainColoring.ColorFor(complex(-1, -1)))))
+}
+
+func (suite *RenderSuite) TestGeneratedColorSource(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.SampleSourceGenerator = &colorsource.Generator{
+		Type:   colorsource.Checkerboard,
+		Width:  2,
+		Height: 2,
+		Count:  2,
+		Colors: []string{"#ff0000", "#00ff00"},
+	}
+
+	outputImage, _, err := render.Render(wallpaperCommand, nil)
+	checker.Assert(err, IsNil)
+	checker.Assert(outputImage.At(0, 0), Equals, color.Color(color.NRGBA{R: 255, A: 255}))
+	checker.Assert(outputImage.At(2, 0), Equals, color.Color(color.NRGBA{G: 255, A: 255}))
+}
+
+func (suite *RenderSuite) TestPaletteColoringNeedsNoColorSource(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.Coloring = command.ColorByPalette
+	wallpaperCommand.Palette = &colorsource.Palette{
+		Value:   colorsource.RealPart,
+		Minimum: -1,
+		Maximum: 1,
+		Stops: []colorsource.ColorStop{
+			{Position: 0, Color: "#000000"},
+			{Position: 1, Color: "#ffffff"},
+		},
+	}
+	compiledPalette, err := wallpaperCommand.Palette.Compile()
+	checker.Assert(err, IsNil)
+
+	outputImage, _, err := render.Render(wallpaperCommand, nil)
+	checker.Assert(err, IsNil)
+	checker.Assert(outputImage.At(0, 0), Equals, color.Color(colorsource.EightBitColor(compiledPalette.ColorFor(complex(-1, -1)))))
+	checker.Assert(outputImage.At(0, 0), Equals, color.Color(color.NRGBA{A: 255}))
+}
+
+func (suite *RenderSuite) TestPaletteColoringNeedsAPalette(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.Coloring = command.ColorByPalette
+	_, _, err := render.Render(wallpaperCommand, nil)
+	checker.Assert(err, ErrorMatches, "palette coloring needs a palette")
+}
+
+func (suite *RenderSuite) TestSixteenBitOutputKeepsTheSourcePrecision(checker *C) {
+	sixteenBitSource := image.NewNRGBA64(image.Rect(0, 0, 1, 1))
+	sixteenBitSource.SetNRGBA64(0, 0, color.NRGBA64{R: 0x1234, G: 0x5678, B: 0x9abc, A: 0xffff})
+
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.OutputBitDepth = 16
+	outputImage, _, err := render.Render(wallpaperCommand, sixteenBitSource)
+	checker.Assert(err, IsNil)
+
+	sixteenBitImage, isSixteenBit := outputImage.(*image.NRGBA64)
+	checker.Assert(isSixteenBit, Equals, true)
+	checker.Assert(sixteenBitImage.NRGBA64At(1, 1), Equals, color.NRGBA64{R: 0x1234, G: 0x5678, B: 0x9abc, A: 0xffff})
+}
+
+func (suite *RenderSuite) TestEightBitOutputIsTheDefault(checker *C) {
+	outputImage, _, err := render.Render(newRosetteCommand(checker), suite.colorSource)
+	checker.Assert(err, IsNil)
+	_, isEightBit := outputImage.(*image.NRGBA)
+	checker.Assert(isEightBit, Equals, true)
+}
@@ -0,0 +1,82 @@
+package render
+
+import (
+	"image"
+	"math"
+	"math/cmplx"
+	"wallpaper/entities/command"
+	"wallpaper/entities/mathutility"
+)
+
+// sampleSpaceMapper maps output pixels onto the sample space.
+type sampleSpaceMapper interface {
+	// scale returns the point in the sample space that lines up with the destination pixel.
+	//   The pixel may be fractional, to sample between pixels, or lie just outside of the destination.
+	scale(destinationX, destinationY float64) complex128
+}
+
+// newSampleSpaceMapper uses the command's viewport if it has one, otherwise it stretches the sample space corners over the output.
+func newSampleSpaceMapper(wallpaperCommand *command.CreateSymmetryPattern, destinationBounds image.Rectangle) sampleSpaceMapper {
+	if wallpaperCommand.Viewport != nil {
+		return newViewportTransform(wallpaperCommand.Viewport, destinationBounds)
+	}
+	return sampleSpaceScaler{
+		destinationBounds: destinationBounds,
+		sampleSpaceMin:    complex(wallpaperCommand.SampleSpace.MinX, wallpaperCommand.SampleSpace.MinY),
+		sampleSpaceMax:    complex(wallpaperCommand.SampleSpace.MaxX, wallpaperCommand.SampleSpace.MaxY),
+	}
+}
+
+// sampleSpaceScaler maps output pixels onto the sample space.
+type sampleSpaceScaler struct {
+	destinationBounds image.Rectangle
+	sampleSpaceMin    complex128
+	sampleSpaceMax    complex128
+}
+
+// scale stretches the sample space corners over the destination.
+func (scaler sampleSpaceScaler) scale(destinationX, destinationY float64) complex128 {
+	destinationScaledX := mathutility.ExtendValueBetweenTwoRanges(
+		destinationX,
+		float64(scaler.destinationBounds.Min.X),
+		float64(scaler.destinationBounds.Max.X),
+		real(scaler.sampleSpaceMin),
+		real(scaler.sampleSpaceMax),
+	)
+	destinationScaledY := mathutility.ExtendValueBetweenTwoRanges(
+		destinationY,
+		float64(scaler.destinationBounds.Min.Y),
+		float64(scaler.destinationBounds.Max.Y),
+		imag(scaler.sampleSpaceMin),
+		imag(scaler.sampleSpaceMax),
+	)
+	return complex(destinationScaledX, destinationScaledY)
+}
+
+
+// viewportTransform maps output pixels onto the sample space by moving, scaling and rotating them.
+type viewportTransform struct {
+	// destinationCenter is the pixel that lines up with the viewport's center.
+	destinationCenter complex128
+	center            complex128
+	// pixelStep is how far one pixel to the right moves in the sample space. One pixel down moves i times as far.
+	pixelStep complex128
+}
+
+func newViewportTransform(viewport *command.Viewport, destinationBounds image.Rectangle) viewportTransform {
+	rotationInRadians := viewport.Rotation * math.Pi / 180
+	return viewportTransform{
+		destinationCenter: complex(
+			float64(destinationBounds.Min.X)+float64(destinationBounds.Dx())/2,
+			float64(destinationBounds.Min.Y)+float64(destinationBounds.Dy())/2,
+		),
+		center:    complex(viewport.Center.Real, viewport.Center.Imaginary),
+		pixelStep: complex(viewport.Zoom, 0) * cmplx.Rect(1, rotationInRadians),
+	}
+}
+
+// scale returns the point in the sample space that lines up with the destination pixel.
+func (transform viewportTransform) scale(destinationX, destinationY float64) complex128 {
+	distanceFromCenter := complex(destinationX, destinationY) - transform.destinationCenter
+	return transform.center + distanceFromCenter*transform.pixelStep
+}
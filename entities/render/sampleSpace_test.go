@@ -0,0 +1,66 @@
+package render_test
+
+import (
+	. "gopkg.in/check.v1"
+	"image"
+	"image/color"
+	"wallpaper/entities/command"
+	"wallpaper/entities/render"
+	"wallpaper/entities/utility"
+)
+
+type ViewportSuite struct {
+	colorSource image.Image
+}
+
+var _ = Suite(&ViewportSuite{})
+
+func (suite *ViewportSuite) SetUpTest(checker *C) {
+	colorSource := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	colorSource.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	colorSource.Set(1, 0, color.NRGBA{G: 255, A: 255})
+	colorSource.Set(0, 1, color.NRGBA{B: 255, A: 255})
+	colorSource.Set(1, 1, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	suite.colorSource = colorSource
+}
+
+func (suite *ViewportSuite) TestViewportMatchesTheSameSampleSpace(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.OutputImageSize = command.WidthHeightDimensions{Width: 8, Height: 8}
+	sampleSpaceImage, _, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+
+	wallpaperCommand.SampleSpace = command.ComplexNumberCorners{}
+	wallpaperCommand.Viewport = &command.Viewport{Zoom: 0.25}
+	viewportImage, _, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+
+	checker.Assert(viewportImage.(*image.NRGBA).Pix, DeepEquals, sampleSpaceImage.(*image.NRGBA).Pix)
+}
+
+func (suite *ViewportSuite) TestViewportKeepsTheAspectRatio(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.OutputImageSize = command.WidthHeightDimensions{Width: 8, Height: 2}
+	wallpaperCommand.Viewport = &command.Viewport{Zoom: 0.25}
+
+	_, report, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(real(report.TransformedBounds.Min), utility.NumericallyCloseEnough{}, -1, 1e-6)
+	checker.Assert(imag(report.TransformedBounds.Min), utility.NumericallyCloseEnough{}, -0.25, 1e-6)
+	checker.Assert(real(report.TransformedBounds.Max), utility.NumericallyCloseEnough{}, 0.75, 1e-6)
+	checker.Assert(imag(report.TransformedBounds.Max), utility.NumericallyCloseEnough{}, 0, 1e-6)
+}
+
+func (suite *ViewportSuite) TestViewportRotatesAroundTheCenter(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.OutputImageSize = command.WidthHeightDimensions{Width: 4, Height: 4}
+	wallpaperCommand.Viewport = &command.Viewport{
+		Zoom:     0.5,
+		Rotation: 90,
+	}
+
+	outputImage, _, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(outputImage.At(0, 0), Equals, color.Color(color.NRGBA{G: 255, A: 255}))
+	checker.Assert(outputImage.At(3, 3), Equals, color.Color(color.NRGBA{B: 255, A: 255}))
+}
@@ -0,0 +1,167 @@
+package render
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"wallpaper/entities/colorsource"
+	"wallpaper/entities/command"
+	"wallpaper/entities/formula/symmetryelement"
+)
+
+// SymmetryOverlay holds the symmetry elements of the rendered pattern, moved into the output image.
+//   Points are pixel coordinates, with x in the real part and y in the imaginary part.
+//   Pixel x, y is centered on the point x + yi, because that is where its sample was taken.
+type SymmetryOverlay struct {
+	Elements   *symmetryelement.Set
+	OutputSize command.WidthHeightDimensions
+	Color      color.NRGBA
+	LineWidth  float64
+}
+
+// MarkerRadius is how far each rotation center's marker reaches from the center, in pixels.
+func (overlay *SymmetryOverlay) MarkerRadius() float64 {
+	return 4 * overlay.LineWidth
+}
+
+// DashLength is the length of the dashes along glide axes, in pixels.
+func (overlay *SymmetryOverlay) DashLength() float64 {
+	return 4 * overlay.LineWidth
+}
+
+// DashGap is the length of the gaps between the dashes along glide axes, in pixels.
+func (overlay *SymmetryOverlay) DashGap() float64 {
+	return 3 * overlay.LineWidth
+}
+
+// newSymmetryOverlay finds the symmetry elements in the part of the sample space that the output image shows.
+//   Elements just outside of the image are kept, so their lines and markers can reach into it.
+func newSymmetryOverlay(
+	wallpaperCommand *command.CreateSymmetryPattern,
+	symmetryAnalysis *SymmetryAnalysis,
+	sampleSpace sampleSpaceMapper,
+) (*SymmetryOverlay, error) {
+	options := wallpaperCommand.SymmetryOverlay
+	overlayColor, err := colorsource.ParseHexColor(options.OverlayColor())
+	if err != nil {
+		return nil, err
+	}
+	overlay := &SymmetryOverlay{
+		OutputSize: wallpaperCommand.OutputImageSize,
+		Color:      overlayColor,
+		LineWidth:  options.OverlayLineWidth(),
+	}
+
+	margin := overlay.MarkerRadius() + overlay.LineWidth
+	regionMin, regionMax := sampleSpaceRegion(sampleSpace, overlay.OutputSize, margin)
+	sampleSpaceElements, err := findSymmetryElements(symmetryAnalysis, regionMin, regionMax)
+	if err != nil {
+		return nil, err
+	}
+
+	mapper := newPixelMapper(sampleSpace)
+	overlay.Elements = &symmetryelement.Set{}
+	for _, mirror := range sampleSpaceElements.Mirrors {
+		overlay.Elements.Mirrors = append(overlay.Elements.Mirrors, symmetryelement.Line{
+			Start: mapper.pixel(mirror.Start),
+			End:   mapper.pixel(mirror.End),
+		})
+	}
+	for _, glide := range sampleSpaceElements.Glides {
+		overlay.Elements.Glides = append(overlay.Elements.Glides, symmetryelement.Line{
+			Start: mapper.pixel(glide.Start),
+			End:   mapper.pixel(glide.End),
+			Glide: mapper.distance(glide.Glide),
+		})
+	}
+	for _, rotationCenter := range sampleSpaceElements.RotationCenters {
+		overlay.Elements.RotationCenters = append(overlay.Elements.RotationCenters, symmetryelement.RotationCenter{
+			Center: mapper.pixel(rotationCenter.Center),
+			Order:  rotationCenter.Order,
+		})
+	}
+	return overlay, nil
+}
+
+// findSymmetryElements lists the elements of the analyzed formula in the sample space rectangle.
+func findSymmetryElements(symmetryAnalysis *SymmetryAnalysis, regionMin, regionMax complex128) (*symmetryelement.Set, error) {
+	switch {
+	case symmetryAnalysis.Frieze != nil:
+		return symmetryelement.FriezeElements(symmetryAnalysis.Frieze, regionMin, regionMax)
+	case symmetryAnalysis.Rosette != nil:
+		return symmetryelement.RosetteElements(symmetryAnalysis.Rosette, regionMin, regionMax), nil
+	case symmetryAnalysis.LatticeVectors != nil:
+		return symmetryelement.LatticeElements(symmetryAnalysis.Lattice, symmetryAnalysis.LatticeVectors, regionMin, regionMax)
+	}
+	return nil, errors.New("no symmetries were analyzed")
+}
+
+// drawOnto paints the overlay over the output image: mirror lines, then dashed glide axes, then rotation center markers.
+//   Edges are antialiased by how much of each pixel the line or marker covers.
+func (overlay *SymmetryOverlay) drawOnto(canvas *outputCanvas, destinationBounds image.Rectangle) {
+	paintColor := colorsource.SixteenBitColor(overlay.Color)
+	for _, mirror := range overlay.Elements.Mirrors {
+		overlay.drawLine(canvas, destinationBounds, paintColor, mirror, false)
+	}
+	for _, glide := range overlay.Elements.Glides {
+		overlay.drawLine(canvas, destinationBounds, paintColor, glide, true)
+	}
+	for _, rotationCenter := range overlay.Elements.RotationCenters {
+		overlay.drawMarker(canvas, destinationBounds, paintColor, rotationCenter)
+	}
+}
+
+// drawLine paints a mirror line, or a dashed glide axis.
+func (overlay *SymmetryOverlay) drawLine(canvas *outputCanvas, destinationBounds image.Rectangle, paintColor color.NRGBA64, line symmetryelement.Line, dashed bool) {
+	dashLength, dashGap := 0.0, 0.0
+	if dashed {
+		dashLength, dashGap = overlay.DashLength(), overlay.DashGap()
+	}
+	paintLine(canvas, destinationBounds, paintColor, line.Start, line.End, overlay.LineWidth, dashLength, dashGap)
+}
+
+// drawMarker paints an n-sided shape for an n-fold rotation center, and an oval for a 2-fold one.
+func (overlay *SymmetryOverlay) drawMarker(canvas *outputCanvas, destinationBounds image.Rectangle, paintColor color.NRGBA64, rotationCenter symmetryelement.RotationCenter) {
+	radius := overlay.MarkerRadius()
+	center := rotationCenter.Center
+	paintShape(canvas, destinationBounds, paintColor,
+		real(center)-radius, imag(center)-radius,
+		real(center)+radius, imag(center)+radius,
+		func(point complex128) float64 {
+			return edgeCoverage(markerEdgeDistance(point-center, radius, rotationCenter.Order))
+		},
+	)
+}
+
+// markerEdgeDistance returns about how far the offset from the marker's center lies outside of the marker.
+//   It is negative inside of the marker.
+func markerEdgeDistance(offset complex128, radius float64, order int) float64 {
+	if order == 2 {
+		ovalHeight := radius / 2
+		return (math.Hypot(real(offset)/radius, imag(offset)/ovalHeight) - 1) * ovalHeight
+	}
+
+	// Each side is as far from the center as the middle of the side, and faces out between two corners.
+	sideDistance := radius * math.Cos(math.Pi/float64(order))
+	farthestOutside := math.Inf(-1)
+	for side := 0; side < order; side++ {
+		sideDirection := cmplx.Rect(1, markerFirstCornerAngle+math.Pi*float64(2*side+1)/float64(order))
+		farthestOutside = math.Max(farthestOutside, real(offset)*real(sideDirection)+imag(offset)*imag(sideDirection)-sideDistance)
+	}
+	return farthestOutside
+}
+
+// markerFirstCornerAngle points the first corner of each marker straight up the image.
+const markerFirstCornerAngle = -math.Pi / 2
+
+// markerCorners returns the corners of the n-sided marker for an n-fold rotation center.
+func markerCorners(center complex128, radius float64, order int) []complex128 {
+	corners := []complex128{}
+	for corner := 0; corner < order; corner++ {
+		corners = append(corners, center+cmplx.Rect(radius, markerFirstCornerAngle+2*math.Pi*float64(corner)/float64(order)))
+	}
+	return corners
+}
+
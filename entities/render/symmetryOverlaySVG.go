@@ -0,0 +1,84 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"wallpaper/entities/command"
+)
+
+// WriteSymmetryOverlaySVG writes the overlay as an SVG image the same size as the output image,
+//   so it lines up when it is laid over the image.
+//   Mirror lines, glide axes and rotation centers are in separate groups, so each can be hidden or restyled.
+func WriteSymmetryOverlaySVG(writer io.Writer, overlay *SymmetryOverlay) error {
+	color, opacity := svgColor(overlay.Color)
+
+	svg := &strings.Builder{}
+	writeSVGStart(svg, overlay.OutputSize)
+	fmt.Fprintf(svg, "  <g transform=\"translate(0.5 0.5)\" fill=\"none\" stroke=\"%s\" stroke-opacity=\"%s\" stroke-width=\"%s\">\n",
+		color, opacity, svgNumber(overlay.LineWidth))
+
+	svg.WriteString("    <g id=\"mirrors\">\n")
+	for _, mirror := range overlay.Elements.Mirrors {
+		writeSVGLine(svg, mirror.Start, mirror.End)
+	}
+	svg.WriteString("    </g>\n")
+
+	fmt.Fprintf(svg, "    <g id=\"glides\" stroke-dasharray=\"%s %s\">\n", svgNumber(overlay.DashLength()), svgNumber(overlay.DashGap()))
+	for _, glide := range overlay.Elements.Glides {
+		writeSVGLine(svg, glide.Start, glide.End)
+	}
+	svg.WriteString("    </g>\n")
+
+	fmt.Fprintf(svg, "    <g id=\"rotation_centers\" stroke=\"none\" fill=\"%s\" fill-opacity=\"%s\">\n", color, opacity)
+	for _, rotationCenter := range overlay.Elements.RotationCenters {
+		center := rotationCenter.Center
+		radius := overlay.MarkerRadius()
+		if rotationCenter.Order == 2 {
+			fmt.Fprintf(svg, "      <ellipse cx=\"%s\" cy=\"%s\" rx=\"%s\" ry=\"%s\"/>\n",
+				svgNumber(real(center)), svgNumber(imag(center)), svgNumber(radius), svgNumber(radius/2))
+			continue
+		}
+		fmt.Fprintf(svg, "      <polygon points=\"%s\"/>\n", svgPoints(markerCorners(center, radius, rotationCenter.Order)))
+	}
+	svg.WriteString("    </g>\n")
+
+	svg.WriteString("  </g>\n</svg>\n")
+	_, err := io.WriteString(writer, svg.String())
+	return err
+}
+
+// writeSVGStart opens an SVG image the same size as the output image.
+//   Groups inside it should move by half a pixel: pixel x, y covers the square from x to x + 1,
+//   but its sample was taken at x, y.
+func writeSVGStart(svg *strings.Builder, outputSize command.WidthHeightDimensions) {
+	fmt.Fprintf(svg, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		outputSize.Width, outputSize.Height, outputSize.Width, outputSize.Height)
+}
+
+// svgColor returns the color as "#rrggbb", and its opacity.
+func svgColor(nrgbaColor color.NRGBA) (string, string) {
+	return fmt.Sprintf("#%02x%02x%02x", nrgbaColor.R, nrgbaColor.G, nrgbaColor.B), svgNumber(float64(nrgbaColor.A) / 0xff)
+}
+
+// svgPoints lists the corners for a polygon's points attribute.
+func svgPoints(corners []complex128) string {
+	points := []string{}
+	for _, corner := range corners {
+		points = append(points, svgNumber(real(corner))+","+svgNumber(imag(corner)))
+	}
+	return strings.Join(points, " ")
+}
+
+func writeSVGLine(svg *strings.Builder, start, end complex128) {
+	fmt.Fprintf(svg, "      <line x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\"/>\n",
+		svgNumber(real(start)), svgNumber(imag(start)), svgNumber(real(end)), svgNumber(imag(end)))
+}
+
+// svgNumber writes the number with up to 3 decimal places, which is finer than a pixel needs.
+func svgNumber(value float64) string {
+	return strconv.FormatFloat(math.Round(value*1000)/1000, 'f', -1, 64)
+}
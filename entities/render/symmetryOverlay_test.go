@@ -0,0 +1,185 @@
+package render_test
+
+import (
+	"bytes"
+	. "gopkg.in/check.v1"
+	"image"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"strings"
+	"wallpaper/entities/command"
+	"wallpaper/entities/formula/coefficient"
+	"wallpaper/entities/formula/frieze"
+	"wallpaper/entities/formula/rosette"
+	"wallpaper/entities/render"
+	"wallpaper/entities/utility"
+)
+
+type SymmetryOverlaySuite struct{}
+
+var _ = Suite(&SymmetryOverlaySuite{})
+
+func newSquareLatticeCommand(checker *C) *command.CreateSymmetryPattern {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_size:
+  width: 40
+  height: 40
+sample_space:
+  minx: 0
+  miny: 0
+  maxx: 1
+  maxy: 1
+coloring: domain
+lattice_pattern:
+  lattice_type: square
+  multiplier:
+    real: 1
+    imaginary: 0
+  wave_packets:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      terms:
+        -
+          power_n: 1
+          power_m: 2
+  desired_symmetry: p4m
+symmetry_overlay:
+  color: "#00ff00"
+  line_width: 2
+`))
+	checker.Assert(err, IsNil)
+	return wallpaperCommand
+}
+
+func (suite *SymmetryOverlaySuite) TestLatticeElementsAreMovedIntoTheOutputImage(checker *C) {
+	_, report, err := render.Render(newSquareLatticeCommand(checker), nil)
+	checker.Assert(err, IsNil)
+	overlay := report.SymmetryOverlay
+	checker.Assert(overlay, NotNil)
+	checker.Assert(overlay.OutputSize, Equals, command.WidthHeightDimensions{Width: 40, Height: 40})
+
+	ordersByCenter := map[image.Point]int{}
+	for _, rotationCenter := range overlay.Elements.RotationCenters {
+		ordersByCenter[image.Pt(int(real(rotationCenter.Center)+0.5), int(imag(rotationCenter.Center)+0.5))] = rotationCenter.Order
+	}
+	checker.Assert(ordersByCenter[image.Pt(0, 0)], Equals, 4)
+	checker.Assert(ordersByCenter[image.Pt(20, 20)], Equals, 4)
+	checker.Assert(ordersByCenter[image.Pt(20, 0)], Equals, 2)
+	checker.Assert(ordersByCenter[image.Pt(40, 40)], Equals, 4)
+
+	foundDiagonalMirror := false
+	for _, mirror := range overlay.Elements.Mirrors {
+		if cmplx.Abs(mirror.Start) < 1e-9 && cmplx.Abs(mirror.End-complex(40, 40)) < 1e-9 {
+			foundDiagonalMirror = true
+		}
+	}
+	checker.Assert(foundDiagonalMirror, Equals, true)
+	for _, glide := range overlay.Elements.Glides {
+		checker.Assert(math.Abs(real(glide.Glide)), utility.NumericallyCloseEnough{}, 20.0, 1e-9)
+		checker.Assert(math.Abs(imag(glide.Glide)), utility.NumericallyCloseEnough{}, 20.0, 1e-9)
+	}
+}
+
+func (suite *SymmetryOverlaySuite) TestImageLayerIsDrawnOverThePattern(checker *C) {
+	overlaidImage, _, err := render.Render(newSquareLatticeCommand(checker), nil)
+	checker.Assert(err, IsNil)
+
+	wallpaperCommand := newSquareLatticeCommand(checker)
+	wallpaperCommand.SymmetryOverlay = nil
+	plainImage, _, err := render.Render(wallpaperCommand, nil)
+	checker.Assert(err, IsNil)
+
+	green := color.Color(color.NRGBA{G: 255, A: 255})
+	checker.Assert(overlaidImage.At(20, 20), Equals, green)
+	checker.Assert(overlaidImage.At(10, 10), Equals, green)
+	checker.Assert(overlaidImage.At(30, 20), Equals, green)
+	checker.Assert(overlaidImage.At(7, 3), Equals, plainImage.At(7, 3))
+	checker.Assert(plainImage.At(20, 20), Not(Equals), green)
+}
+
+func (suite *SymmetryOverlaySuite) TestSVGMarksEveryElement(checker *C) {
+	wallpaperCommand := newSquareLatticeCommand(checker)
+	wallpaperCommand.SymmetryOverlay.Layer = command.OverlaySVG
+	_, report, err := render.Render(wallpaperCommand, nil)
+	checker.Assert(err, IsNil)
+
+	svg := &bytes.Buffer{}
+	checker.Assert(render.WriteSymmetryOverlaySVG(svg, report.SymmetryOverlay), IsNil)
+	checker.Assert(strings.HasPrefix(svg.String(), `<svg xmlns="http://www.w3.org/2000/svg" width="40" height="40" viewBox="0 0 40 40">`), Equals, true)
+	checker.Assert(svg.String(), Matches, `(?s).*stroke="#00ff00" stroke-opacity="1" stroke-width="2".*`)
+	checker.Assert(svg.String(), Matches, `(?s).*<line x1="0" y1="0" x2="40" y2="40"/>.*`)
+	checker.Assert(svg.String(), Matches, `(?s).*<g id="glides" stroke-dasharray="8 6">\s*<line .*`)
+	checker.Assert(svg.String(), Matches, `(?s).*<polygon points="20,12 28,20 20,28 12,20"/>.*`)
+	checker.Assert(svg.String(), Matches, `(?s).*<ellipse cx="20" cy="0" rx="8" ry="4"/>.*`)
+}
+
+func (suite *SymmetryOverlaySuite) TestViewportIsUndone(checker *C) {
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML([]byte(`
+output_size:
+  width: 100
+  height: 100
+viewport:
+  center:
+    real: 0.25
+    imaginary: 0
+  zoom: 0.01
+  rotation: 90
+coloring: domain
+rosette_formula:
+  terms:
+    -
+      multiplier:
+        real: 1
+        imaginary: 0
+      power_n: 3
+      power_m: 0
+symmetry_overlay:
+  layer: svg
+`))
+	checker.Assert(err, IsNil)
+	_, report, err := render.Render(wallpaperCommand, nil)
+	checker.Assert(err, IsNil)
+	checker.Assert(report.Symmetry.Rosette, DeepEquals, &rosette.Symmetry{Multifold: 3})
+
+	rotationCenters := report.SymmetryOverlay.Elements.RotationCenters
+	checker.Assert(rotationCenters, HasLen, 1)
+	checker.Assert(rotationCenters[0].Order, Equals, 3)
+	checker.Assert(real(rotationCenters[0].Center), utility.NumericallyCloseEnough{}, 50.0, 1e-9)
+	checker.Assert(imag(rotationCenters[0].Center), utility.NumericallyCloseEnough{}, 75.0, 1e-9)
+}
+
+func (suite *SymmetryOverlaySuite) TestFriezeMirrorsLineUpWithTheStrip(checker *C) {
+	wallpaperCommand := newFriezeStripCommand(checker)
+	for _, term := range wallpaperCommand.FriezeFormula.Terms {
+		term.CoefficientRelationships = []coefficient.Relationship{coefficient.PlusMPlusN}
+	}
+	wallpaperCommand.Coloring = command.ColorByDomain
+	wallpaperCommand.SymmetryOverlay = &command.SymmetryOverlay{OverlayOptions: command.OverlayOptions{Layer: command.OverlaySVG}}
+	_, report, err := render.Render(wallpaperCommand, nil)
+	checker.Assert(err, IsNil)
+	checker.Assert(report.Symmetry.Frieze.P1m1, Equals, true)
+
+	stripSampleSpace := report.FriezeStrip.SampleSpace
+	pixelsPerUnit := float64(report.FriezeStrip.OutputSize.Width) / (stripSampleSpace.MaxX - stripSampleSpace.MinX)
+	mirrorsInTheStrip := 0
+	for _, mirror := range report.SymmetryOverlay.Elements.Mirrors {
+		checker.Assert(real(mirror.Start), Equals, real(mirror.End))
+		mirrorX := real(mirror.Start)/pixelsPerUnit + stripSampleSpace.MinX
+		halfPeriods := mirrorX / (frieze.Period / 2)
+		checker.Assert(halfPeriods, utility.NumericallyCloseEnough{}, math.Round(halfPeriods), 1e-9)
+		if real(mirror.Start) >= 0 && real(mirror.Start) < float64(report.FriezeStrip.OutputSize.Width) {
+			mirrorsInTheStrip++
+		}
+	}
+	checker.Assert(mirrorsInTheStrip, Equals, 4)
+}
+
+func (suite *SymmetryOverlaySuite) TestTooManyLatticeCellsIsAnError(checker *C) {
+	wallpaperCommand := newSquareLatticeCommand(checker)
+	wallpaperCommand.SampleSpace = command.ComplexNumberCorners{MinX: -100, MinY: -100, MaxX: 100, MaxY: 100}
+	_, _, err := render.Render(wallpaperCommand, nil)
+	checker.Assert(err, ErrorMatches, "symmetry_overlay: the region holds .* lattice cells, more than 10000")
+}
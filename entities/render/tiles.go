@@ -0,0 +1,226 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"wallpaper/entities/command"
+)
+
+// TileSize is the width and height of every tile, in pixels.
+const TileSize = 256
+
+// MaximumTileZoom is the deepest zoom level a tile pyramid can have.
+//   Each level has four times as many tiles as the one above it.
+const MaximumTileZoom = 12
+
+// TilePathTemplate is where each tile is stored, relative to the pyramid's manifest.
+const TilePathTemplate = "{z}/{x}/{y}.png"
+
+// TilePyramid describes a set of square tiles covering the command's sample space at several zoom levels.
+//   Zoom level z is cut into 2^z columns and 2^z rows of tiles.
+//   x counts columns from the smallest real part, and y counts rows from the smallest imaginary part,
+//   in the same direction as the rows of a regular render.
+type TilePyramid struct {
+	// Bounds is the square of the sample space covered by every zoom level.
+	//   It is centered on the command's sample space and just big enough to hold it.
+	Bounds command.ComplexNumberCorners `json:"bounds"`
+	// SampleSpace is the part of Bounds the command asked for.
+	SampleSpace command.ComplexNumberCorners `json:"sample_space"`
+	MinZoom     int                          `json:"min_zoom"`
+	MaxZoom     int                          `json:"max_zoom"`
+	TileSize    int                          `json:"tile_size"`
+	TilePath    string                       `json:"tile_path"`
+	// ColorValueSpace is shared by every tile, so colors match across tiles and zoom levels.
+	//   It is only set when the command colors using a source image.
+	ColorValueSpace *command.ComplexNumberCorners `json:"color_value_space,omitempty"`
+}
+
+// Tile is one rendered tile of a pyramid.
+type Tile struct {
+	Zoom   int
+	X      int
+	Y      int
+	Bounds command.ComplexNumberCorners
+	Image  image.Image
+}
+
+// NewTilePyramid returns the pyramid that covers the command's sample space from minZoom to maxZoom.
+//   A viewport is turned into sample space corners using the command's output size.
+//   Rotated viewports cannot be tiled, because tiles line up with the real and imaginary axes.
+func NewTilePyramid(wallpaperCommand *command.CreateSymmetryPattern, minZoom, maxZoom int) (*TilePyramid, error) {
+	if minZoom < 0 || maxZoom > MaximumTileZoom || minZoom > maxZoom {
+		return nil, fmt.Errorf("zoom levels must be from 0 to %d, with the minimum no more than the maximum: %d to %d", MaximumTileZoom, minZoom, maxZoom)
+	}
+
+	if !wallpaperCommand.RendersSampleSpace() {
+		return nil, fmt.Errorf("tiles can only be cut from the sample space, not from output_mode %s", wallpaperCommand.OutputMode)
+	}
+
+	sampleSpace := wallpaperCommand.SampleSpace
+	if wallpaperCommand.Viewport != nil {
+		if wallpaperCommand.Viewport.Rotation != 0 {
+			return nil, errors.New("tiles cannot be rendered from a rotated viewport")
+		}
+		sampleSpace = viewportCorners(wallpaperCommand.Viewport, wallpaperCommand.OutputImageSize)
+	}
+
+	center := complex((sampleSpace.MinX+sampleSpace.MaxX)/2, (sampleSpace.MinY+sampleSpace.MaxY)/2)
+	halfSide := math.Max(math.Abs(sampleSpace.MaxX-sampleSpace.MinX), math.Abs(sampleSpace.MaxY-sampleSpace.MinY)) / 2
+	return &TilePyramid{
+		Bounds: command.ComplexNumberCorners{
+			MinX: real(center) - halfSide,
+			MinY: imag(center) - halfSide,
+			MaxX: real(center) + halfSide,
+			MaxY: imag(center) + halfSide,
+		},
+		SampleSpace: sampleSpace,
+		MinZoom:     minZoom,
+		MaxZoom:     maxZoom,
+		TileSize:    TileSize,
+		TilePath:    TilePathTemplate,
+	}, nil
+}
+
+// viewportCorners returns the sample space corners a viewport without rotation shows at the given output size.
+func viewportCorners(viewport *command.Viewport, outputSize command.WidthHeightDimensions) command.ComplexNumberCorners {
+	halfWidth := float64(outputSize.Width) * viewport.Zoom / 2
+	halfHeight := float64(outputSize.Height) * viewport.Zoom / 2
+	return command.ComplexNumberCorners{
+		MinX: viewport.Center.Real - halfWidth,
+		MinY: viewport.Center.Imaginary - halfHeight,
+		MaxX: viewport.Center.Real + halfWidth,
+		MaxY: viewport.Center.Imaginary + halfHeight,
+	}
+}
+
+// TilesPerSide returns the number of columns (and rows) of tiles at the zoom level.
+func (pyramid *TilePyramid) TilesPerSide(zoom int) int {
+	return 1 << uint(zoom)
+}
+
+// TileCount returns the number of tiles in every zoom level of the pyramid.
+func (pyramid *TilePyramid) TileCount() int {
+	count := 0
+	for zoom := pyramid.MinZoom; zoom <= pyramid.MaxZoom; zoom++ {
+		count += pyramid.TilesPerSide(zoom) * pyramid.TilesPerSide(zoom)
+	}
+	return count
+}
+
+// TileBounds returns the part of the sample space covered by a tile.
+//   Neighboring tiles share their edges, so they line up without gaps or overlaps.
+func (pyramid *TilePyramid) TileBounds(zoom, x, y int) command.ComplexNumberCorners {
+	tilesPerSide := float64(pyramid.TilesPerSide(zoom))
+	tileWidth := (pyramid.Bounds.MaxX - pyramid.Bounds.MinX) / tilesPerSide
+	tileHeight := (pyramid.Bounds.MaxY - pyramid.Bounds.MinY) / tilesPerSide
+	return command.ComplexNumberCorners{
+		MinX: pyramid.Bounds.MinX + float64(x)*tileWidth,
+		MinY: pyramid.Bounds.MinY + float64(y)*tileHeight,
+		MaxX: pyramid.Bounds.MinX + float64(x+1)*tileWidth,
+		MaxY: pyramid.Bounds.MinY + float64(y+1)*tileHeight,
+	}
+}
+
+// tileAt returns the zoom level and position of the tile with the given index.
+//   Tiles are counted from MinZoom to MaxZoom, by column and then by row, so the tile list never has to be built.
+func (pyramid *TilePyramid) tileAt(tileIndex int) (zoom, x, y int) {
+	for zoom = pyramid.MinZoom; zoom < pyramid.MaxZoom; zoom++ {
+		tilesInLevel := pyramid.TilesPerSide(zoom) * pyramid.TilesPerSide(zoom)
+		if tileIndex < tilesInLevel {
+			break
+		}
+		tileIndex -= tilesInLevel
+	}
+	tilesPerSide := pyramid.TilesPerSide(zoom)
+	return zoom, tileIndex / tilesPerSide, tileIndex % tilesPerSide
+}
+
+// pixelNumbering places the tile inside one big image of its whole zoom level,
+//   so jittered tiles line up with each other instead of repeating one pattern.
+func (pyramid *TilePyramid) pixelNumbering(zoom, x, y int) *pixelNumbering {
+	return &pixelNumbering{
+		origin: image.Pt(x*TileSize, y*TileSize),
+		width:  pyramid.TilesPerSide(zoom) * TileSize,
+	}
+}
+
+// TileCommand returns a copy of the command that renders one tile on its own.
+//   The copy shares the command's formulas, so neither should be modified while tiles are rendered.
+func (pyramid *TilePyramid) TileCommand(wallpaperCommand *command.CreateSymmetryPattern, zoom, x, y int) *command.CreateSymmetryPattern {
+	tileCommand := regionCommand(
+		wallpaperCommand,
+		pyramid.TileBounds(zoom, x, y),
+		command.WidthHeightDimensions{Width: TileSize, Height: TileSize},
+	)
+	if pyramid.ColorValueSpace != nil {
+		tileCommand.ColorValueSpace = *pyramid.ColorValueSpace
+		tileCommand.AutomaticColorValueSpace = nil
+	}
+	return tileCommand
+}
+
+// RenderTiles renders every tile of the pyramid from minZoom to maxZoom.
+//   Each tile is calculated from the formula at its own zoom level, instead of being shrunk from a bigger image.
+//   An automatic color value space is chosen once from the whole pyramid, and then every tile uses it.
+//   Tiles are spread across GOMAXPROCS workers. handleTile is called once per tile as soon as it is finished,
+//   possibly from several workers at once and in any order.
+//   Once a tile fails, no more tiles are started and the first error is returned.
+//   colorSource is shared by every tile; a generated source is drawn once for the whole pyramid.
+func RenderTiles(
+	wallpaperCommand *command.CreateSymmetryPattern,
+	colorSource image.Image,
+	minZoom, maxZoom int,
+	handleTile func(tile *Tile) error,
+) (*TilePyramid, error) {
+	if wallpaperCommand.Animation != nil {
+		return nil, errors.New("tiles cannot be rendered from an animation")
+	}
+	pyramid, err := NewTilePyramid(wallpaperCommand, minZoom, maxZoom)
+	if err != nil {
+		return nil, err
+	}
+
+	if colorSource == nil && wallpaperCommand.UsesSourceImage() && wallpaperCommand.SampleSourceGenerator != nil {
+		generatedSource, err := wallpaperCommand.SampleSourceGenerator.Generate()
+		if err != nil {
+			return nil, err
+		}
+		colorSource = generatedSource
+	}
+
+	if wallpaperCommand.UsesSourceImage() {
+		colorValueSpace := wallpaperCommand.ColorValueSpace
+		if wallpaperCommand.AutomaticColorValueSpace != nil {
+			_, report, err := Render(pyramid.TileCommand(wallpaperCommand, 0, 0, 0), colorSource)
+			if err != nil {
+				return nil, err
+			}
+			colorValueSpace = report.ColorValueSpace
+		}
+		pyramid.ColorValueSpace = &colorValueSpace
+	}
+
+	renderErr := &firstError{}
+
+	processIndicesInParallel(pyramid.TileCount(), func(tileIndex int) {
+		if renderErr.get() != nil {
+			return
+		}
+		zoom, x, y := pyramid.tileAt(tileIndex)
+		tileImage, _, err := renderPattern(pyramid.TileCommand(wallpaperCommand, zoom, x, y), colorSource, nil, pyramid.pixelNumbering(zoom, x, y))
+		if err != nil {
+			renderErr.record(err)
+			return
+		}
+		tile := &Tile{Zoom: zoom, X: x, Y: y, Bounds: pyramid.TileBounds(zoom, x, y), Image: tileImage}
+		if err = handleTile(tile); err != nil {
+			renderErr.record(err)
+		}
+	})
+	if err = renderErr.get(); err != nil {
+		return nil, err
+	}
+	return pyramid, nil
+}
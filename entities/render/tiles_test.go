@@ -0,0 +1,149 @@
+package render_test
+
+import (
+	. "gopkg.in/check.v1"
+	"image"
+	"sync"
+	"wallpaper/entities/command"
+	"wallpaper/entities/render"
+	"wallpaper/entities/utility"
+)
+
+type TilesSuite struct {
+	colorSource image.Image
+}
+
+var _ = Suite(&TilesSuite{})
+
+func (suite *TilesSuite) SetUpTest(checker *C) {
+	renderSuite := &RenderSuite{}
+	renderSuite.SetUpTest(checker)
+	suite.colorSource = renderSuite.colorSource
+}
+
+func (suite *TilesSuite) renderTiles(checker *C, wallpaperCommand *command.CreateSymmetryPattern, minZoom, maxZoom int) (*render.TilePyramid, map[[3]int]*render.Tile) {
+	var tilesLock sync.Mutex
+	tiles := map[[3]int]*render.Tile{}
+	pyramid, err := render.RenderTiles(wallpaperCommand, suite.colorSource, minZoom, maxZoom, func(tile *render.Tile) error {
+		tilesLock.Lock()
+		defer tilesLock.Unlock()
+		tiles[[3]int{tile.Zoom, tile.X, tile.Y}] = tile
+		return nil
+	})
+	checker.Assert(err, IsNil)
+	return pyramid, tiles
+}
+
+func (suite *TilesSuite) TestTilesLineUpWithOneBigRender(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	pyramid, tiles := suite.renderTiles(checker, wallpaperCommand, 1, 1)
+	checker.Assert(tiles, HasLen, 4)
+	checker.Assert(pyramid.TileCount(), Equals, 4)
+
+	wallpaperCommand.OutputImageSize = command.WidthHeightDimensions{Width: 2 * render.TileSize, Height: 2 * render.TileSize}
+	bigImage, _, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+
+	for _, tile := range tiles {
+		checker.Assert(tile.Image.Bounds(), Equals, image.Rect(0, 0, render.TileSize, render.TileSize))
+		for y := 0; y < render.TileSize; y += 15 {
+			for x := 0; x < render.TileSize; x += 15 {
+				bigX, bigY := tile.X*render.TileSize+x, tile.Y*render.TileSize+y
+				checker.Assert(tile.Image.At(x, y), Equals, bigImage.At(bigX, bigY))
+			}
+		}
+	}
+}
+
+func (suite *TilesSuite) TestJitteredTilesLineUpWithOneBigRender(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.RosetteFormula.Terms[0].PowerN = 3
+	wallpaperCommand.Antialias = &command.AntialiasOptions{Mode: command.AntialiasJitter, Samples: 4}
+	_, tiles := suite.renderTiles(checker, wallpaperCommand, 1, 1)
+	checker.Assert(tiles, HasLen, 4)
+
+	wallpaperCommand.OutputImageSize = command.WidthHeightDimensions{Width: 2 * render.TileSize, Height: 2 * render.TileSize}
+	bigImage, _, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+
+	for _, tile := range tiles {
+		for y := 0; y < render.TileSize; y += 15 {
+			for x := 0; x < render.TileSize; x += 15 {
+				bigX, bigY := tile.X*render.TileSize+x, tile.Y*render.TileSize+y
+				checker.Assert(tile.Image.At(x, y), Equals, bigImage.At(bigX, bigY))
+			}
+		}
+	}
+}
+
+func (suite *TilesSuite) TestPyramidIsASquareAroundTheSampleSpace(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.SampleSpace = command.ComplexNumberCorners{MinX: -2, MinY: 0, MaxX: 2, MaxY: 2}
+
+	pyramid, err := render.NewTilePyramid(wallpaperCommand, 0, 2)
+	checker.Assert(err, IsNil)
+	checker.Assert(pyramid.Bounds, Equals, command.ComplexNumberCorners{MinX: -2, MinY: -1, MaxX: 2, MaxY: 3})
+	checker.Assert(pyramid.SampleSpace, Equals, wallpaperCommand.SampleSpace)
+	checker.Assert(pyramid.TileCount(), Equals, 1+4+16)
+	checker.Assert(pyramid.TileBounds(1, 1, 0), Equals, command.ComplexNumberCorners{MinX: 0, MinY: -1, MaxX: 2, MaxY: 1})
+}
+
+func (suite *TilesSuite) TestViewportIsTurnedIntoCorners(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.OutputImageSize = command.WidthHeightDimensions{Width: 40, Height: 20}
+	wallpaperCommand.Viewport = &command.Viewport{
+		Center: utility.ComplexNumberForMarshal{Real: 1, Imaginary: -1},
+		Zoom:   0.1,
+	}
+
+	pyramid, err := render.NewTilePyramid(wallpaperCommand, 0, 0)
+	checker.Assert(err, IsNil)
+	checker.Assert(pyramid.SampleSpace.MinX, utility.NumericallyCloseEnough{}, -1.0, 1e-6)
+	checker.Assert(pyramid.SampleSpace.MaxX, utility.NumericallyCloseEnough{}, 3.0, 1e-6)
+	checker.Assert(pyramid.SampleSpace.MinY, utility.NumericallyCloseEnough{}, -2.0, 1e-6)
+	checker.Assert(pyramid.SampleSpace.MaxY, utility.NumericallyCloseEnough{}, 0.0, 1e-6)
+	checker.Assert(pyramid.Bounds.MinY, utility.NumericallyCloseEnough{}, -3.0, 1e-6)
+}
+
+func (suite *TilesSuite) TestRotatedViewportCannotBeTiled(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.Viewport = &command.Viewport{Zoom: 0.1, Rotation: 30}
+
+	_, err := render.NewTilePyramid(wallpaperCommand, 0, 0)
+	checker.Assert(err, ErrorMatches, ".*rotated viewport.*")
+}
+
+func (suite *TilesSuite) TestZoomLevelsMustBeInOrder(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	_, err := render.NewTilePyramid(wallpaperCommand, 2, 1)
+	checker.Assert(err, ErrorMatches, "zoom levels must be .*")
+	_, err = render.NewTilePyramid(wallpaperCommand, 0, render.MaximumTileZoom+1)
+	checker.Assert(err, ErrorMatches, "zoom levels must be .*")
+}
+
+func (suite *TilesSuite) TestAutomaticColorValueSpaceIsSharedByEveryTile(checker *C) {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.AutomaticColorValueSpace = &command.PercentileRange{Low: 0, High: 100}
+
+	pyramid, tiles := suite.renderTiles(checker, wallpaperCommand, 0, 1)
+	checker.Assert(tiles, HasLen, 5)
+	checker.Assert(pyramid.ColorValueSpace, NotNil)
+
+	_, report, err := render.Render(pyramid.TileCommand(wallpaperCommand, 0, 0, 0), suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(report.ColorValueSpace, Equals, *pyramid.ColorValueSpace)
+
+	for _, tile := range tiles {
+		tileCommand := pyramid.TileCommand(wallpaperCommand, tile.Zoom, tile.X, tile.Y)
+		checker.Assert(tileCommand.AutomaticColorValueSpace, IsNil)
+		checker.Assert(tileCommand.ColorValueSpace, Equals, *pyramid.ColorValueSpace)
+	}
+}
+
+func (suite *TilesSuite) TestTilesCannotBeRenderedFromAnAnimation(checker *C) {
+	wallpaperCommand := newAnimatedRosetteCommand(checker, 2)
+	_, err := render.RenderTiles(wallpaperCommand, suite.colorSource, 0, 0, func(tile *render.Tile) error {
+		return nil
+	})
+	checker.Assert(err, ErrorMatches, ".*animation.*")
+}
@@ -0,0 +1,212 @@
+package render
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"wallpaper/entities/command"
+	"wallpaper/entities/formula/result"
+	"wallpaper/entities/mathutility"
+)
+
+// TransformCache holds the transformed value of every sample in a render,
+//   so the pattern can be colored again without calculating the formula.
+type TransformCache struct {
+	// Key is the command's TransformCacheKey. Only commands with the same key can use the cache.
+	Key string
+	// OutputSize is the size of the rendered image, after the output mode picks it.
+	OutputSize      command.WidthHeightDimensions
+	SamplesPerPixel int
+	// Values lists every sample of every pixel, one row at a time from the top left.
+	Values []complex128
+	// ColorValueSamples are the transformed values of the grid used to choose an automatic color value space.
+	ColorValueSamples        []complex128
+	ContributionBoundsByTerm []mathutility.BoundingBox
+}
+
+// IsFilled returns true if the cache holds transformed values to color.
+func (cache *TransformCache) IsFilled() bool {
+	return len(cache.Values) > 0
+}
+
+// checkFits returns an error if the cache was filled for a different command, or for a different image.
+func (cache *TransformCache) checkFits(key string, outputSize command.WidthHeightDimensions, samplesPerPixel int) error {
+	if cache.Key != key {
+		return errors.New("the cache was made for a different formula, sample space, output size or antialiasing")
+	}
+	if cache.OutputSize != outputSize || cache.SamplesPerPixel != samplesPerPixel {
+		return fmt.Errorf(
+			"the cache holds %dx%d pixels with %d sample(s) each, but %dx%d pixels with %d sample(s) each are rendered",
+			cache.OutputSize.Width, cache.OutputSize.Height, cache.SamplesPerPixel,
+			outputSize.Width, outputSize.Height, samplesPerPixel,
+		)
+	}
+	return nil
+}
+
+// rowSamples returns the cached samples of every pixel in the row, grouped by pixel.
+func (cache *TransformCache) rowSamples(row int) [][]*result.CalculationResultForFormula {
+	samplesByPixel := make([][]*result.CalculationResultForFormula, cache.OutputSize.Width)
+	rowStart := row * cache.OutputSize.Width * cache.SamplesPerPixel
+	for pixelIndex := range samplesByPixel {
+		pixelStart := rowStart + pixelIndex*cache.SamplesPerPixel
+		samplesByPixel[pixelIndex] = make([]*result.CalculationResultForFormula, cache.SamplesPerPixel)
+		for sampleIndex := range samplesByPixel[pixelIndex] {
+			samplesByPixel[pixelIndex][sampleIndex] = &result.CalculationResultForFormula{
+				Total: cache.Values[pixelStart+sampleIndex],
+			}
+		}
+	}
+	return samplesByPixel
+}
+
+// saveRowSamples copies the calculated samples of every pixel in the row into the cache.
+//   Each row is saved to its own part of Values, so bands can save rows at the same time.
+func (cache *TransformCache) saveRowSamples(row int, samplesByPixel [][]*result.CalculationResultForFormula) {
+	rowStart := row * cache.OutputSize.Width * cache.SamplesPerPixel
+	for pixelIndex, samples := range samplesByPixel {
+		pixelStart := rowStart + pixelIndex*cache.SamplesPerPixel
+		for sampleIndex, formulaResult := range samples {
+			cache.Values[pixelStart+sampleIndex] = formulaResult.Total
+		}
+	}
+}
+
+// transformCacheMagic starts every transform cache file.
+const transformCacheMagic = "WPTC"
+
+// transformCacheFormatVersion changes whenever the file layout changes.
+const transformCacheFormatVersion = 1
+
+// WriteTransformCache writes the cache in a compact binary format:
+//   a header, the bounds of each term, then the color value samples and the values as little endian float64 pairs.
+func WriteTransformCache(writer io.Writer, cache *TransformCache) error {
+	bufferedWriter := bufio.NewWriter(writer)
+	header := []uint32{
+		transformCacheFormatVersion,
+		uint32(len(cache.Key)),
+	}
+	if _, err := bufferedWriter.WriteString(transformCacheMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(bufferedWriter, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	if _, err := bufferedWriter.WriteString(cache.Key); err != nil {
+		return err
+	}
+
+	sizes := []uint32{
+		uint32(cache.OutputSize.Width),
+		uint32(cache.OutputSize.Height),
+		uint32(cache.SamplesPerPixel),
+		uint32(len(cache.ContributionBoundsByTerm)),
+		uint32(len(cache.ColorValueSamples)),
+	}
+	if err := binary.Write(bufferedWriter, binary.LittleEndian, sizes); err != nil {
+		return err
+	}
+	for _, termBounds := range cache.ContributionBoundsByTerm {
+		if err := writeComplexValues(bufferedWriter, []complex128{termBounds.Min, termBounds.Max}); err != nil {
+			return err
+		}
+	}
+	if err := writeComplexValues(bufferedWriter, cache.ColorValueSamples); err != nil {
+		return err
+	}
+	if err := writeComplexValues(bufferedWriter, cache.Values); err != nil {
+		return err
+	}
+	return bufferedWriter.Flush()
+}
+
+// ReadTransformCache reads a cache written by WriteTransformCache.
+func ReadTransformCache(reader io.Reader) (*TransformCache, error) {
+	bufferedReader := bufio.NewReader(reader)
+	magic := make([]byte, len(transformCacheMagic))
+	if _, err := io.ReadFull(bufferedReader, magic); err != nil || string(magic) != transformCacheMagic {
+		return nil, errors.New("not a transform cache")
+	}
+	header := make([]uint32, 2)
+	if err := binary.Read(bufferedReader, binary.LittleEndian, header); err != nil {
+		return nil, transformCacheReadError(err)
+	}
+	if header[0] != transformCacheFormatVersion {
+		return nil, fmt.Errorf("transform cache format %d is not supported", header[0])
+	}
+	key := make([]byte, header[1])
+	if _, err := io.ReadFull(bufferedReader, key); err != nil {
+		return nil, transformCacheReadError(err)
+	}
+
+	sizes := make([]uint32, 5)
+	if err := binary.Read(bufferedReader, binary.LittleEndian, sizes); err != nil {
+		return nil, transformCacheReadError(err)
+	}
+	cache := &TransformCache{
+		Key:             string(key),
+		OutputSize:      command.WidthHeightDimensions{Width: int(sizes[0]), Height: int(sizes[1])},
+		SamplesPerPixel: int(sizes[2]),
+	}
+	valueCount := uint64(sizes[0]) * uint64(sizes[1]) * uint64(sizes[2])
+	if valueCount == 0 || valueCount > math.MaxInt32 {
+		return nil, fmt.Errorf("transform cache size is not usable: %dx%d pixels with %d sample(s) each", sizes[0], sizes[1], sizes[2])
+	}
+
+	termBounds, err := readComplexValues(bufferedReader, 2*uint64(sizes[3]))
+	if err != nil {
+		return nil, transformCacheReadError(err)
+	}
+	for termIndex := 0; termIndex < len(termBounds); termIndex += 2 {
+		cache.ContributionBoundsByTerm = append(cache.ContributionBoundsByTerm, mathutility.BoundingBox{
+			Min: termBounds[termIndex],
+			Max: termBounds[termIndex+1],
+		})
+	}
+	if cache.ColorValueSamples, err = readComplexValues(bufferedReader, uint64(sizes[4])); err != nil {
+		return nil, transformCacheReadError(err)
+	}
+	if cache.Values, err = readComplexValues(bufferedReader, valueCount); err != nil {
+		return nil, transformCacheReadError(err)
+	}
+	return cache, nil
+}
+
+func transformCacheReadError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errors.New("transform cache is cut short")
+	}
+	return err
+}
+
+// writeComplexValues writes the real and imaginary parts of each value.
+func writeComplexValues(writer io.Writer, values []complex128) error {
+	valueBytes := make([]byte, 16)
+	for _, value := range values {
+		binary.LittleEndian.PutUint64(valueBytes[:8], math.Float64bits(real(value)))
+		binary.LittleEndian.PutUint64(valueBytes[8:], math.Float64bits(imag(value)))
+		if _, err := writer.Write(valueBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readComplexValues reads count values written by writeComplexValues.
+func readComplexValues(reader io.Reader, count uint64) ([]complex128, error) {
+	values := []complex128{}
+	valueBytes := make([]byte, 16)
+	for valueIndex := uint64(0); valueIndex < count; valueIndex++ {
+		if _, err := io.ReadFull(reader, valueBytes); err != nil {
+			return nil, err
+		}
+		values = append(values, complex(
+			math.Float64frombits(binary.LittleEndian.Uint64(valueBytes[:8])),
+			math.Float64frombits(binary.LittleEndian.Uint64(valueBytes[8:])),
+		))
+	}
+	return values, nil
+}
@@ -0,0 +1,104 @@
+package render_test
+
+import (
+	"bytes"
+	. "gopkg.in/check.v1"
+	"image"
+	"image/color"
+	"wallpaper/entities/command"
+	"wallpaper/entities/render"
+)
+
+type TransformCacheSuite struct {
+	colorSource image.Image
+}
+
+var _ = Suite(&TransformCacheSuite{})
+
+func (suite *TransformCacheSuite) SetUpTest(checker *C) {
+	colorSource := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	colorSource.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	colorSource.Set(1, 0, color.NRGBA{G: 255, A: 255})
+	colorSource.Set(0, 1, color.NRGBA{B: 255, A: 255})
+	colorSource.Set(1, 1, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	suite.colorSource = colorSource
+}
+
+func newCachedRosetteCommand(checker *C) *command.CreateSymmetryPattern {
+	wallpaperCommand := newRosetteCommand(checker)
+	wallpaperCommand.OutputImageSize = command.WidthHeightDimensions{Width: 16, Height: 12}
+	wallpaperCommand.RosetteFormula.Terms[0].PowerN = 3
+	wallpaperCommand.Antialias = &command.AntialiasOptions{Mode: command.AntialiasJitter, Samples: 4}
+	return wallpaperCommand
+}
+
+func (suite *TransformCacheSuite) fillCache(checker *C, wallpaperCommand *command.CreateSymmetryPattern) *render.TransformCache {
+	transformCache := &render.TransformCache{}
+	freshImage, freshReport, err := render.RenderWithTransformCache(wallpaperCommand, suite.colorSource, transformCache)
+	checker.Assert(err, IsNil)
+	checker.Assert(transformCache.IsFilled(), Equals, true)
+	checker.Assert(transformCache.Values, HasLen, 16*12*4)
+
+	plainImage, plainReport, err := render.Render(wallpaperCommand, suite.colorSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(freshImage, DeepEquals, plainImage)
+	checker.Assert(freshReport, DeepEquals, plainReport)
+	return transformCache
+}
+
+func (suite *TransformCacheSuite) TestRecoloringWithTheCacheMatchesAFreshRender(checker *C) {
+	transformCache := suite.fillCache(checker, newCachedRosetteCommand(checker))
+
+	recolored := newCachedRosetteCommand(checker)
+	recolored.AutomaticColorValueSpace = &command.PercentileRange{Low: 5, High: 95}
+	recolored.OutputBitDepth = 16
+	recoloredSource := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	recoloredSource.Set(0, 0, color.NRGBA{R: 10, G: 20, B: 30, A: 255})
+	recoloredSource.Set(1, 0, color.NRGBA{R: 200, A: 128})
+	recoloredSource.Set(2, 0, color.NRGBA{B: 90, A: 255})
+
+	cachedImage, cachedReport, err := render.RenderWithTransformCache(recolored, recoloredSource, transformCache)
+	checker.Assert(err, IsNil)
+	freshImage, freshReport, err := render.Render(recolored, recoloredSource)
+	checker.Assert(err, IsNil)
+	checker.Assert(cachedImage, DeepEquals, freshImage)
+	checker.Assert(cachedReport, DeepEquals, freshReport)
+}
+
+func (suite *TransformCacheSuite) TestCacheSurvivesARoundTrip(checker *C) {
+	transformCache := suite.fillCache(checker, newCachedRosetteCommand(checker))
+
+	cacheFile := &bytes.Buffer{}
+	checker.Assert(render.WriteTransformCache(cacheFile, transformCache), IsNil)
+	checker.Assert(cacheFile.Len() > 16*len(transformCache.Values), Equals, true)
+
+	readCache, err := render.ReadTransformCache(cacheFile)
+	checker.Assert(err, IsNil)
+	checker.Assert(readCache, DeepEquals, transformCache)
+}
+
+func (suite *TransformCacheSuite) TestCacheOnlyFitsTheSameTransform(checker *C) {
+	transformCache := suite.fillCache(checker, newCachedRosetteCommand(checker))
+
+	otherFormula := newCachedRosetteCommand(checker)
+	otherFormula.RosetteFormula.Terms[0].PowerN = 4
+	_, _, err := render.RenderWithTransformCache(otherFormula, suite.colorSource, transformCache)
+	checker.Assert(err, ErrorMatches, "transform cache: the cache was made for a different formula, .*")
+}
+
+func (suite *TransformCacheSuite) TestDamagedCachesCannotBeRead(checker *C) {
+	transformCache := suite.fillCache(checker, newCachedRosetteCommand(checker))
+	cacheFile := &bytes.Buffer{}
+	checker.Assert(render.WriteTransformCache(cacheFile, transformCache), IsNil)
+
+	_, err := render.ReadTransformCache(bytes.NewReader(cacheFile.Bytes()[:cacheFile.Len()-1]))
+	checker.Assert(err, ErrorMatches, "transform cache is cut short")
+
+	_, err = render.ReadTransformCache(bytes.NewReader([]byte("PNG image")))
+	checker.Assert(err, ErrorMatches, "not a transform cache")
+
+	otherVersion := append([]byte{}, cacheFile.Bytes()...)
+	otherVersion[4] = 9
+	_, err = render.ReadTransformCache(bytes.NewReader(otherVersion))
+	checker.Assert(err, ErrorMatches, "transform cache format 9 is not supported")
+}
@@ -1,339 +1,697 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"image"
-	"image/color"
-	"image/png"
 	_ "image/png"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 	"wallpaper/entities/command"
+	"wallpaper/entities/encoder"
 	"wallpaper/entities/formula/frieze"
-	"wallpaper/entities/formula/rosette"
-	"wallpaper/entities/formula/wallpaper"
-	"wallpaper/entities/mathutility"
+	"wallpaper/entities/render"
+)
+
+const usage = `Usage: wallpaper <subcommand> [flags] <formula file>
+
+Subcommands:
+  render        Transform the source image and write the pattern to the output file
+  render-tiles  Render a pyramid of 256 pixel tiles for zoomable viewers
+  analyze       Print the symmetries found in the formula
+  validate      Check the formula file for errors
+
+The formula file is read as YAML (.yml, .yaml) or JSON (.json) based on its extension.
+Run "wallpaper <subcommand> -h" to see the flags for a subcommand.
+`
+
+// version is written into the PNG files this tool renders.
+//   Release builds can set it with: go build -ldflags "-X main.version=1.2.3"
+var version = "development"
+
+// Keywords of the PNG text chunks written with each render.
+const (
+	formulaKeyword      = "wallpaper formula"
+	softwareKeyword     = "Software"
+	creationTimeKeyword = "Creation Time"
 )
 
 func main() {
-	createWallpaperYAML, err := ioutil.ReadFile("data/formula.yml")
-	if err != nil {
-		log.Fatal(err)
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
 	}
-	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML(createWallpaperYAML)
-	if err != nil {
-		log.Fatal(err)
+
+	subcommandsByName := map[string]func(arguments []string) error{
+		"render":       runRenderSubcommand,
+		"render-tiles": runRenderTilesSubcommand,
+		"analyze":      runAnalyzeSubcommand,
+		"validate":     runValidateSubcommand,
 	}
-	sampleSpaceMin := complex(wallpaperCommand.SampleSpace.MinX, wallpaperCommand.SampleSpace.MinY)
-	sampleSpaceMax := complex(wallpaperCommand.SampleSpace.MaxX, wallpaperCommand.SampleSpace.MaxY)
-	outputWidth := wallpaperCommand.OutputImageSize.Width
-	outputHeight := wallpaperCommand.OutputImageSize.Height
-	colorSourceFilename := wallpaperCommand.SampleSourceFilename
-	outputFilename := wallpaperCommand.OutputFilename
-	colorValueBoundMin := complex(wallpaperCommand.ColorValueSpace.MinX, wallpaperCommand.ColorValueSpace.MinY)
-	colorValueBoundMax := complex(wallpaperCommand.ColorValueSpace.MaxX, wallpaperCommand.ColorValueSpace.MaxY)
-
-	//sampleSpaceMin := complex(-1e0, -1e0)
-	//sampleSpaceMax := complex(1e0, 1e0)
-	////outputWidth := 800
-	////outputHeight := 450
-	//outputWidth := 3840
-	//outputHeight := 2160
-	//colorSourceFilename := "exampleImage/brownie.png"
-	//outputFilename := "exampleImage/newBrownie.png"
-	//colorValueBoundMin := complex(-2e5, -2e5)
-	//colorValueBoundMax := complex(5e5, 5e5)
 
-	reader, err := os.Open(colorSourceFilename)
-	if err != nil {
-	  log.Fatal(err)
+	subcommandName := os.Args[1]
+	subcommand := subcommandsByName[subcommandName]
+	if subcommand == nil {
+		fmt.Fprintf(os.Stderr, "unknown subcommand %q\n\n%s", subcommandName, usage)
+		os.Exit(2)
 	}
-	defer reader.Close()
 
-	colorSourceImage, _, err := image.Decode(reader)
-	if err != nil {
+	if err := subcommand(os.Args[2:]); err != nil {
 		log.Fatal(err)
 	}
+}
 
-	destinationBounds := image.Rect(0,0, outputWidth, outputHeight)
-	destinationCoordinates := flattenCoordinates(destinationBounds)
+// outputSizeFlag parses WIDTHxHEIGHT into output dimensions.
+type outputSizeFlag struct {
+	dimensions *command.WidthHeightDimensions
+}
 
-	scaledCoordinates := scaleDestinationPixels(
-		destinationBounds,
-		destinationCoordinates,
-		sampleSpaceMin,
-		sampleSpaceMax,
-	)
+func (flag *outputSizeFlag) String() string {
+	if flag.dimensions == nil {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", flag.dimensions.Width, flag.dimensions.Height)
+}
 
-	transformedCoordinates := transformCoordinatesForFormula(wallpaperCommand, scaledCoordinates)
-	minz, maxz := mathutility.GetBoundingBox(transformedCoordinates)
-	println(minz)
-	println(maxz)
+func (flag *outputSizeFlag) Set(value string) error {
+	var width, height int
+	if _, err := fmt.Sscanf(value, "%dx%d", &width, &height); err != nil {
+		return fmt.Errorf("output size must look like 800x600: %v", err)
+	}
+	flag.dimensions = &command.WidthHeightDimensions{Width: width, Height: height}
+	return nil
+}
 
-	// Consider how to give a preview image? What's the picture ration
-	outputImage := image.NewNRGBA(image.Rect(0, 0, outputWidth, outputHeight))
-	colorDestinationImage(outputImage, colorSourceImage, destinationCoordinates, transformedCoordinates, colorValueBoundMin, colorValueBoundMax)
+// commandOverrides holds the flags that replace values in the formula file.
+type commandOverrides struct {
+	outputFilename       string
+	outputSize           outputSizeFlag
+	sampleSourceFilename string
+}
 
-	outputToFile(outputFilename, outputImage)
+func (overrides *commandOverrides) register(flags *flag.FlagSet) {
+	flags.StringVar(&overrides.outputFilename, "output-filename", "", "replaces output_filename")
+	flags.Var(&overrides.outputSize, "output-size", "replaces output_size, written as WIDTHxHEIGHT")
+	flags.StringVar(&overrides.sampleSourceFilename, "sample-source-filename", "", "replaces sample_source and sample_source_filename")
 }
 
-func outputToFile(outputFilename string, outputImage image.Image) {
-	outputImageFile, err := os.Create(outputFilename)
+func (overrides *commandOverrides) apply(wallpaperCommand *command.CreateSymmetryPattern) {
+	if overrides.outputFilename != "" {
+		wallpaperCommand.OutputFilename = overrides.outputFilename
+	}
+	if overrides.outputSize.dimensions != nil {
+		wallpaperCommand.OutputImageSize = *overrides.outputSize.dimensions
+	}
+	if overrides.sampleSourceFilename != "" {
+		wallpaperCommand.SampleSourceFilename = overrides.sampleSourceFilename
+		wallpaperCommand.SampleSourceGenerator = nil
+	}
+}
+
+// parseSubcommandArguments parses flags that may appear before or after the formula filename.
+func parseSubcommandArguments(flags *flag.FlagSet, arguments []string) (string, error) {
+	positionalArguments, err := parseFlagsAndPositionalArguments(flags, arguments)
+	if err != nil {
+		return "", err
+	}
+	return onlyFormulaFilename(flags, positionalArguments)
+}
+
+// parseFlagsAndPositionalArguments parses flags that may appear before, between or after the positional arguments.
+func parseFlagsAndPositionalArguments(flags *flag.FlagSet, arguments []string) ([]string, error) {
+	positionalArguments := []string{}
+	for {
+		if err := flags.Parse(arguments); err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positionalArguments = append(positionalArguments, flags.Arg(0))
+		arguments = flags.Args()[1:]
+	}
+	return positionalArguments, nil
+}
+
+func onlyFormulaFilename(flags *flag.FlagSet, positionalArguments []string) (string, error) {
+	if len(positionalArguments) != 1 {
+		return "", fmt.Errorf("%s needs exactly one formula file, got %d", flags.Name(), len(positionalArguments))
+	}
+	return positionalArguments[0], nil
+}
+
+// loadCommand reads the formula file, applies the overrides and validates the result.
+func loadCommand(formulaFilename string, overrides *commandOverrides) (*command.CreateSymmetryPattern, error) {
+	formulaFileContents, err := ioutil.ReadFile(formulaFilename)
+	if err != nil {
+		return nil, err
+	}
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromFileContents(formulaFilename, formulaFileContents)
+	if err != nil {
+		return nil, err
+	}
+	return overrideAndValidate(formulaFilename, wallpaperCommand, overrides)
+}
+
+// loadCommandFromImage reads the formula embedded in a rendered PNG, applies the overrides and validates the result.
+//   The image cannot be replaced by the new render.
+func loadCommandFromImage(imageFilename string, overrides *commandOverrides) (*command.CreateSymmetryPattern, error) {
+	embeddedFormula, err := readEmbeddedFormula(imageFilename)
+	if err != nil {
+		return nil, err
+	}
+	wallpaperCommand, err := command.NewCreateWallpaperCommandFromYAML(embeddedFormula)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", imageFilename, err)
+	}
+
+	wallpaperCommand, err = overrideAndValidate(imageFilename, wallpaperCommand, overrides)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	if isSameFile(imageFilename, wallpaperCommand.OutputFilename) {
+		return nil, fmt.Errorf("rendering would replace %s, use -output-filename to write somewhere else", imageFilename)
 	}
-	defer outputImageFile.Close()
-	png.Encode(outputImageFile, outputImage)
+	return wallpaperCommand, nil
 }
 
-func transformCoordinatesForFormula(command *command.CreateSymmetryPattern, scaledCoordinates []complex128) []complex128 {
-	if command.FriezeFormula != nil {
-		return transformCoordinatesForFriezeFormula(command.FriezeFormula, scaledCoordinates)
+func overrideAndValidate(sourceFilename string, wallpaperCommand *command.CreateSymmetryPattern, overrides *commandOverrides) (*command.CreateSymmetryPattern, error) {
+	overrides.apply(wallpaperCommand)
+
+	if err := wallpaperCommand.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", sourceFilename, err)
 	}
-	if command.RosetteFormula != nil {
-		return transformCoordinatesForRosetteFormula(command.RosetteFormula, scaledCoordinates)
+	return wallpaperCommand, nil
+}
+
+// readEmbeddedFormula returns the formula that was written into a PNG when it was rendered.
+func readEmbeddedFormula(imageFilename string) ([]byte, error) {
+	imageFile, err := os.Open(imageFilename)
+	if err != nil {
+		return nil, err
 	}
-	if command.LatticePattern != nil {
-		return transformCoordinatesForLatticePattern(command.LatticePattern, scaledCoordinates)
+	defer imageFile.Close()
+
+	textChunks, err := encoder.ReadPNGText(bufio.NewReader(imageFile))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", imageFilename, err)
+	}
+	for _, textChunk := range textChunks {
+		if textChunk.Keyword == formulaKeyword {
+			return []byte(textChunk.Text), nil
+		}
 	}
-	log.Fatal(errors.New("no formula found"))
-	return []complex128{}
+	return nil, fmt.Errorf("%s has no embedded formula, only PNG files rendered by this tool have one", imageFilename)
+}
+
+func isSameFile(firstFilename, secondFilename string) bool {
+	firstFileInfo, firstErr := os.Stat(firstFilename)
+	secondFileInfo, secondErr := os.Stat(secondFilename)
+	return firstErr == nil && secondErr == nil && os.SameFile(firstFileInfo, secondFileInfo)
 }
 
-func transformCoordinatesForFriezeFormula(friezeFormula *frieze.Formula, scaledCoordinates []complex128) []complex128 {
-	symmetryAnalysis := friezeFormula.AnalyzeForSymmetry()
-	if symmetryAnalysis.P111 {
-		println("Has these symmetries: p111")
+func runValidateSubcommand(arguments []string) error {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	overrides := &commandOverrides{}
+	overrides.register(flags)
+	formulaFilename, err := parseSubcommandArguments(flags, arguments)
+	if err != nil {
+		return err
 	}
-	if symmetryAnalysis.P211 {
-		println("  P211")
+
+	if _, err := loadCommand(formulaFilename, overrides); err != nil {
+		return err
 	}
-	if symmetryAnalysis.P1m1 {
-		println("  P1m1")
+	fmt.Printf("%s is valid\n", formulaFilename)
+	return nil
+}
+
+func runAnalyzeSubcommand(arguments []string) error {
+	flags := flag.NewFlagSet("analyze", flag.ExitOnError)
+	overrides := &commandOverrides{}
+	overrides.register(flags)
+	formulaFilename, err := parseSubcommandArguments(flags, arguments)
+	if err != nil {
+		return err
 	}
-	if symmetryAnalysis.P11g {
-		println("  P11g")
+
+	wallpaperCommand, err := loadCommand(formulaFilename, overrides)
+	if err != nil {
+		return err
 	}
-	if symmetryAnalysis.P11m {
-		println("  P11m")
+
+	symmetryAnalysis, err := render.AnalyzeSymmetry(wallpaperCommand)
+	if err != nil {
+		return err
 	}
-	if symmetryAnalysis.P2mm {
-		println("  P2mm")
+	printSymmetryAnalysis(symmetryAnalysis)
+	return nil
+}
+
+func runRenderSubcommand(arguments []string) error {
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	overrides := &commandOverrides{}
+	overrides.register(flags)
+	fromImage := flags.String("from-image", "", "renders the formula embedded in a PNG rendered by this tool, instead of a formula file")
+	transformCacheDirectory := flags.String("transform-cache-dir", "", "saves transformed values to this folder, and reuses them when only coloring options change")
+	positionalArguments, err := parseFlagsAndPositionalArguments(flags, arguments)
+	if err != nil {
+		return err
+	}
+
+	var wallpaperCommand *command.CreateSymmetryPattern
+	if *fromImage != "" {
+		if len(positionalArguments) != 0 {
+			return fmt.Errorf("render -from-image does not use a formula file, got %d", len(positionalArguments))
+		}
+		wallpaperCommand, err = loadCommandFromImage(*fromImage, overrides)
+	} else {
+		formulaFilename, filenameErr := onlyFormulaFilename(flags, positionalArguments)
+		if filenameErr != nil {
+			return filenameErr
+		}
+		wallpaperCommand, err = loadCommand(formulaFilename, overrides)
 	}
-	if symmetryAnalysis.P2mg {
-		println("  P2mg")
+	if err != nil {
+		return err
 	}
 
-	transformedCoordinates := []complex128{}
-	resultsByTerm := [][]complex128{}
-	for range friezeFormula.Terms {
-		resultsByTerm = append(resultsByTerm, []complex128{})
+	var colorSourceImage image.Image
+	if wallpaperCommand.UsesSourceImage() && wallpaperCommand.SampleSourceGenerator == nil {
+		colorSourceImage, err = readColorSourceImage(wallpaperCommand.SampleSourceFilename)
+		if err != nil {
+			return err
+		}
 	}
 
-	for _, complexCoordinate := range scaledCoordinates {
-		friezeResults := friezeFormula.Calculate(complexCoordinate)
-		for index, formulaResult := range friezeResults.ContributionByTerm {
-			resultsByTerm[index] = append(resultsByTerm[index], formulaResult)
+	if wallpaperCommand.Animation != nil {
+		if *transformCacheDirectory != "" {
+			return errors.New("-transform-cache-dir cannot be used with an animation, every frame transforms different values")
 		}
+		return renderAnimation(wallpaperCommand, colorSourceImage)
+	}
 
-		transformedCoordinate := friezeResults.Total
-		transformedCoordinates = append(transformedCoordinates, transformedCoordinate)
+	var outputImage image.Image
+	var report *render.Report
+	if *transformCacheDirectory != "" {
+		outputImage, report, err = renderWithTransformCacheFile(wallpaperCommand, colorSourceImage, *transformCacheDirectory)
+	} else {
+		outputImage, report, err = render.Render(wallpaperCommand, colorSourceImage)
+	}
+	if err != nil {
+		return err
+	}
+	printRenderReport(report)
+	if wallpaperCommand.AutomaticColorValueSpace != nil {
+		printAutomaticColorValueSpace(report.ColorValueSpace)
+	}
+	if report.LatticeTile != nil {
+		printLatticeTile(report.LatticeTile)
+	}
+	if report.FriezeStrip != nil {
+		printFriezeStrip(report.FriezeStrip)
+	}
+	if report.SymmetryOverlay != nil {
+		printSymmetryOverlay(report.SymmetryOverlay)
+	}
+	if wallpaperCommand.SymmetryOverlay != nil && wallpaperCommand.SymmetryOverlay.WritesSVG() {
+		err = writeOverlaySVG(wallpaperCommand.SymmetryOverlayFilename(), func(svgFile io.Writer) error {
+			return render.WriteSymmetryOverlaySVG(svgFile, report.SymmetryOverlay)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if report.LatticeOverlay != nil {
+		printLatticeOverlay(report.LatticeOverlay)
+	}
+	if wallpaperCommand.LatticeOverlay != nil && wallpaperCommand.LatticeOverlay.WritesSVG() {
+		err = writeOverlaySVG(wallpaperCommand.LatticeOverlayFilename(), func(svgFile io.Writer) error {
+			return render.WriteLatticeOverlaySVG(svgFile, report.LatticeOverlay)
+		})
+		if err != nil {
+			return err
+		}
 	}
 
-	println("Min/Max ranges, by Term")
-	for index, results := range resultsByTerm {
-		minz, maxz := mathutility.GetBoundingBox(results)
-		fmt.Printf("%d: %e - %e\n", index, minz, maxz)
+	textChunks, err := renderTextChunks(wallpaperCommand, time.Now())
+	if err != nil {
+		return err
 	}
-	return transformedCoordinates
+	return outputToFile(wallpaperCommand.OutputFilename, outputImage, wallpaperCommand.OutputEncoding, textChunks)
 }
 
-func transformCoordinatesForRosetteFormula(rosetteFormula *rosette.Formula, scaledCoordinates []complex128) []complex128 {
-	transformedCoordinates := []complex128{}
-	resultsByTerm := [][]complex128{}
-	for range rosetteFormula.Terms {
-		resultsByTerm = append(resultsByTerm, []complex128{})
+// transformCacheExtension ends the name of every transform cache file. The rest of the name is the cache key.
+const transformCacheExtension = ".wpcache"
+
+// renderWithTransformCacheFile reuses the transform cache file for the command if the folder has one.
+//   Otherwise it renders normally and saves a new cache file there.
+func renderWithTransformCacheFile(wallpaperCommand *command.CreateSymmetryPattern, colorSourceImage image.Image, cacheDirectory string) (image.Image, *render.Report, error) {
+	cacheKey, err := wallpaperCommand.TransformCacheKey()
+	if err != nil {
+		return nil, nil, err
 	}
+	cacheFilename := filepath.Join(cacheDirectory, cacheKey+transformCacheExtension)
 
-	for _, complexCoordinate := range scaledCoordinates {
-		rosetteResults := rosetteFormula.Calculate(complexCoordinate)
-		for index, formulaResult := range rosetteResults.ContributionByTerm {
-			resultsByTerm[index] = append(resultsByTerm[index], formulaResult)
+	transformCache, err := readTransformCacheFile(cacheFilename)
+	if err != nil {
+		return nil, nil, err
+	}
+	if transformCache != nil {
+		outputImage, report, err := render.RenderWithTransformCache(wallpaperCommand, colorSourceImage, transformCache)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", cacheFilename, err)
 		}
+		fmt.Printf("Loaded transformed values from %s\n", cacheFilename)
+		return outputImage, report, nil
+	}
 
-		transformedCoordinate := rosetteResults.Total
-		transformedCoordinates = append(transformedCoordinates, transformedCoordinate)
+	transformCache = &render.TransformCache{}
+	outputImage, report, err := render.RenderWithTransformCache(wallpaperCommand, colorSourceImage, transformCache)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = writeTransformCacheFile(cacheFilename, transformCache); err != nil {
+		return nil, nil, err
 	}
+	fmt.Printf("Saved transformed values to %s\n", cacheFilename)
+	return outputImage, report, nil
+}
 
-	println("Min/Max ranges, by Term")
-	for index, results := range resultsByTerm {
-		minz, maxz := mathutility.GetBoundingBox(results)
-		fmt.Printf("%d: %e - %e\n", index, minz, maxz)
+// readTransformCacheFile returns nil if the cache file does not exist yet.
+func readTransformCacheFile(cacheFilename string) (*render.TransformCache, error) {
+	cacheFile, err := os.Open(cacheFilename)
+	if os.IsNotExist(err) {
+		return nil, nil
 	}
-	return transformedCoordinates
+	if err != nil {
+		return nil, err
+	}
+	defer cacheFile.Close()
+
+	transformCache, err := render.ReadTransformCache(cacheFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %v", cacheFilename, err)
+	}
+	return transformCache, nil
 }
 
-func transformCoordinatesForLatticePattern(latticePattern *wallpaper.Formula, scaledCoordinates []complex128) []complex128 {
-	latticePattern.Setup()
+// writeTransformCacheFile writes the cache to a temporary file and then renames it,
+//   so other renders never read a half written cache.
+func writeTransformCacheFile(cacheFilename string, transformCache *render.TransformCache) error {
+	cacheDirectory := filepath.Dir(cacheFilename)
+	if err := os.MkdirAll(cacheDirectory, 0755); err != nil {
+		return err
+	}
+	temporaryFile, err := ioutil.TempFile(cacheDirectory, filepath.Base(cacheFilename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporaryFile.Name())
 
-	transformedCoordinates := []complex128{}
-	resultsByTerm := [][]complex128{}
-	for range latticePattern.WavePackets {
-		resultsByTerm = append(resultsByTerm, []complex128{})
+	if err = render.WriteTransformCache(temporaryFile, transformCache); err != nil {
+		temporaryFile.Close()
+		return fmt.Errorf("cannot write %s: %v", cacheFilename, err)
+	}
+	if err = temporaryFile.Close(); err != nil {
+		return err
 	}
+	if err = os.Chmod(temporaryFile.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(temporaryFile.Name(), cacheFilename)
+}
 
-	for _, complexCoordinate := range scaledCoordinates {
-		latticePatternResults := latticePattern.Calculate(complexCoordinate)
-		for index, formulaResult := range latticePatternResults.ContributionByTerm {
-			resultsByTerm[index] = append(resultsByTerm[index], formulaResult)
+// renderAnimation writes an animated GIF when the output filename ends in .gif,
+//   and one numbered image per frame otherwise. Each PNG frame embeds the formula for that frame.
+func renderAnimation(wallpaperCommand *command.CreateSymmetryPattern, colorSourceImage image.Image) error {
+	format, err := encoder.FormatForFilename(wallpaperCommand.OutputFilename)
+	if err != nil {
+		return err
+	}
+
+	animation := wallpaperCommand.Animation
+	renderTime := time.Now()
+	gifFrames := make([]image.Image, animation.Frames)
+	err = render.RenderAnimation(wallpaperCommand, colorSourceImage, func(frame *render.AnimationFrame) error {
+		fmt.Printf("Rendered frame %d of %d\n", frame.Index+1, animation.Frames)
+		if format == encoder.GIF {
+			gifFrames[frame.Index] = frame.Image
+			return nil
 		}
 
-		transformedCoordinate := latticePatternResults.Total
-		transformedCoordinates = append(transformedCoordinates, transformedCoordinate)
+		frame.Command.OutputFilename = animation.FrameFilename(wallpaperCommand.OutputFilename, frame.Index)
+		textChunks, chunkErr := renderTextChunks(frame.Command, renderTime)
+		if chunkErr != nil {
+			return chunkErr
+		}
+		return outputToFile(frame.Command.OutputFilename, frame.Image, wallpaperCommand.OutputEncoding, textChunks)
+	})
+	if err != nil || format != encoder.GIF {
+		return err
 	}
 
-	println("Min/Max ranges, by Term")
-	for index, results := range resultsByTerm {
-		minz, maxz := mathutility.GetBoundingBox(results)
-		fmt.Printf("%d: %e - %e\n", index, minz, maxz)
+	frameDelay := time.Duration(float64(time.Second) / animation.FrameRate())
+	return outputAnimatedGIF(wallpaperCommand.OutputFilename, gifFrames, frameDelay, wallpaperCommand.OutputEncoding)
+}
+
+// tileManifestFilename is written into the tile directory, next to the zoom level folders.
+const tileManifestFilename = "manifest.json"
+
+func runRenderTilesSubcommand(arguments []string) error {
+	flags := flag.NewFlagSet("render-tiles", flag.ExitOnError)
+	overrides := &commandOverrides{}
+	overrides.register(flags)
+	minZoom := flags.Int("min-zoom", 0, "the first zoom level to render, where one tile covers the whole sample space")
+	maxZoom := flags.Int("max-zoom", 3, "the last zoom level to render")
+	outputDirectory := flags.String("output-directory", "", "the folder the tiles are written to, defaults to output_filename without its extension")
+	formulaFilename, err := parseSubcommandArguments(flags, arguments)
+	if err != nil {
+		return err
+	}
+
+	wallpaperCommand, err := loadCommand(formulaFilename, overrides)
+	if err != nil {
+		return err
+	}
+	if *outputDirectory == "" {
+		*outputDirectory = strings.TrimSuffix(wallpaperCommand.OutputFilename, filepath.Ext(wallpaperCommand.OutputFilename))
 	}
 
-	println("Symmetries found:")
-	if latticePattern.HasSymmetry(wallpaper.P1) {
-		println("  p1")
+	var colorSourceImage image.Image
+	if wallpaperCommand.UsesSourceImage() && wallpaperCommand.SampleSourceGenerator == nil {
+		colorSourceImage, err = readColorSourceImage(wallpaperCommand.SampleSourceFilename)
+		if err != nil {
+			return err
+		}
 	}
-	if latticePattern.HasSymmetry(wallpaper.P2) {
-		println("  p2")
+
+	pyramid, err := render.RenderTiles(wallpaperCommand, colorSourceImage, *minZoom, *maxZoom, func(tile *render.Tile) error {
+		tileFilename := filepath.Join(*outputDirectory, strconv.Itoa(tile.Zoom), strconv.Itoa(tile.X), strconv.Itoa(tile.Y)+".png")
+		if mkdirErr := os.MkdirAll(filepath.Dir(tileFilename), 0755); mkdirErr != nil {
+			return mkdirErr
+		}
+		return outputToFile(tileFilename, tile.Image, nil, nil)
+	})
+	if err != nil {
+		return err
 	}
-	if latticePattern.HasSymmetry(wallpaper.P31m) {
-		println("  p31m")
+
+	manifest, err := json.MarshalIndent(pyramid, "", "  ")
+	if err != nil {
+		return err
 	}
-	if latticePattern.HasSymmetry(wallpaper.P3m1) {
-		println("  p3m1")
+	manifestFilename := filepath.Join(*outputDirectory, tileManifestFilename)
+	if err = ioutil.WriteFile(manifestFilename, append(manifest, '\n'), 0644); err != nil {
+		return err
 	}
-	if latticePattern.HasSymmetry(wallpaper.P6) {
-		println("  p6")
+	fmt.Printf("Rendered %d tiles, described in %s\n", pyramid.TileCount(), manifestFilename)
+	return nil
+}
+
+func outputAnimatedGIF(outputFilename string, frames []image.Image, frameDelay time.Duration, options *encoder.Options) error {
+	outputImageFile, err := os.Create(outputFilename)
+	if err != nil {
+		return err
 	}
-	if latticePattern.HasSymmetry(wallpaper.P6m) {
-		println("  p6m")
+	if err = encoder.EncodeAnimatedGIF(outputImageFile, frames, frameDelay, options); err != nil {
+		outputImageFile.Close()
+		return fmt.Errorf("cannot write %s: %v", outputFilename, err)
 	}
-	if latticePattern.HasSymmetry(wallpaper.P3) {
-		println("  p3")
+	return outputImageFile.Close()
+}
+
+// renderTextChunks describe how the image was rendered. The formula chunk can be rendered again with -from-image.
+func renderTextChunks(wallpaperCommand *command.CreateSymmetryPattern, renderTime time.Time) ([]encoder.TextChunk, error) {
+	formula, err := wallpaperCommand.ToYAML()
+	if err != nil {
+		return nil, err
 	}
-	if latticePattern.HasSymmetry(wallpaper.P4) {
-		println("  p4")
+	return []encoder.TextChunk{
+		{Keyword: formulaKeyword, Text: string(formula)},
+		{Keyword: softwareKeyword, Text: "wallpaper " + version},
+		{Keyword: creationTimeKeyword, Text: renderTime.Format(time.RFC1123Z)},
+	}, nil
+}
+
+func readColorSourceImage(colorSourceFilename string) (image.Image, error) {
+	reader, err := os.Open(colorSourceFilename)
+	if err != nil {
+		return nil, err
 	}
-	if latticePattern.HasSymmetry(wallpaper.P4m) {
-		println("  p4m")
+	defer reader.Close()
+
+	colorSourceImage, _, err := image.Decode(reader)
+	return colorSourceImage, err
+}
+
+// outputToFile encodes the image in the format that matches the filename's extension.
+//   PNG files also store the text chunks.
+func outputToFile(outputFilename string, outputImage image.Image, options *encoder.Options, textChunks []encoder.TextChunk) error {
+	format, err := encoder.FormatForFilename(outputFilename)
+	if err != nil {
+		return err
 	}
-	if latticePattern.HasSymmetry(wallpaper.P4g) {
-		println("  p4g")
+
+	outputImageFile, err := os.Create(outputFilename)
+	if err != nil {
+		return err
 	}
-	if latticePattern.HasSymmetry(wallpaper.Cm) {
-		println("  cm")
+	if format == encoder.PNG {
+		err = encoder.EncodePNGWithText(outputImageFile, outputImage, textChunks)
+	} else {
+		err = encoder.Encode(outputImageFile, outputImage, format, options)
 	}
-	if latticePattern.HasSymmetry(wallpaper.Cmm) {
-		println("  cmm")
+	if err != nil {
+		outputImageFile.Close()
+		return fmt.Errorf("cannot write %s: %v", outputFilename, err)
 	}
-	if latticePattern.HasSymmetry(wallpaper.Pm) {
-		println("  pm")
+	return outputImageFile.Close()
+}
+
+func printRenderReport(report *render.Report) {
+	printSymmetryAnalysis(report.Symmetry)
+
+	fmt.Println("Min/Max ranges, by Term")
+	for index, termBounds := range report.ContributionBoundsByTerm {
+		fmt.Printf("%d: %e - %e\n", index, termBounds.Min, termBounds.Max)
 	}
-	if latticePattern.HasSymmetry(wallpaper.Pg) {
-		println("  pg")
+
+	fmt.Println(report.TransformedBounds.Min)
+	fmt.Println(report.TransformedBounds.Max)
+}
+
+// printAutomaticColorValueSpace prints the chosen color value space in the formula file's format,
+//   so it can be pasted in to render the same image again.
+func printAutomaticColorValueSpace(colorValueSpace command.ComplexNumberCorners) {
+	fmt.Println("Automatic color value space:")
+	fmt.Println("color_value_space:")
+	fmt.Printf("  minx: %g\n", colorValueSpace.MinX)
+	fmt.Printf("  miny: %g\n", colorValueSpace.MinY)
+	fmt.Printf("  maxx: %g\n", colorValueSpace.MaxX)
+	fmt.Printf("  maxy: %g\n", colorValueSpace.MaxY)
+}
+
+// printLatticeTile prints how the rendered tile repeats, in the sample space and in output pixels.
+func printLatticeTile(latticeTile *render.LatticeTile) {
+	repeat := latticeTile.Repeat
+	pixelsAcross, pixelsDown := latticeTile.PixelsPerUnit()
+	fmt.Printf("Tile is one repeat, %.6g by %.6g (%dx%d pixels)\n", repeat.Width, repeat.Height, latticeTile.OutputSize.Width, latticeTile.OutputSize.Height)
+	fmt.Printf("  Straight repeat: every %.6g across and %.6g down (%.6g and %.6g pixels)\n",
+		repeat.Width, repeat.Height, repeat.Width*pixelsAcross, repeat.Height*pixelsDown)
+
+	dropName := "Drop"
+	if repeat.IsHalfDrop() {
+		dropName = "Half-drop"
 	}
-	if latticePattern.HasSymmetry(wallpaper.Pmm) {
-		println("  pmm")
+	fmt.Printf("  %s repeat: columns %.6g wide, each moved %.6g down (%.6g and %.6g pixels)\n",
+		dropName, repeat.DropWidth, repeat.DropOffset, repeat.DropWidth*pixelsAcross, repeat.DropOffset*pixelsDown)
+
+	brickName := "Brick"
+	if repeat.IsHalfBrick() {
+		brickName = "Half-brick"
 	}
-	if latticePattern.HasSymmetry(wallpaper.Pmg) {
-		println("  pmg")
+	fmt.Printf("  %s repeat: rows %.6g tall, each moved %.6g across (%.6g and %.6g pixels)\n",
+		brickName, repeat.BrickHeight, repeat.BrickOffset, repeat.BrickHeight*pixelsDown, repeat.BrickOffset*pixelsAcross)
+}
+
+// printFriezeStrip prints the part of the frieze that loops, in the sample space and in output pixels.
+func printFriezeStrip(friezeStrip *render.FriezeStrip) {
+	fmt.Printf("Strip is %d period(s), from %.6g to %.6g (%dx%d pixels)\n",
+		friezeStrip.Periods, friezeStrip.SampleSpace.MinX, friezeStrip.SampleSpace.MaxX,
+		friezeStrip.OutputSize.Width, friezeStrip.OutputSize.Height)
+	fmt.Printf("  Each period is %.6g pixels wide\n", friezeStrip.PixelsPerPeriod())
+}
+
+// printSymmetryOverlay prints how many symmetry elements the overlay draws.
+func printSymmetryOverlay(overlay *render.SymmetryOverlay) {
+	fmt.Printf("Symmetry overlay: %d mirror line(s), %d glide axis(es), %d rotation center(s)\n",
+		len(overlay.Elements.Mirrors), len(overlay.Elements.Glides), len(overlay.Elements.RotationCenters))
+}
+
+// printLatticeOverlay prints which fundamental domain the overlay shades.
+func printLatticeOverlay(overlay *render.LatticeOverlay) {
+	fmt.Printf("Lattice overlay: %d cell edge(s), shading one %s fundamental domain\n", len(overlay.CellEdges), overlay.Symmetry)
+}
+
+// writeOverlaySVG creates the SVG file and writes an overlay to it, the same size as the output image.
+func writeOverlaySVG(svgFilename string, writeOverlay func(svgFile io.Writer) error) error {
+	svgFile, err := os.Create(svgFilename)
+	if err != nil {
+		return err
 	}
-	if latticePattern.HasSymmetry(wallpaper.Pgg) {
-		println("  pgg")
+	if err = writeOverlay(svgFile); err != nil {
+		svgFile.Close()
+		return fmt.Errorf("cannot write %s: %v", svgFilename, err)
 	}
-	return transformedCoordinates
+	fmt.Printf("Overlay written to %s\n", svgFilename)
+	return svgFile.Close()
 }
 
-func flattenCoordinates(destinationBounds image.Rectangle) []complex128 {
-	flattenedCoordinates := []complex128{}
-	for destinationY := destinationBounds.Min.Y ; destinationY < destinationBounds.Max.Y; destinationY++ {
-		for destinationX := destinationBounds.Min.X; destinationX < destinationBounds.Max.X; destinationX++ {
-			flattenedCoordinates = append(flattenedCoordinates, complex(float64(destinationX), float64(destinationY)))
-		}
+func printSymmetryAnalysis(symmetryAnalysis *render.SymmetryAnalysis) {
+	if symmetryAnalysis.Frieze != nil {
+		printFriezeSymmetries(symmetryAnalysis.Frieze)
+	}
+	if symmetryAnalysis.Rosette != nil {
+		fmt.Printf("Rosette has %d-fold rotational symmetry\n", symmetryAnalysis.Rosette.Multifold)
 	}
-	return flattenedCoordinates
-}
-
-func scaleDestinationPixels(destinationBounds image.Rectangle, destinationCoordinates []complex128, viewPortMin complex128, viewPortMax complex128) []complex128 {
-	scaledCoordinates := []complex128{}
-	for _, destinationCoordinate := range destinationCoordinates {
-		destinationScaledX := mathutility.ScaleValueBetweenTwoRanges(
-			float64(real(destinationCoordinate)),
-			float64(destinationBounds.Min.X),
-			float64(destinationBounds.Max.X),
-			real(viewPortMin),
-			real(viewPortMax),
-		)
-		destinationScaledY := mathutility.ScaleValueBetweenTwoRanges(
-			float64(imag(destinationCoordinate)),
-			float64(destinationBounds.Min.Y),
-			float64(destinationBounds.Max.Y),
-			imag(viewPortMin),
-			imag(viewPortMax),
-		)
-		scaledCoordinates = append(scaledCoordinates, complex(destinationScaledX, destinationScaledY))
-	}
-	return scaledCoordinates
-}
-
-func colorDestinationImage(
-	destinationImage *image.NRGBA,
-	sourceImage image.Image,
-	destinationCoordinates []complex128,
-	transformedCoordinates []complex128,
-	colorValueBoundMin complex128,
-	colorValueBoundMax complex128,
-	) {
-	sourceImageBounds := sourceImage.Bounds()
-	for index, transformedCoordinate := range transformedCoordinates {
-		var sourceColorR, sourceColorG, sourceColorB, sourceColorA uint32
-
-		if real(transformedCoordinate) < real(colorValueBoundMin) ||
-			imag(transformedCoordinate) < imag(colorValueBoundMin) ||
-		real(transformedCoordinate) > real(colorValueBoundMax) ||
-			imag(transformedCoordinate) > imag(colorValueBoundMax) {
-			sourceColorR,sourceColorG,sourceColorB,sourceColorA = 0,0,0,0
-		} else {
-			sourceImagePixelX := int(mathutility.ScaleValueBetweenTwoRanges(
-				float64(real(transformedCoordinate)),
-				real(colorValueBoundMin),
-				real(colorValueBoundMax),
-				float64(sourceImageBounds.Min.X),
-				float64(sourceImageBounds.Max.X),
-			))
-			sourceImagePixelY := int(mathutility.ScaleValueBetweenTwoRanges(
-				float64(imag(transformedCoordinate)),
-				imag(colorValueBoundMin),
-				imag(colorValueBoundMax),
-				float64(sourceImageBounds.Min.Y),
-				float64(sourceImageBounds.Max.Y),
-			))
-			sourceColorR, sourceColorG, sourceColorB, sourceColorA = sourceImage.At(sourceImagePixelX, sourceImagePixelY).RGBA()
+	if symmetryAnalysis.Lattice != nil {
+		fmt.Println("Symmetries found:")
+		for _, symmetry := range symmetryAnalysis.Lattice {
+			fmt.Println("  " + string(symmetry))
 		}
+	}
+}
 
-		destinationPixelX := int(real(destinationCoordinates[index]))
-		destinationPixelY := int(imag(destinationCoordinates[index]))
-
-		destinationImage.Set(
-			destinationPixelX,
-			destinationPixelY,
-			color.NRGBA{
-				R: uint8(sourceColorR>>8),
-				G: uint8(sourceColorG>>8),
-				B: uint8(sourceColorB>>8),
-				A: uint8(sourceColorA>>8),
-			},
-		)
+func printFriezeSymmetries(friezeSymmetry *frieze.Symmetry) {
+	if friezeSymmetry.P111 {
+		fmt.Println("Has these symmetries: p111")
+	}
+	if friezeSymmetry.P211 {
+		fmt.Println("  P211")
+	}
+	if friezeSymmetry.P1m1 {
+		fmt.Println("  P1m1")
+	}
+	if friezeSymmetry.P11g {
+		fmt.Println("  P11g")
+	}
+	if friezeSymmetry.P11m {
+		fmt.Println("  P11m")
+	}
+	if friezeSymmetry.P2mm {
+		fmt.Println("  P2mm")
+	}
+	if friezeSymmetry.P2mg {
+		fmt.Println("  P2mg")
 	}
 }